@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// leaves out the characters that are easily mixed up (0/O, 1/I/L), since invite codes get typed in by hand
+const inviteCodeChars = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+const inviteCodeLen = 10
+
+// GenerateInviteCode returns a random, upper-case code for someone to type into /grolist-join.
+func GenerateInviteCode() (string, error) {
+	max := big.NewInt(int64(len(inviteCodeChars)))
+	o := make([]byte, inviteCodeLen)
+	for i := range o {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		o[i] = inviteCodeChars[n.Int64()]
+	}
+	return string(o), nil
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestGenerateInviteCode(t *testing.T) {
+	code, err := GenerateInviteCode()
+	require.NoError(t, err)
+	require.Len(t, code, inviteCodeLen)
+	for _, c := range code {
+		require.True(t, strings.ContainsRune(inviteCodeChars, c), "unexpected character %q in %s", c, code)
+	}
+	require.Equal(t, code, models.NormaliseInviteCode(" "+strings.ToLower(code)+"\n"))
+
+	other, err := GenerateInviteCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code, other)
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+const webhookSignaturePrefix = "sha256="
+
+// SignWebhookPayload returns the signature of a webhook delivery, which is an HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook's secret.
+// The timestamp is signed too so that receivers can reject old deliveries being replayed.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a signature made by SignWebhookPayload in constant time.
+func VerifyWebhookSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"type":"grocery_entry.added"}`)
+	sig := SignWebhookPayload("secret", 1700000000, body)
+	// echo -n '1700000000.{"type":"grocery_entry.added"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=0ef35ffbb29ff6d6b25827369ce04d33ba349f830656f2c5eefadb6734468ef7", sig)
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"type":"grocery_entry.added"}`)
+	sig := SignWebhookPayload("secret", 1700000000, body)
+	require.True(t, VerifyWebhookSignature("secret", 1700000000, body, sig))
+	require.False(t, VerifyWebhookSignature("other-secret", 1700000000, body, sig))
+	require.False(t, VerifyWebhookSignature("secret", 1700000001, body, sig))
+	require.False(t, VerifyWebhookSignature("secret", 1700000000, []byte(`{"type":"grocery_entry.removed"}`), sig))
+}
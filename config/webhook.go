@@ -0,0 +1,9 @@
+package config
+
+import "os"
+
+// IsWebhookPrivateNetworkAllowed lets webhooks be delivered to private/loopback addresses, which is blocked by default so that
+// webhooks can't be used to poke around the network that GroceryBot runs in. Handy for local development.
+func IsWebhookPrivateNetworkAllowed() bool {
+	return os.Getenv("GROCER_BOT_WEBHOOK_ALLOW_PRIVATE_NETWORK") == "true"
+}
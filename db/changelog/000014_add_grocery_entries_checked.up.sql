@@ -0,0 +1,4 @@
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `checked_at` datetime;
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `checked_by_id` text;
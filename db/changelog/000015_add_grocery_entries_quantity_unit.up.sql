@@ -0,0 +1,4 @@
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `quantity` real;
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `unit` text;
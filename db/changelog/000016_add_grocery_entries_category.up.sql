@@ -0,0 +1,13 @@
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `category` text;
+
+CREATE TABLE IF NOT EXISTS `guild_category_overrides` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `keyword` text NOT NULL,
+  `category` text NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_guild_category_overrides_guild_id_keyword` ON `guild_category_overrides`(`guild_id`, `keyword`);
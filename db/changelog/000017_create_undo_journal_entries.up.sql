@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `undo_journal_entries` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `operation` text NOT NULL,
+  `grocery_list_id` integer,
+  `snapshot` text NOT NULL,
+  `created_by_id` text,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_undo_journal_entries_guild_id` ON `undo_journal_entries`(`guild_id`);
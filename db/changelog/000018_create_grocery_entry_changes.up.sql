@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `grocery_entry_changes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `grocery_list_id` integer,
+  `grocery_entry_id` integer NOT NULL,
+  `action` text NOT NULL,
+  `before` text,
+  `after` text,
+  `changed_by_id` text,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_grocery_entry_changes_guild_id_grocery_list_id` ON `grocery_entry_changes`(`guild_id`, `grocery_list_id`);
+CREATE INDEX `idx_grocery_entry_changes_grocery_entry_id` ON `grocery_entry_changes`(`grocery_entry_id`);
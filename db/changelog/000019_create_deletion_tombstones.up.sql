@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `deletion_tombstones` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `entity_type` text NOT NULL,
+  `entity_id` integer NOT NULL,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_deletion_tombstones_guild_id_created_at` ON `deletion_tombstones`(`guild_id`, `created_at`);
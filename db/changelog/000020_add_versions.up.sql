@@ -0,0 +1,5 @@
+ALTER TABLE `grocery_entries` ADD COLUMN
+  `version` integer NOT NULL DEFAULT 1;
+
+ALTER TABLE `grocery_lists` ADD COLUMN
+  `version` integer NOT NULL DEFAULT 1;
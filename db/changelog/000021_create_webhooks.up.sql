@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS `webhook_subscriptions` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `url` text NOT NULL,
+  `secret` text NOT NULL,
+  `created_by_id` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE INDEX `idx_webhook_subscriptions_guild_id` ON `webhook_subscriptions`(`guild_id`);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `webhook_subscription_id` integer NOT NULL,
+  `event_id` text NOT NULL,
+  `event_type` text NOT NULL,
+  `attempt` integer NOT NULL,
+  `status_code` integer,
+  `error` text,
+  `succeeded` boolean NOT NULL DEFAULT false,
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_webhook_deliveries_webhook_subscription_id` ON `webhook_deliveries`(`webhook_subscription_id`);
+CREATE INDEX `idx_webhook_deliveries_created_at` ON `webhook_deliveries`(`created_at`);
@@ -0,0 +1,5 @@
+ALTER TABLE `grohere_records` ADD COLUMN
+  `extra_message_ids` text NOT NULL DEFAULT '';
+
+ALTER TABLE `guild_configs` ADD COLUMN
+  `grohere_extra_message_ids` text NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+ALTER TABLE `guild_configs` ADD COLUMN
+  `use_grolist_reactions` boolean DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS `grolist_reaction_messages` (
+  `message_id` text PRIMARY KEY,
+  `guild_id` text NOT NULL,
+  `channel_id` text NOT NULL,
+  `grocery_entry_ids` text NOT NULL DEFAULT '',
+  `created_at` datetime
+);
+
+CREATE INDEX `idx_grolist_reaction_messages_created_at` ON `grolist_reaction_messages`(`created_at`);
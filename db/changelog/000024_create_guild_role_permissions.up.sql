@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `guild_role_permissions` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `role_id` text NOT NULL,
+  `capability` text NOT NULL,
+  `created_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_guild_role_permissions_guild_id_role_id_capability` ON `guild_role_permissions`(`guild_id`, `role_id`, `capability`);
@@ -0,0 +1,8 @@
+ALTER TABLE `grocery_lists` ADD COLUMN
+  `visibility` text NOT NULL DEFAULT 'public';
+
+ALTER TABLE `grocery_lists` ADD COLUMN
+  `owner_id` text;
+
+ALTER TABLE `grocery_lists` ADD COLUMN
+  `allowed_ids` text NOT NULL DEFAULT '';
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `grocery_list_shares` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `grocery_list_id` integer NOT NULL,
+  `guild_id` text NOT NULL,
+  `created_by_id` text,
+  `created_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_grocery_list_shares_grocery_list_id_guild_id` ON `grocery_list_shares`(`grocery_list_id`, `guild_id`);
+CREATE INDEX `idx_grocery_list_shares_guild_id` ON `grocery_list_shares`(`guild_id`);
+
+CREATE TABLE IF NOT EXISTS `grocery_list_invites` (
+  `code` text PRIMARY KEY,
+  `grocery_list_id` integer NOT NULL,
+  `guild_id` text NOT NULL,
+  `created_by_id` text,
+  `created_at` datetime,
+  `expires_at` datetime NOT NULL
+);
+
+CREATE INDEX `idx_grocery_list_invites_grocery_list_id` ON `grocery_list_invites`(`grocery_list_id`);
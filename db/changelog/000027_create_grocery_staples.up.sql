@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS `grocery_staples` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `grocery_list_id` integer,
+  `item_desc` text NOT NULL,
+  `quantity` real,
+  `unit` text,
+  `schedule` text NOT NULL,
+  `weekday` integer,
+  `interval_days` integer,
+  `next_run_at` datetime,
+  `last_run_at` datetime,
+  `created_by_id` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE INDEX `idx_grocery_staples_guild_id_grocery_list_id` ON `grocery_staples`(`guild_id`, `grocery_list_id`);
+CREATE INDEX `idx_grocery_staples_next_run_at` ON `grocery_staples`(`next_run_at`);
+
+-- staples that are re-added whenever they're checked off look for the latest check-offs
+CREATE INDEX `idx_grocery_entry_changes_created_at` ON `grocery_entry_changes`(`created_at`);
@@ -0,0 +1,19 @@
+ALTER TABLE `guild_configs` ADD COLUMN
+  `timezone` text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS `grocery_reminders` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `grocery_list_id` integer,
+  `channel_id` text,
+  `user_ids` text NOT NULL DEFAULT '',
+  `cron` text NOT NULL,
+  `next_run_at` datetime NOT NULL,
+  `last_run_at` datetime,
+  `created_by_id` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE INDEX `idx_grocery_reminders_guild_id` ON `grocery_reminders`(`guild_id`);
+CREATE INDEX `idx_grocery_reminders_next_run_at` ON `grocery_reminders`(`next_run_at`);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `grocery_templates` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `name` text NOT NULL,
+  `items` text NOT NULL,
+  `created_by_id` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_grocery_templates_guild_id_name` ON `grocery_templates`(`guild_id`, `name`);
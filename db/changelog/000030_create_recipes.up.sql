@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `recipes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `guild_id` text NOT NULL,
+  `name` text NOT NULL,
+  `title` text,
+  `source_url` text,
+  `ingredients` text NOT NULL,
+  `servings` integer,
+  `created_by_id` text,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_recipes_guild_id_name` ON `recipes`(`guild_id`, `name`);
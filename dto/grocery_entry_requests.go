@@ -0,0 +1,8 @@
+package dto
+
+// UpdateGroceryEntryRequest is the body of PATCH /groceries/:id - fields that are left out are not changed.
+type UpdateGroceryEntryRequest struct {
+	ItemDesc *string `json:"item_desc" validate:"omitempty,min=1"`
+	// null or 0 moves the entry into the guild's default grocery list
+	GroceryListID *uint `json:"grocery_list_id"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+const (
+	GroceryEventEntryAdded   = "grocery_entry.added"
+	GroceryEventEntryRemoved = "grocery_entry.removed"
+	GroceryEventEntryEdited  = "grocery_entry.edited"
+	GroceryEventListCreated  = "grocery_list.created"
+	GroceryEventListDeleted  = "grocery_list.deleted"
+	// GroceryEventListUpdated carries the list's entries after it has been changed, and is only sent through GET /events
+	GroceryEventListUpdated = "grocery_list.updated"
+)
+
+// GroceryEvent is sent to webhook subscriptions & GET /events whenever a guild's groceries change.
+type GroceryEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	GuildID   string    `json:"guild_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Changes is set for grocery_entry.* events
+	Changes []models.GroceryEntryChange `json:"changes,omitempty"`
+	// GroceryList is set for grocery_list.* events, except for grocery_list.updated events on the guild's default list
+	GroceryList *models.GroceryList `json:"grocery_list,omitempty"`
+	// GroceryEntries is set for grocery_list.updated events
+	GroceryEntries []models.GroceryEntry `json:"grocery_entries,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url,max=2000"`
+}
+
+type CreateWebhookResponse struct {
+	Webhook models.WebhookSubscription `json:"webhook"`
+	// Secret is only ever returned once, when the webhook is created
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/verzac/grocer-discord-bot/models"
+
+// SaveGroceryTemplateRequest is the body of POST /templates - saving over an existing template replaces its items.
+type SaveGroceryTemplateRequest struct {
+	Name string `json:"name" validate:"required"`
+	// the grocery list to save the template from - null or 0 for the guild's default grocery list
+	GroceryListID *uint `json:"grocery_list_id"`
+}
+
+// ApplyGroceryTemplateRequest is the body of POST /templates/:id/apply.
+type ApplyGroceryTemplateRequest struct {
+	// the grocery list to add the template's items to - null or 0 for the guild's default grocery list
+	GroceryListID *uint `json:"grocery_list_id"`
+}
+
+type ApplyGroceryTemplateResponse struct {
+	Added []models.GroceryEntry `json:"added"`
+	// the template's items that were already on the grocery list
+	Skipped []models.GroceryTemplateItem `json:"skipped"`
+}
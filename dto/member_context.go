@@ -0,0 +1,12 @@
+package dto
+
+// MemberContext is who's running a command, so that their permissions can be checked (see guildconfig.Service.HasCapability).
+type MemberContext struct {
+	GuildID   string
+	ChannelID string
+	UserID    string
+	// nil if unknown, in which case they're looked up through Discord when needed
+	RoleIDs []string
+	// nil if unknown (e.g. for message commands), in which case they're looked up through Discord when needed
+	Permissions *int64
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/verzac/grocer-discord-bot/models"
+
+// SaveRecipeRequest is the body of POST /recipes - saving over an existing recipe (with the same name) replaces it.
+type SaveRecipeRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	Title       *string  `json:"title"`
+	SourceURL   *string  `json:"source_url"`
+	Ingredients []string `json:"ingredients" validate:"required"`
+	// null if the recipe doesn't say how many people it's for
+	Servings *int `json:"servings"`
+}
+
+// AddRecipeRequest is the body of POST /recipes/:id/add.
+type AddRecipeRequest struct {
+	// scales the recipe's quantities to this many servings - null or 0 to add them as they are
+	Servings *int `json:"servings"`
+	// the grocery list to add the recipe's ingredients to - null or 0 for the guild's default grocery list
+	GroceryListID *uint `json:"grocery_list_id"`
+}
+
+type AddRecipeResponse struct {
+	Added []models.GroceryEntry `json:"added"`
+	// the grocery entries that were already on the list, with the recipe's quantities added onto them
+	Merged []models.GroceryEntry `json:"merged"`
+}
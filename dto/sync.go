@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/verzac/grocer-discord-bot/models"
+
+type SyncResponse struct {
+	// Cursor should be sent as ?since= on the next sync
+	Cursor string `json:"cursor"`
+	// FullSync is true when the response contains everything in the guild (e.g. on the first sync, or when the cursor is too old),
+	// in which case clients should replace what they have instead of applying the response on top of it
+	FullSync               bool                  `json:"full_sync"`
+	GroceryEntries         []models.GroceryEntry `json:"grocery_entries"`
+	GroceryLists           []models.GroceryList  `json:"grocery_lists"`
+	DeletedGroceryEntryIDs []uint                `json:"deleted_grocery_entry_ids"`
+	DeletedGroceryListIDs  []uint                `json:"deleted_grocery_list_ids"`
+}
@@ -105,6 +105,19 @@ func (s *APITestSession) DeleteNoBody(path string) (*http.Response, error) {
 	return s.client.Do(req)
 }
 
+// OpenEventStream opens GET /events; callers must close the response's body.
+func (s *APITestSession) OpenEventStream(lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, s.baseURL+"/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	s.applyAuth(req)
+	return s.client.Do(req)
+}
+
 func ReadBodyAndClose(res *http.Response) ([]byte, error) {
 	defer res.Body.Close()
 	return io.ReadAll(res.Body)
@@ -160,6 +173,52 @@ func (s *APITestSession) PatchGroceryList(id uint, body []byte) (*http.Response,
 	return s.client.Do(req)
 }
 
+func (s *APITestSession) PatchGrocery(id uint, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPatch,
+		fmt.Sprintf("%s/groceries/%d", s.baseURL, id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.applyAuth(req)
+	return s.client.Do(req)
+}
+
+func (s *APITestSession) PatchGroceryIfMatch(id uint, body []byte, etag string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPatch,
+		fmt.Sprintf("%s/groceries/%d", s.baseURL, id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", etag)
+	s.applyAuth(req)
+	return s.client.Do(req)
+}
+
+func (s *APITestSession) DeleteGroceryIfMatch(id uint, etag string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf("%s/groceries/%d", s.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("If-Match", etag)
+	s.applyAuth(req)
+	return s.client.Do(req)
+}
+
+func (s *APITestSession) PatchGroceryListIfMatch(id uint, body []byte, etag string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPatch,
+		fmt.Sprintf("%s/grocery-lists/%d", s.baseURL, id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", etag)
+	s.applyAuth(req)
+	return s.client.Do(req)
+}
+
 func (s *APITestSession) CleanupAllGroceryLists() error {
 	lists, status, err := s.FetchGroceryLists()
 	if err != nil {
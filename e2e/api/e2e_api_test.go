@@ -3,10 +3,12 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -495,6 +497,389 @@ func TestDeleteGroceryListWithEntries(t *testing.T) {
 	require.Equal(t, http.StatusConflict, res.StatusCode, "%s", string(b))
 }
 
+func TestPatchGroceryEntry(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	e := postGroceries(t, `{"item_desc":"api-e2e-patch"}`)
+
+	res, err := apiSess.PatchGrocery(e.ID, []byte(`{"item_desc":"api-e2e-patched 2 L"}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+
+	var updated models.GroceryEntry
+	require.NoError(t, json.Unmarshal(b, &updated))
+	require.Equal(t, e.ID, updated.ID)
+	require.Equal(t, "api-e2e-patched", updated.ItemDesc)
+	require.NotNil(t, updated.Quantity)
+	require.Equal(t, 2.0, *updated.Quantity)
+}
+
+func TestPatchGroceryEntryClearsQuantity(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	e := postGroceries(t, `{"item_desc":"2L milk"}`)
+	require.NotNil(t, e.Quantity)
+
+	// like !groedit, an item_desc without a quantity clears the old one
+	res, err := apiSess.PatchGrocery(e.ID, []byte(`{"item_desc":"oat milk"}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+
+	var updated models.GroceryEntry
+	require.NoError(t, json.Unmarshal(b, &updated))
+	require.Equal(t, "oat milk", updated.ItemDesc)
+	require.Nil(t, updated.Quantity)
+	require.Nil(t, updated.Unit)
+}
+
+func TestPatchGroceryEntryMoveList(t *testing.T) {
+	cleanupGroceries(t)
+	cleanupGroceryLists(t)
+	defer func() {
+		cleanupGroceries(t)
+		cleanupGroceryLists(t)
+	}()
+
+	gl := postGroceryList(t, `{"list_label":"moveto"}`)
+	e := postGroceries(t, `{"item_desc":"api-e2e-move"}`)
+
+	res, err := apiSess.PatchGrocery(e.ID, []byte(`{"grocery_list_id":`+uintToStr(gl.ID)+`}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	var moved models.GroceryEntry
+	require.NoError(t, json.Unmarshal(b, &moved))
+	require.NotNil(t, moved.GroceryListID)
+	require.Equal(t, gl.ID, *moved.GroceryListID)
+	require.Equal(t, "api-e2e-move", moved.ItemDesc)
+
+	// null moves it back into the default list
+	res, err = apiSess.PatchGrocery(e.ID, []byte(`{"grocery_list_id":null}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	require.NoError(t, json.Unmarshal(b, &moved))
+	require.Nil(t, moved.GroceryListID)
+}
+
+func TestPatchGroceryEntryValidationErrors(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	e := postGroceries(t, `{"item_desc":"api-e2e-patch-invalid"}`)
+
+	for _, body := range []string{`{}`, `{"item_desc":""}`, `{"grocery_list_id":999999999}`} {
+		res, err := apiSess.PatchGrocery(e.ID, []byte(body))
+		require.NoError(t, err)
+		b, err := apiharness.ReadBodyAndClose(res)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s: %s", body, string(b))
+	}
+
+	res, err := apiSess.PatchGrocery(999999999, []byte(`{"item_desc":"Ghost"}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode, "%s", string(b))
+}
+
 func uintToStr(id uint) string {
 	return strconv.FormatUint(uint64(id), 10)
 }
+
+func TestGroceryEntryIfMatch(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	e := postGroceries(t, `{"item_desc":"api-e2e-etag"}`)
+	require.NotEmpty(t, e.GetETag())
+	staleETag := e.GetETag()
+
+	res, err := apiSess.PatchGroceryIfMatch(e.ID, []byte(`{"item_desc":"api-e2e-etag-edited"}`), staleETag)
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	var updated models.GroceryEntry
+	require.NoError(t, json.Unmarshal(b, &updated))
+	require.Equal(t, updated.GetETag(), res.Header.Get("ETag"))
+	require.NotEqual(t, staleETag, updated.GetETag())
+
+	// someone else's write has happened since staleETag, so these must not go through
+	res, err = apiSess.PatchGroceryIfMatch(e.ID, []byte(`{"item_desc":"api-e2e-etag-clobbered"}`), staleETag)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.DeleteGroceryIfMatch(e.ID, staleETag)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.DeleteGroceryIfMatch(e.ID, updated.GetETag())
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode, "%s", string(b))
+}
+
+func TestGroceryListIfMatch(t *testing.T) {
+	cleanupGroceryLists(t)
+	defer cleanupGroceryLists(t)
+
+	gl := postGroceryList(t, `{"list_label":"etag"}`)
+	staleETag := gl.GetETag()
+
+	res, err := apiSess.PatchGroceryListIfMatch(gl.ID, []byte(`{"fancy_name":"ETag"}`), staleETag)
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	require.NotEqual(t, staleETag, res.Header.Get("ETag"))
+
+	res, err = apiSess.PatchGroceryListIfMatch(gl.ID, []byte(`{"fancy_name":"Clobbered"}`), staleETag)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode, "%s", string(b))
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	res, err := apiSess.PostJSON("/webhooks", []byte(`{"url":"ftp://example.com/grocerybot"}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.PostJSON("/webhooks", []byte(`{"url":"https://example.com/grocerybot"}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode, "%s", string(b))
+	var created dto.CreateWebhookResponse
+	require.NoError(t, json.Unmarshal(b, &created))
+	require.NotEmpty(t, created.Secret)
+	require.Equal(t, "https://example.com/grocerybot", created.Webhook.URL)
+	path := "/webhooks/" + uintToStr(created.Webhook.ID)
+
+	res, err = apiSess.Get("/webhooks")
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	require.NotContains(t, string(b), created.Secret)
+	var webhooks []models.WebhookSubscription
+	require.NoError(t, json.Unmarshal(b, &webhooks))
+	require.NotEmpty(t, webhooks)
+
+	res, err = apiSess.Get(path + "/deliveries")
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.DeleteNoBody(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.DeleteNoBody(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode, "%s", string(b))
+}
+
+// nextStreamEvent reads the stream until the next event, skipping comments & the retry field.
+func nextStreamEvent(t *testing.T, r *bufio.Reader) (id string, event string, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if event != "" {
+				return id, event, data
+			}
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	res, err := apiSess.OpenEventStream("")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	r := bufio.NewReader(res.Body)
+
+	postGroceries(t, `{"item_desc":"Streamed milk"}`)
+	id, event, data := nextStreamEvent(t, r)
+	require.NoError(t, res.Body.Close())
+	require.NotEmpty(t, id)
+	require.Equal(t, dto.GroceryEventEntryAdded, event)
+	var ev dto.GroceryEvent
+	require.NoError(t, json.Unmarshal([]byte(data), &ev))
+	require.Len(t, ev.Changes, 1)
+	require.NotNil(t, ev.Changes[0].After)
+	require.Contains(t, *ev.Changes[0].After, "Streamed milk")
+
+	// events missed while disconnected are replayed
+	postGroceries(t, `{"item_desc":"Missed eggs"}`)
+	res, err = apiSess.OpenEventStream(id)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	_, event, data = nextStreamEvent(t, bufio.NewReader(res.Body))
+	require.Equal(t, dto.GroceryEventEntryAdded, event)
+	require.Contains(t, data, "Missed eggs")
+}
+
+func TestTemplateSaveAndApply(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	e := postGroceries(t, `{"item_desc":"api-e2e-template 2 L"}`)
+
+	res, err := apiSess.PostJSON("/templates", []byte(`{"name":"  API E2E  Template "}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Contains(t, []int{http.StatusCreated, http.StatusOK}, res.StatusCode, "%s", string(b))
+	var template struct {
+		ID    uint                         `json:"id"`
+		Name  string                       `json:"name"`
+		Items []models.GroceryTemplateItem `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(b, &template))
+	require.Equal(t, "api e2e template", template.Name)
+	require.Len(t, template.Items, 1)
+	require.Equal(t, "api-e2e-template", template.Items[0].ItemDesc)
+	path := "/templates/" + uintToStr(template.ID)
+
+	// it's still on the list, so it's skipped
+	res, err = apiSess.PostJSON(path+"/apply", []byte(`{}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	var applied dto.ApplyGroceryTemplateResponse
+	require.NoError(t, json.Unmarshal(b, &applied))
+	require.Empty(t, applied.Added)
+	require.Len(t, applied.Skipped, 1)
+
+	res, err = apiSess.DeleteNoBody("/groceries/" + uintToStr(e.ID))
+	require.NoError(t, err)
+	_, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+
+	res, err = apiSess.PostJSON(path+"/apply", []byte(`{}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	require.NoError(t, json.Unmarshal(b, &applied))
+	require.Len(t, applied.Added, 1)
+	require.Equal(t, "api-e2e-template", applied.Added[0].ItemDesc)
+	require.NotNil(t, applied.Added[0].Quantity)
+	require.Equal(t, 2.0, *applied.Added[0].Quantity)
+
+	res, err = apiSess.DeleteNoBody(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.Get(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode, "%s", string(b))
+}
+
+func TestRecipeSaveAndAdd(t *testing.T) {
+	cleanupGroceries(t)
+	defer cleanupGroceries(t)
+
+	res, err := apiSess.PostJSON("/recipes", []byte(`{"name":"API E2E Recipe","title":"API E2E Pancakes","ingredients":["2 cups api-e2e-recipe-flour"," ","api-e2e-recipe-salt"],"servings":4}`))
+	require.NoError(t, err)
+	b, err := apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Contains(t, []int{http.StatusCreated, http.StatusOK}, res.StatusCode, "%s", string(b))
+	var recipe struct {
+		ID          uint     `json:"id"`
+		Name        string   `json:"name"`
+		Ingredients []string `json:"ingredients"`
+		Servings    *int     `json:"servings"`
+	}
+	require.NoError(t, json.Unmarshal(b, &recipe))
+	require.Equal(t, "api e2e recipe", recipe.Name)
+	require.Equal(t, []string{"2 cups api-e2e-recipe-flour", "api-e2e-recipe-salt"}, recipe.Ingredients)
+	require.NotNil(t, recipe.Servings)
+	require.Equal(t, 4, *recipe.Servings)
+	path := "/recipes/" + uintToStr(recipe.ID)
+
+	res, err = apiSess.PostJSON(path+"/add", []byte(`{"servings":6}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	var added dto.AddRecipeResponse
+	require.NoError(t, json.Unmarshal(b, &added))
+	require.Len(t, added.Added, 2)
+	require.Empty(t, added.Merged)
+	require.Equal(t, "api-e2e-recipe-flour", added.Added[0].ItemDesc)
+	require.NotNil(t, added.Added[0].Quantity)
+	require.Equal(t, 3.0, *added.Added[0].Quantity)
+
+	// adding it again tops up what's already on the list
+	res, err = apiSess.PostJSON(path+"/add", []byte(`{}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", string(b))
+	added = dto.AddRecipeResponse{}
+	require.NoError(t, json.Unmarshal(b, &added))
+	require.Empty(t, added.Added)
+	require.Len(t, added.Merged, 2)
+	require.Equal(t, "api-e2e-recipe-flour", added.Merged[0].ItemDesc)
+	require.Equal(t, 5.0, *added.Merged[0].Quantity)
+
+	res, err = apiSess.PostJSON(path+"/add", []byte(`{"servings":1000}`))
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.DeleteNoBody(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode, "%s", string(b))
+
+	res, err = apiSess.Get(path)
+	require.NoError(t, err)
+	b, err = apiharness.ReadBodyAndClose(res)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode, "%s", string(b))
+}
@@ -1 +1,72 @@
 package api
+
+import (
+	"context"
+
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+)
+
+// canSeeGroceryList checks whether the caller can see the grocery list (nil for the default list).
+func canSeeGroceryList(ctx context.Context, authContext *apimw.AuthContext, groceryList *models.GroceryList) (bool, error) {
+	if groceryList == nil {
+		return true, nil
+	}
+	return grocery.Service.CanSeeGroceryList(ctx, authContext.GetMemberContext(), groceryList)
+}
+
+// findHiddenGroceryEntryIDs returns the IDs of the entries that are on grocery lists that the caller cannot see.
+func findHiddenGroceryEntryIDs(ctx context.Context, authContext *apimw.AuthContext, entries []models.GroceryEntry) ([]uint, error) {
+	canSeeByListID := make(map[uint]bool)
+	hiddenIDs := make([]uint, 0)
+	for _, g := range entries {
+		if g.GroceryListID == nil || *g.GroceryListID == 0 {
+			continue
+		}
+		canSee, ok := canSeeByListID[*g.GroceryListID]
+		if !ok {
+			groceryList, err := groceryListRepo.GetByQuery(&models.GroceryList{ID: *g.GroceryListID, GuildID: authContext.GuildID})
+			if err != nil {
+				return nil, err
+			}
+			canSee, err = canSeeGroceryList(ctx, authContext, groceryList)
+			if err != nil {
+				return nil, err
+			}
+			canSeeByListID[*g.GroceryListID] = canSee
+		}
+		if !canSee {
+			hiddenIDs = append(hiddenIDs, g.ID)
+		}
+	}
+	return hiddenIDs, nil
+}
+
+// findGuildGroceryEntry looks up one of the guild's grocery entries (including the ones in grocery lists that other guilds have shared with it),
+// along with its grocery list. Returns a nil entry if there's no such entry, or if it's on a grocery list that the caller cannot see.
+func findGuildGroceryEntry(ctx context.Context, authContext *apimw.AuthContext, id uint) (*models.GroceryEntry, *models.GroceryList, error) {
+	entries, err := grocery.Service.FindGuildGroceryEntries(ctx, authContext.GuildID, []uint{id})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil, nil
+	}
+	entry := &entries[0]
+	var groceryList *models.GroceryList
+	if entry.GroceryListID != nil && *entry.GroceryListID != 0 {
+		groceryList, err = grocery.Service.GetGuildGroceryList(ctx, authContext.GuildID, &models.GroceryList{ID: *entry.GroceryListID})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	canSee, err := canSeeGroceryList(ctx, authContext, groceryList)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canSee {
+		return nil, nil, nil
+	}
+	return entry, groceryList, nil
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"strings"
@@ -12,8 +13,10 @@ import (
 	"github.com/patrickmn/go-cache"
 	"github.com/verzac/grocer-discord-bot/auth"
 	"github.com/verzac/grocer-discord-bot/config"
+	"github.com/verzac/grocer-discord-bot/dto"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -30,6 +33,37 @@ type AuthContext struct {
 	GuildID string
 }
 
+// GetMemberContext returns the member making the request. API clients (Basic auth) aren't anyone in particular, so they can only see public grocery lists.
+func (c *AuthContext) GetMemberContext() *dto.MemberContext {
+	return &dto.MemberContext{
+		GuildID: c.GuildID,
+		UserID:  c.UserID,
+	}
+}
+
+// GetGroceryList returns the grocery list with that ID (nil or 0 for the default list), including the lists that other guilds have
+// shared with this one. Lists that the caller cannot see are treated as if they don't exist.
+func (c *AuthContext) GetGroceryList(ctx context.Context, id *uint) (*models.GroceryList, error) {
+	if id == nil || *id == 0 {
+		return nil, nil
+	}
+	groceryList, err := grocery.Service.GetGuildGroceryList(ctx, c.GuildID, &models.GroceryList{ID: *id})
+	if err != nil {
+		return nil, err
+	}
+	if groceryList == nil {
+		return nil, errGroceryListNotFound
+	}
+	canSee, err := grocery.Service.CanSeeGroceryList(ctx, c.GetMemberContext(), groceryList)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee {
+		return nil, errGroceryListNotFound
+	}
+	return groceryList, nil
+}
+
 var bearerGuildOKCache = cache.New(60*time.Second, 2*time.Minute)
 
 const (
@@ -38,6 +72,7 @@ const (
 
 var (
 	errIncorrectToken             = echo.NewHTTPError(403, "Forbidden.")
+	errGroceryListNotFound        = echo.NewHTTPError(400, "Cannot find grocery list with that ID.")
 	bearerPathSkipGuildIDCheckMap = map[string]bool{
 		"/guilds":      false,
 		"/auth/logout": false,
@@ -145,8 +180,16 @@ func AuthMiddleware(apiKeyRepo repositories.ApiClientRepository, logger *zap.Log
 						})
 					}
 					guildID := strings.TrimSpace(c.Request().Header.Get(HeaderXGuildID))
-					if guildID == "" {
-						return echo.NewHTTPError(400, "X-Guild-ID header is required.")
+					if guildID == "" || guildID == models.PersonalGuildID(discordUserID) {
+						// the user's personal grocery lists, i.e. the ones in their DMs with GroceryBot
+						return next(&AuthContext{
+							Context: c,
+							UserID:  discordUserID,
+							GuildID: models.PersonalGuildID(discordUserID),
+						})
+					}
+					if models.IsPersonalGuildID(guildID) {
+						return errIncorrectToken
 					}
 					if discordSess == nil {
 						return echo.NewHTTPError(500, "Cannot verify token.")
@@ -0,0 +1,92 @@
+package routeevents
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/services/events"
+	"go.uber.org/zap"
+)
+
+const (
+	// keeps proxies & load balancers from closing the connection when nothing has changed for a while
+	heartbeatInterval = 25 * time.Second
+	// how long clients should wait before reconnecting
+	reconnectDelay = 3 * time.Second
+
+	HeaderLastEventID = "Last-Event-ID"
+)
+
+// Register mounts GET /events, which streams the guild's grocery changes as Server-Sent Events.
+func Register(e *echo.Echo, logger *zap.Logger) {
+	logger = logger.Named("events")
+
+	e.GET("/events", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		guildID := authContext.GuildID
+
+		lastEventID := c.Request().Header.Get(HeaderLastEventID)
+		if lastEventID == "" {
+			// for clients that can't set headers when reconnecting
+			lastEventID = c.QueryParam("last_event_id")
+		}
+		sub, replay, err := events.Service.Subscribe(guildID, lastEventID)
+		if errors.Is(err, events.ErrTooManySubscribers) {
+			return echo.NewHTTPError(429, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+		defer events.Service.Unsubscribe(sub)
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+		res.Header().Set("Connection", "keep-alive")
+		// stops nginx from buffering the stream
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(200)
+		if _, err := fmt.Fprintf(res, "retry: %d\n\n", reconnectDelay.Milliseconds()); err != nil {
+			return nil
+		}
+		for _, ev := range replay {
+			if err := writeEvent(res, ev); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		ctx := c.Request().Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case ev, ok := <-sub.C:
+				if !ok {
+					// we've fallen behind - the client will catch up through Last-Event-ID when it reconnects
+					return nil
+				}
+				if err := writeEvent(res, ev); err != nil {
+					return nil
+				}
+			case <-ticker.C:
+				if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+					return nil
+				}
+			}
+			res.Flush()
+		}
+	})
+}
+
+func writeEvent(res *echo.Response, ev events.Event) error {
+	_, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+	return err
+}
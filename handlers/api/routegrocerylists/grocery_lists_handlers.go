@@ -1,6 +1,7 @@
 package routegrocerylists
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -13,12 +14,25 @@ import (
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
 	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/history"
 	"github.com/verzac/grocer-discord-bot/services/registration"
 	"github.com/verzac/grocer-discord-bot/utils"
 	"go.uber.org/zap"
 )
 
-// Register mounts /grocery-lists mutation routes (POST, DELETE /:id, PATCH /:id).
+// hideInvisibleGroceryList returns nil if the caller cannot see the grocery list, so that it's treated as if it doesn't exist.
+func hideInvisibleGroceryList(ctx context.Context, authContext *apimw.AuthContext, groceryList *models.GroceryList) (*models.GroceryList, error) {
+	if groceryList == nil {
+		return nil, nil
+	}
+	canSee, err := grocery.Service.CanSeeGroceryList(ctx, authContext.GetMemberContext(), groceryList)
+	if err != nil || !canSee {
+		return nil, err
+	}
+	return groceryList, nil
+}
+
+// Register mounts /grocery-lists mutation routes (POST, DELETE /:id, PATCH /:id) and GET /:id/history.
 func Register(
 	e *echo.Echo,
 	logger *zap.Logger,
@@ -74,9 +88,11 @@ func Register(
 			}
 			return err
 		}
+		grocery.Service.OnGroceryListCreated(ctx, newList)
 		if err := grocery.Service.UpdateGuildGrohere(ctx, guildID); err != nil {
 			logger.Error("Failed to update grohere after grocery list creation", zap.Error(err))
 		}
+		c.Response().Header().Set(utils.HeaderETag, newList.GetETag())
 		return c.JSON(201, newList)
 	})
 	e.DELETE("/grocery-lists/:id", func(c echo.Context) error {
@@ -98,9 +114,17 @@ func Register(
 		if err != nil {
 			return err
 		}
+		groceryList, err = hideInvisibleGroceryList(ctx, authContext, groceryList)
+		if err != nil {
+			return err
+		}
 		if groceryList == nil {
 			return echo.NewHTTPError(404, repositories.ErrGroceryListNotFound.Error())
 		}
+		ifMatch := c.Request().Header.Get(utils.HeaderIfMatch)
+		if !utils.IfMatch(ifMatch, groceryList.GetETag()) {
+			return echo.NewHTTPError(412, repositories.ErrVersionMismatch.Error())
+		}
 
 		count, err := groceryEntryRepo.GetCount(&models.GroceryEntry{GuildID: guildID, GroceryListID: &groceryList.ID})
 		if err != nil {
@@ -134,12 +158,21 @@ func Register(
 			}
 		}
 
-		if err := groceryListRepo.WithContext(ctx).Delete(groceryList); err != nil {
+		if ifMatch != "" {
+			err = groceryListRepo.WithContext(ctx).DeleteIfVersion(groceryList, groceryList.Version)
+		} else {
+			err = groceryListRepo.WithContext(ctx).Delete(groceryList)
+		}
+		if err != nil {
 			if err == repositories.ErrGroceryListNotFound {
 				return echo.NewHTTPError(404, err.Error())
 			}
+			if err == repositories.ErrVersionMismatch {
+				return echo.NewHTTPError(412, err.Error())
+			}
 			return err
 		}
+		grocery.Service.OnGroceryListDeleted(ctx, groceryList)
 
 		if err := grocery.Service.UpdateGuildGrohere(ctx, guildID); err != nil {
 			logger.Error("Failed to update grohere after grocery list deletion", zap.Error(err))
@@ -179,12 +212,28 @@ func Register(
 		if err != nil {
 			return err
 		}
+		groceryList, err = hideInvisibleGroceryList(ctx, authContext, groceryList)
+		if err != nil {
+			return err
+		}
 		if groceryList == nil {
 			return echo.NewHTTPError(404, repositories.ErrGroceryListNotFound.Error())
 		}
+		ifMatch := c.Request().Header.Get(utils.HeaderIfMatch)
+		if !utils.IfMatch(ifMatch, groceryList.GetETag()) {
+			return echo.NewHTTPError(412, repositories.ErrVersionMismatch.Error())
+		}
 
 		groceryList.FancyName = fancyName
-		if err := groceryListRepo.WithContext(ctx).Save(groceryList); err != nil {
+		if ifMatch != "" {
+			err = groceryListRepo.WithContext(ctx).SaveIfVersion(groceryList, groceryList.Version)
+		} else {
+			err = groceryListRepo.WithContext(ctx).Save(groceryList)
+		}
+		if err != nil {
+			if err == repositories.ErrVersionMismatch {
+				return echo.NewHTTPError(412, err.Error())
+			}
 			return err
 		}
 
@@ -192,6 +241,51 @@ func Register(
 			logger.Error("Failed to update grohere after grocery list edit", zap.Error(err))
 		}
 
+		c.Response().Header().Set(utils.HeaderETag, groceryList.GetETag())
 		return c.JSON(200, groceryList)
 	})
+	e.GET("/grocery-lists/:id/history", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		guildID := authContext.GuildID
+
+		// 0 refers to the guild's default grocery list
+		idStr := c.Param("id")
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(400, "Invalid ID format.")
+		}
+		limit := history.DefaultHistoryLimit
+		if limitStr := c.QueryParam("limit"); limitStr != "" {
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil || limit < 1 || limit > history.MaxHistoryLimit {
+				return echo.NewHTTPError(400, fmt.Sprintf("limit must be between 1 and %d.", history.MaxHistoryLimit))
+			}
+		}
+
+		ctx := c.Request().Context()
+		var groceryList *models.GroceryList
+		if id != 0 {
+			groceryList, err = groceryListRepo.WithContext(ctx).GetByQuery(&models.GroceryList{
+				ID:      uint(id),
+				GuildID: guildID,
+			})
+			if err != nil {
+				return err
+			}
+			groceryList, err = hideInvisibleGroceryList(ctx, authContext, groceryList)
+			if err != nil {
+				return err
+			}
+			if groceryList == nil {
+				return echo.NewHTTPError(404, repositories.ErrGroceryListNotFound.Error())
+			}
+		}
+
+		changes, err := history.Service.GetGroceryListHistory(ctx, guildID, groceryList, limit)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, changes)
+	})
 }
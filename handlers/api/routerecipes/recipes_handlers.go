@@ -0,0 +1,152 @@
+package routerecipes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
+	"go.uber.org/zap"
+)
+
+var errRecipeNotFound = echo.NewHTTPError(404, "Recipe not found.")
+
+// Register mounts /recipes routes (GET, POST, GET /:id, DELETE /:id) and POST /:id/add.
+func Register(e *echo.Echo, logger *zap.Logger) {
+	logger = logger.Named("recipes")
+
+	e.GET("/recipes", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		recipeBook, err := recipes.Service.GetRecipes(c.Request().Context(), authContext.GuildID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, recipeBook)
+	})
+	e.POST("/recipes", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		req := dto.SaveRecipeRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		if err := c.Validate(&req); err != nil {
+			return echo.NewHTTPError(400, err.Error())
+		}
+		recipe := &models.Recipe{
+			GuildID:   authContext.GuildID,
+			Name:      req.Name,
+			Title:     req.Title,
+			SourceURL: req.SourceURL,
+			Servings:  req.Servings,
+		}
+		if authContext.UserID != "" {
+			recipe.CreatedByID = &authContext.UserID
+		}
+		if err := recipe.SetIngredients(req.Ingredients); err != nil {
+			return err
+		}
+
+		replaced, err := recipes.Service.SaveRecipe(c.Request().Context(), recipe)
+		if recipes.IsUserError(err) {
+			return echo.NewHTTPError(400, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+		if replaced {
+			return c.JSON(200, recipe)
+		}
+		return c.JSON(201, recipe)
+	})
+	e.GET("/recipes/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		recipe, err := getRecipe(c, authContext)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, recipe)
+	})
+	e.POST("/recipes/:id/add", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		ctx := c.Request().Context()
+
+		recipe, err := getRecipe(c, authContext)
+		if err != nil {
+			return err
+		}
+		req := dto.AddRecipeRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		groceryList, err := authContext.GetGroceryList(ctx, req.GroceryListID)
+		if err != nil {
+			return err
+		}
+		servings := 0
+		if req.Servings != nil {
+			servings = *req.Servings
+		}
+
+		added, merged, err := recipes.Service.AddRecipe(ctx, authContext.GuildID, recipe, servings, groceryList, authContext.UserID)
+		if err != nil {
+			var limitErr *recipes.GroceryEntryLimitError
+			if errors.As(err, &limitErr) {
+				return echo.NewHTTPError(400, fmt.Sprintf("You've reached the max number of grocery entries that you can have for your server. Limit: %d | Server ID: %s", limitErr.Limit, groceryList.GetGuildID(authContext.GuildID)))
+			}
+			if recipes.IsUserError(err) {
+				return echo.NewHTTPError(400, err.Error())
+			}
+			return err
+		}
+		if added == nil {
+			added = []models.GroceryEntry{}
+		}
+		if merged == nil {
+			merged = []models.GroceryEntry{}
+		}
+		return c.JSON(200, dto.AddRecipeResponse{
+			Added:  added,
+			Merged: merged,
+		})
+	})
+	e.DELETE("/recipes/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		recipe, err := getRecipe(c, authContext)
+		if err != nil {
+			return err
+		}
+		if err := recipes.Service.DeleteRecipe(c.Request().Context(), recipe); err != nil {
+			return err
+		}
+		return c.NoContent(204)
+	})
+}
+
+func getRecipe(c echo.Context, authContext *apimw.AuthContext) (*models.Recipe, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, echo.NewHTTPError(400, "Invalid ID format.")
+	}
+	recipe, err := recipes.Service.GetRecipeByID(c.Request().Context(), authContext.GuildID, uint(id))
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil {
+		return nil, errRecipeNotFound
+	}
+	return recipe, nil
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -18,9 +20,14 @@ import (
 	"github.com/verzac/grocer-discord-bot/handlers"
 	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
 	"github.com/verzac/grocer-discord-bot/handlers/api/routeauth"
+	"github.com/verzac/grocer-discord-bot/handlers/api/routeevents"
 	"github.com/verzac/grocer-discord-bot/handlers/api/routegrocerylists"
 	"github.com/verzac/grocer-discord-bot/handlers/api/routeguilds"
+	"github.com/verzac/grocer-discord-bot/handlers/api/routerecipes"
+	"github.com/verzac/grocer-discord-bot/handlers/api/routesync"
+	"github.com/verzac/grocer-discord-bot/handlers/api/routetemplates"
 	"github.com/verzac/grocer-discord-bot/handlers/api/routetest"
+	"github.com/verzac/grocer-discord-bot/handlers/api/routewebhooks"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/monitoring/groprometheus"
 	"github.com/verzac/grocer-discord-bot/repositories"
@@ -28,6 +35,7 @@ import (
 	"github.com/verzac/grocer-discord-bot/services/oauthsession"
 	"github.com/verzac/grocer-discord-bot/services/registration"
 	"github.com/verzac/grocer-discord-bot/utils"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -65,11 +73,16 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 	}
 	e.Logger.SetHeader("L-${time_rfc3339} ${level} ${short_file}:${line}")
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: strings.Split(ao, ","),
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, apimw.HeaderXGuildID},
+		AllowOrigins:  strings.Split(ao, ","),
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, apimw.HeaderXGuildID, utils.HeaderIfMatch, routeevents.HeaderLastEventID},
+		ExposeHeaders: []string{utils.HeaderETag},
 	}))
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
 		Timeout: 10 * time.Second,
+		Skipper: func(c echo.Context) bool {
+			// event streams are meant to stay open
+			return c.Path() == "/events"
+		},
 	}))
 	e.Use(middleware.Recover())
 	e.Validator = utils.NewCustomValidator()
@@ -100,11 +113,21 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 		defer handlers.Recover(logger)
 		authContext := c.(*apimw.AuthContext)
 		guildID := authContext.GuildID
-		groceryEntries, err := groceryEntryRepo.FindByQuery(&models.GroceryEntry{GuildID: guildID})
+		// includes the lists that other guilds have shared with this one
+		groceryLists, groceryEntries, err := grocery.Service.FindGuildGroceryLists(c.Request().Context(), guildID)
 		if err != nil {
 			return c.String(500, err.Error())
 		}
-		groceryLists, err := groceryListRepo.FindByQuery(&models.GroceryList{GuildID: guildID})
+		if category := strings.TrimSpace(c.QueryParam("category")); category != "" {
+			filtered := make([]models.GroceryEntry, 0, len(groceryEntries))
+			for _, g := range groceryEntries {
+				if strings.EqualFold(g.GetCategory(), category) {
+					filtered = append(filtered, g)
+				}
+			}
+			groceryEntries = filtered
+		}
+		groceryLists, groceryEntries, err = grocery.Service.FilterVisibleGroceryLists(c.Request().Context(), authContext.GetMemberContext(), groceryLists, groceryEntries)
 		if err != nil {
 			return c.String(500, err.Error())
 		}
@@ -116,6 +139,11 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 		return c.JSON(200, out)
 	})
 	routegrocerylists.Register(e, logger, groceryListRepo, groceryEntryRepo, grohereRecordRepo, discordSess)
+	routesync.Register(e, logger)
+	routewebhooks.Register(e, logger, discordSess)
+	routeevents.Register(e, logger)
+	routetemplates.Register(e, logger)
+	routerecipes.Register(e, logger)
 	e.DELETE("/groceries", func(c echo.Context) error {
 		defer handlers.Recover(logger)
 		authContext := c.(*apimw.AuthContext)
@@ -129,8 +157,19 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 		if err := c.Validate(&req); err != nil {
 			return echo.NewHTTPError(400, err.Error())
 		}
+		entries, err := groceryEntryRepo.FindByGuildAndIDs(ctx, guildID, req.IDs)
+		if err != nil {
+			return err
+		}
+		hiddenIDs, err := findHiddenGroceryEntryIDs(ctx, authContext, entries)
+		if err != nil {
+			return err
+		}
+		if len(hiddenIDs) > 0 {
+			return echo.NewHTTPError(404, (&grocery.GroceryEntriesNotFoundError{IDs: hiddenIDs}).Error())
+		}
 
-		if err := grocery.Service.DeleteGroceriesByIDs(ctx, guildID, req.IDs); err != nil {
+		if err := grocery.Service.DeleteGroceriesByIDs(ctx, guildID, req.IDs, authContext.UserID); err != nil {
 			var notFound *grocery.GroceryEntriesNotFoundError
 			if errors.As(err, &notFound) {
 				return echo.NewHTTPError(404, err.Error())
@@ -153,35 +192,33 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 			return echo.NewHTTPError(400, "Invalid ID format.")
 		}
 
-		// Look up the grocery entry by ID and GuildID
-		entries, err := groceryEntryRepo.FindByQuery(&models.GroceryEntry{
-			ID:      uint(id),
-			GuildID: guildID,
-		})
+		// Look up the grocery entry (and its grocery list) in the guild's lists, including the ones shared with it
+		found, groceryList, err := findGuildGroceryEntry(ctx, authContext, uint(id))
 		if err != nil {
 			return err
 		}
-		if len(entries) == 0 {
+		if found == nil {
 			return echo.NewHTTPError(404, "Grocery entry not found.")
 		}
-		entry := entries[0]
-
-		// Resolve grocery list if the entry has one
-		var groceryList *models.GroceryList
-		if entry.GroceryListID != nil && *entry.GroceryListID != 0 {
-			groceryList, err = groceryListRepo.GetByQuery(&models.GroceryList{
-				ID:      *entry.GroceryListID,
-				GuildID: guildID,
-			})
-			if err != nil {
-				return err
-			}
+		entry := *found
+		ifMatch := c.Request().Header.Get(utils.HeaderIfMatch)
+		if !utils.IfMatch(ifMatch, entry.GetETag()) {
+			return echo.NewHTTPError(412, repositories.ErrVersionMismatch.Error())
 		}
 
-		// Delete the entry
-		if err := groceryEntryRepo.WithContext(ctx).Delete(ctx, &entry); err != nil {
+		// Delete the entry; if the client has sent an If-Match, make sure nobody has changed it since we've looked it up
+		if ifMatch != "" {
+			err = groceryEntryRepo.WithContext(ctx).DeleteIfVersion(ctx, &entry, entry.Version)
+		} else {
+			err = groceryEntryRepo.WithContext(ctx).Delete(ctx, &entry)
+		}
+		if err != nil {
+			if errors.Is(err, repositories.ErrVersionMismatch) {
+				return echo.NewHTTPError(412, err.Error())
+			}
 			return err
 		}
+		grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeRemove, []models.GroceryEntry{entry}, authContext.UserID))
 
 		// Call post-deletion hook
 		if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, guildID); err != nil {
@@ -190,7 +227,123 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 
 		return c.NoContent(204)
 	})
-	// Future edit endpoints should set UpdatedByID from authContext.UserID when non-empty (Bearer), like POST /groceries.
+	e.PATCH("/groceries/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		ctx := c.Request().Context()
+		guildID := authContext.GuildID
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil || id == 0 {
+			return echo.NewHTTPError(400, "Invalid ID format.")
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		// a null grocery_list_id moves the entry into the default list, so we need to know whether it has been sent at all
+		rawReq := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &rawReq); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		req := dto.UpdateGroceryEntryRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		if err := c.Validate(&req); err != nil {
+			return echo.NewHTTPError(400, err.Error())
+		}
+		_, hasGroceryListID := rawReq["grocery_list_id"]
+		if req.ItemDesc == nil && !hasGroceryListID {
+			return echo.NewHTTPError(400, "At least one of item_desc or grocery_list_id is required.")
+		}
+
+		found, oldGroceryList, err := findGuildGroceryEntry(ctx, authContext, uint(id))
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			return echo.NewHTTPError(404, "Grocery entry not found.")
+		}
+		entry := *found
+		ifMatch := c.Request().Header.Get(utils.HeaderIfMatch)
+		if !utils.IfMatch(ifMatch, entry.GetETag()) {
+			return echo.NewHTTPError(412, repositories.ErrVersionMismatch.Error())
+		}
+		before := entry
+
+		newGroceryList := oldGroceryList
+		if hasGroceryListID {
+			newGroceryList = nil
+			if req.GroceryListID != nil && *req.GroceryListID != 0 {
+				newGroceryList, err = grocery.Service.GetGuildGroceryList(ctx, guildID, &models.GroceryList{ID: *req.GroceryListID})
+				if err != nil {
+					return err
+				}
+				if newGroceryList == nil {
+					return echo.NewHTTPError(400, "Cannot find grocery list with that ID.")
+				}
+				canSee, err := canSeeGroceryList(ctx, authContext, newGroceryList)
+				if err != nil {
+					return err
+				}
+				if !canSee {
+					return echo.NewHTTPError(400, "Cannot find grocery list with that ID.")
+				}
+			}
+			// entries stay with the guild that owns them, so they can't be moved into another guild's lists
+			if newGroceryList.GetGuildID(guildID) != entry.GuildID {
+				return echo.NewHTTPError(400, "Grocery entries cannot be moved between servers' grocery lists.")
+			}
+			entry.GroceryListID = newGroceryList.GetID()
+		}
+
+		if req.ItemDesc != nil {
+			itemDesc, quantity, unit := groceryutils.ParseQuantity(*req.ItemDesc)
+			if itemDesc == "" {
+				return echo.NewHTTPError(400, "item_desc cannot be empty.")
+			}
+			// like !groedit, the new item_desc replaces the quantity too (clearing it if there isn't one)
+			entry.ItemDesc, entry.Quantity, entry.Unit = itemDesc, quantity, unit
+			// the item may have changed entirely, so re-categorise it
+			entry.Category = nil
+			edited := []models.GroceryEntry{entry}
+			if err := grocery.Service.AssignCategories(ctx, entry.GuildID, edited); err != nil {
+				return err
+			}
+			entry.Category = edited[0].Category
+		}
+		if authContext.UserID != "" {
+			entry.UpdatedByID = &authContext.UserID
+		}
+		entry.GroceryList = nil
+		if ifMatch != "" {
+			err = groceryEntryRepo.WithContext(ctx).PutIfVersion(ctx, &entry, before.Version)
+		} else {
+			err = groceryEntryRepo.WithContext(ctx).Put(&entry)
+		}
+		if err != nil {
+			if errors.Is(err, repositories.ErrVersionMismatch) {
+				return echo.NewHTTPError(412, err.Error())
+			}
+			return err
+		}
+		grocery.Service.OnGroceryEntriesChanged(ctx, []models.GroceryEntryChange{models.NewGroceryEntryChange(models.GroceryEntryChangeEdit, &before, &entry, authContext.UserID)})
+
+		if err := grocery.Service.OnGroceryListEdit(ctx, oldGroceryList, guildID); err != nil {
+			logger.Error("Failed to run OnGroceryListEdit", zap.Error(err))
+		}
+		if oldID, newID := oldGroceryList.GetID(), newGroceryList.GetID(); (oldID == nil) != (newID == nil) || (oldID != nil && *oldID != *newID) {
+			// the entry has been moved, so the list it was moved into has changed too
+			if err := grocery.Service.OnGroceryListEdit(ctx, newGroceryList, guildID); err != nil {
+				logger.Error("Failed to run OnGroceryListEdit", zap.Error(err))
+			}
+		}
+
+		c.Response().Header().Set(utils.HeaderETag, entry.GetETag())
+		return c.JSON(200, entry)
+	})
 	// create new grocery
 	e.POST("/groceries", func(c echo.Context) error {
 		authContext := c.(*apimw.AuthContext)
@@ -209,15 +362,34 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 		}
 		groceryEntry.CreatedAt = time.Time{}
 		groceryEntry.UpdatedAt = time.Time{}
+		groceryEntry.Version = 0
 		if groceryEntry.ID != 0 {
 			return echo.NewHTTPError(400, "ID must be empty.")
 		}
 		if authContext.UserID != "" {
 			groceryEntry.UpdatedByID = &authContext.UserID
 		}
+		if groceryEntry.Quantity == nil && groceryEntry.Unit == nil {
+			// only parse if the caller hasn't given us a structured quantity
+			itemDesc, quantity, unit := groceryutils.ParseQuantity(groceryEntry.ItemDesc)
+			if itemDesc != "" {
+				groceryEntry.ItemDesc, groceryEntry.Quantity, groceryEntry.Unit = itemDesc, quantity, unit
+			}
+		}
+		if groceryEntry.Category != nil && strings.TrimSpace(*groceryEntry.Category) != "" {
+			category := groceryutils.CanonicalCategory(*groceryEntry.Category)
+			groceryEntry.Category = &category
+		} else {
+			groceryEntry.Category = nil
+		}
+		categorised := []models.GroceryEntry{groceryEntry}
+		if err := grocery.Service.AssignCategories(ctx, guildID, categorised); err != nil {
+			return err
+		}
+		groceryEntry = categorised[0]
 		var groceryList *models.GroceryList
 		if groceryEntry.GroceryListID != nil && *groceryEntry.GroceryListID != 0 {
-			groceryList, err := groceryListRepo.GetByQuery(&models.GroceryList{
+			groceryList, err = groceryListRepo.GetByQuery(&models.GroceryList{
 				ID:      *groceryEntry.GroceryListID,
 				GuildID: guildID,
 			})
@@ -227,6 +399,31 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 			if groceryList == nil {
 				return echo.NewHTTPError(400, "Cannot find grocery list with that ID.")
 			}
+			canSee, err := canSeeGroceryList(ctx, authContext, groceryList)
+			if err != nil {
+				return err
+			}
+			if !canSee {
+				return echo.NewHTTPError(400, "Cannot find grocery list with that ID.")
+			}
+		}
+		if c.QueryParam("merge") == "true" {
+			// merge into a near-duplicate on the same list if we can; otherwise add it like usual
+			merged, _, _, err := grocery.Service.MergeDuplicates(ctx, groceryList, guildID, []models.GroceryEntry{groceryEntry})
+			if err != nil {
+				return err
+			}
+			if len(merged) > 0 {
+				if rErr := groceryEntryRepo.UpdateAndAddToGroceryList(ctx, groceryList, merged, nil, guildID); rErr != nil {
+					return rErr
+				}
+				grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeMerge, merged, authContext.UserID))
+				if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, guildID); err != nil {
+					logger.Error("Failed to run OnGroceryListEdit", zap.Error(err))
+				}
+				c.Response().Header().Set(utils.HeaderETag, merged[0].GetETag())
+				return c.JSON(200, merged[0])
+			}
 		}
 		limitOk, groceryEntryLimit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, guildID, 1)
 		if err != nil {
@@ -245,9 +442,11 @@ func RegisterAndStart(logger *zap.Logger, db *gorm.DB, grobotVersion string, dis
 				return rErr
 			}
 		}
+		grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, inputEntries, authContext.UserID))
 		if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, guildID); err != nil {
 			logger.Error("Failed to run OnGroceryListEdit", zap.Error(err))
 		}
+		c.Response().Header().Set(utils.HeaderETag, inputEntries[0].GetETag())
 		return c.JSON(201, inputEntries[0])
 	})
 	e.GET("/registrations", func(c echo.Context) error {
@@ -0,0 +1,30 @@
+package routesync
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/services/delta"
+	"go.uber.org/zap"
+)
+
+// Register mounts GET /sync, which returns what has changed in the guild since the client's last sync.
+func Register(e *echo.Echo, logger *zap.Logger) {
+	logger = logger.Named("sync")
+
+	e.GET("/sync", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		out, err := delta.Service.GetChangesSince(c.Request().Context(), authContext.GetMemberContext(), c.QueryParam("since"))
+		if errors.Is(err, delta.ErrInvalidCursor) {
+			return echo.NewHTTPError(400, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, out)
+	})
+}
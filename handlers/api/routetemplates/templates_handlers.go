@@ -0,0 +1,139 @@
+package routetemplates
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/templates"
+	"go.uber.org/zap"
+)
+
+var errTemplateNotFound = echo.NewHTTPError(404, "Template not found.")
+
+// Register mounts /templates routes (GET, POST, GET /:id, DELETE /:id) and POST /:id/apply.
+func Register(e *echo.Echo, logger *zap.Logger) {
+	logger = logger.Named("templates")
+
+	e.GET("/templates", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		groceryTemplates, err := templates.Service.GetTemplates(c.Request().Context(), authContext.GuildID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, groceryTemplates)
+	})
+	e.POST("/templates", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		ctx := c.Request().Context()
+
+		req := dto.SaveGroceryTemplateRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		if err := c.Validate(&req); err != nil {
+			return echo.NewHTTPError(400, err.Error())
+		}
+		groceryList, err := authContext.GetGroceryList(ctx, req.GroceryListID)
+		if err != nil {
+			return err
+		}
+
+		template, replaced, err := templates.Service.SaveTemplate(ctx, authContext.GuildID, req.Name, groceryList, authContext.UserID)
+		if err != nil {
+			for _, target := range []error{
+				templates.ErrTemplateLimitReached,
+				templates.ErrInvalidTemplateName,
+				templates.ErrEmptyTemplate,
+				templates.ErrTemplateTooLarge,
+				templates.ErrGroceryListNotPublic,
+			} {
+				if errors.Is(err, target) {
+					return echo.NewHTTPError(400, err.Error())
+				}
+			}
+			return err
+		}
+		if replaced {
+			return c.JSON(200, template)
+		}
+		return c.JSON(201, template)
+	})
+	e.GET("/templates/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		template, err := getTemplate(c, authContext)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, template)
+	})
+	e.POST("/templates/:id/apply", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		ctx := c.Request().Context()
+
+		template, err := getTemplate(c, authContext)
+		if err != nil {
+			return err
+		}
+		req := dto.ApplyGroceryTemplateRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		groceryList, err := authContext.GetGroceryList(ctx, req.GroceryListID)
+		if err != nil {
+			return err
+		}
+
+		added, skipped, err := templates.Service.ApplyTemplate(ctx, authContext.GuildID, template, groceryList, authContext.UserID)
+		if err != nil {
+			var limitErr *templates.GroceryEntryLimitError
+			if errors.As(err, &limitErr) {
+				return echo.NewHTTPError(400, fmt.Sprintf("You've reached the max number of grocery entries that you can have for your server. Limit: %d | Server ID: %s", limitErr.Limit, groceryList.GetGuildID(authContext.GuildID)))
+			}
+			return err
+		}
+		return c.JSON(200, dto.ApplyGroceryTemplateResponse{
+			Added:   added,
+			Skipped: skipped,
+		})
+	})
+	e.DELETE("/templates/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+
+		template, err := getTemplate(c, authContext)
+		if err != nil {
+			return err
+		}
+		if err := templates.Service.DeleteTemplate(c.Request().Context(), template); err != nil {
+			return err
+		}
+		return c.NoContent(204)
+	})
+}
+
+func getTemplate(c echo.Context, authContext *apimw.AuthContext) (*models.GroceryTemplate, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, echo.NewHTTPError(400, "Invalid ID format.")
+	}
+	template, err := templates.Service.GetTemplateByID(c.Request().Context(), authContext.GuildID, uint(id))
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errTemplateNotFound
+	}
+	return template, nil
+}
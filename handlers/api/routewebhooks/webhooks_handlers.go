@@ -0,0 +1,159 @@
+package routewebhooks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/labstack/echo/v4"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	apimw "github.com/verzac/grocer-discord-bot/handlers/api/middleware"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/webhook"
+	"go.uber.org/zap"
+)
+
+// Register mounts /webhooks routes (GET, POST, DELETE /:id) and GET /:id/deliveries.
+// Like /developer, these are only available to API clients and to server administrators.
+func Register(e *echo.Echo, logger *zap.Logger, discordSess *discordgo.Session) {
+	logger = logger.Named("webhooks")
+	requireAdministrator := newRequireAdministrator(logger, discordSess)
+
+	e.GET("/webhooks", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		if err := requireAdministrator(authContext); err != nil {
+			return err
+		}
+
+		subscriptions, err := webhook.Service.GetSubscriptions(c.Request().Context(), authContext.GuildID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, subscriptions)
+	})
+	e.POST("/webhooks", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		if err := requireAdministrator(authContext); err != nil {
+			return err
+		}
+
+		req := dto.CreateWebhookRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(400, "Invalid request body.")
+		}
+		if err := c.Validate(&req); err != nil {
+			return echo.NewHTTPError(400, err.Error())
+		}
+
+		subscription, secret, err := webhook.Service.Subscribe(c.Request().Context(), authContext.GuildID, req.URL, authContext.UserID)
+		if err != nil {
+			if errors.Is(err, webhook.ErrInvalidURL) || errors.Is(err, webhook.ErrLimitReached) {
+				return echo.NewHTTPError(400, err.Error())
+			}
+			return err
+		}
+		return c.JSON(201, dto.CreateWebhookResponse{
+			Webhook: *subscription,
+			Secret:  secret,
+		})
+	})
+	e.DELETE("/webhooks/:id", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		if err := requireAdministrator(authContext); err != nil {
+			return err
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil || id == 0 {
+			return echo.NewHTTPError(400, "Invalid ID format.")
+		}
+		if err := webhook.Service.Unsubscribe(c.Request().Context(), authContext.GuildID, uint(id)); err != nil {
+			if errors.Is(err, repositories.ErrWebhookNotFound) {
+				return echo.NewHTTPError(404, err.Error())
+			}
+			return err
+		}
+		return c.NoContent(204)
+	})
+	e.GET("/webhooks/:id/deliveries", func(c echo.Context) error {
+		defer handlers.Recover(logger)
+		authContext := c.(*apimw.AuthContext)
+		if err := requireAdministrator(authContext); err != nil {
+			return err
+		}
+		guildID := authContext.GuildID
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil || id == 0 {
+			return echo.NewHTTPError(400, "Invalid ID format.")
+		}
+		limit := webhook.DefaultDeliveryLimit
+		if limitStr := c.QueryParam("limit"); limitStr != "" {
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil || limit < 1 || limit > webhook.MaxDeliveryLimit {
+				return echo.NewHTTPError(400, fmt.Sprintf("limit must be between 1 and %d.", webhook.MaxDeliveryLimit))
+			}
+		}
+
+		ctx := c.Request().Context()
+		subscription, err := webhook.Service.GetSubscription(ctx, guildID, uint(id))
+		if err != nil {
+			return err
+		}
+		if subscription == nil {
+			return echo.NewHTTPError(404, repositories.ErrWebhookNotFound.Error())
+		}
+		deliveries, err := webhook.Service.GetDeliveries(ctx, guildID, subscription.ID, limit)
+		if err != nil {
+			return err
+		}
+		return c.JSON(200, deliveries)
+	})
+}
+
+// newRequireAdministrator returns a check that lets API clients through (since only administrators can create them through /developer),
+// but only lets Bearer users through if they are an administrator of the guild.
+func newRequireAdministrator(logger *zap.Logger, discordSess *discordgo.Session) func(authContext *apimw.AuthContext) error {
+	return func(authContext *apimw.AuthContext) error {
+		if authContext.UserID == "" || models.IsPersonalGuildID(authContext.GuildID) {
+			// personal grocery lists are theirs to manage
+			return nil
+		}
+		if discordSess == nil {
+			return echo.NewHTTPError(500, "Cannot verify permissions.")
+		}
+		guild, err := discordSess.Guild(authContext.GuildID)
+		if err != nil {
+			logger.Debug("Cannot look up guild.", zap.Error(err))
+			return echo.NewHTTPError(403, "Only server administrators can manage webhooks.")
+		}
+		if guild.OwnerID == authContext.UserID {
+			return nil
+		}
+		member, err := discordSess.GuildMember(authContext.GuildID, authContext.UserID)
+		if err != nil {
+			logger.Debug("Cannot look up guild member.", zap.Error(err))
+			return echo.NewHTTPError(403, "Only server administrators can manage webhooks.")
+		}
+		roles, err := discordSess.GuildRoles(authContext.GuildID)
+		if err != nil {
+			return err
+		}
+		isMemberRole := make(map[string]bool, len(member.Roles))
+		for _, roleID := range member.Roles {
+			isMemberRole[roleID] = true
+		}
+		for _, role := range roles {
+			if isMemberRole[role.ID] && role.Permissions&discordgo.PermissionAdministrator == discordgo.PermissionAdministrator {
+				return nil
+			}
+		}
+		return echo.NewHTTPError(403, "Only server administrators can manage webhooks.")
+	}
+}
@@ -1,14 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+// custom IDs for the buttons shown when a duplicate is detected - handled by the native slash handlers
+const (
+	CustomIDGroAddAnyway = "gro_add_anyway"
+	CustomIDGroAddCancel = "gro_add_cancel"
 )
 
 func (m *MessageHandlerContext) OnAdd() error {
-	guildID := m.commandContext.GuildID
 	argStr := m.commandContext.ArgStr
 	if argStr == "" {
 		return m.reply("Sorry, I need to know what you want to add to your grocery list :sweat_smile: (e.g. `!gro Chicken wings`)")
@@ -17,6 +25,60 @@ func (m *MessageHandlerContext) OnAdd() error {
 	if err != nil {
 		return m.onGetGroceryListError(err)
 	}
+	guildID := groceryList.GetGuildID(m.commandContext.GuildID)
+	itemDesc, quantity, unit := groceryutils.ParseQuantity(argStr)
+	newEntry := models.GroceryEntry{
+		ItemDesc:      itemDesc,
+		Quantity:      quantity,
+		Unit:          unit,
+		GuildID:       guildID,
+		UpdatedByID:   &m.commandContext.AuthorID,
+		GroceryListID: groceryList.GetID(),
+	}
+	categorised := []models.GroceryEntry{newEntry}
+	if err := m.groceryService.AssignCategories(context.Background(), guildID, categorised); err != nil {
+		return m.onError(err)
+	}
+	newEntry = categorised[0]
+	merged, _, unmergeable, err := m.groceryService.MergeDuplicates(context.Background(), groceryList, guildID, []models.GroceryEntry{newEntry})
+	if err != nil {
+		return m.onError(err)
+	}
+	groceryListName := groceryList.GetName()
+	if len(merged) > 0 && merged[0].Quantity != nil {
+		// same item with the same unit - just bump the quantity up
+		if rErr := m.groceryEntryRepo.UpdateAndAddToGroceryList(context.Background(), groceryList, merged, nil, guildID); rErr != nil {
+			return m.onError(rErr)
+		}
+		m.recordChanges(models.GroceryEntryChangeMerge, merged)
+		if err := m.reply(fmt.Sprintf("*%s* is already on %s - I've bumped it up to *%s*!", merged[0].ItemDesc, groceryListName, merged[0].GetDisplayText())); err != nil {
+			return m.onError(err)
+		}
+		return m.onEditUpdateGrohereWithGroceryList()
+	}
+	if len(merged) > 0 || len(unmergeable) > 0 {
+		// let the user decide whether it's really a duplicate
+		pendingKey := m.groceryService.StorePendingAdd(m.commandContext.GuildID, newEntry)
+		return m.replyWithComponents(
+			fmt.Sprintf("Looks like *%s* is already on %s. Add it anyway?", newEntry.GetDisplayText(), groceryListName),
+			[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Add anyway",
+							Style:    discordgo.PrimaryButton,
+							CustomID: CustomIDGroAddAnyway + ":" + pendingKey,
+						},
+						discordgo.Button{
+							Label:    "Never mind",
+							Style:    discordgo.SecondaryButton,
+							CustomID: CustomIDGroAddCancel + ":" + pendingKey,
+						},
+					},
+				},
+			},
+		)
+	}
 	limitOk, groceryEntryLimit, err := m.ValidateGroceryEntryLimit(guildID, 1)
 	if err != nil {
 		return m.onError(err)
@@ -24,16 +86,10 @@ func (m *MessageHandlerContext) OnAdd() error {
 	if !limitOk {
 		return m.reply(msgOverLimit(groceryEntryLimit))
 	}
+	toAdd := []models.GroceryEntry{newEntry}
 	rErr := m.groceryEntryRepo.AddToGroceryList(
 		groceryList,
-		[]models.GroceryEntry{
-			{
-				ItemDesc:    argStr,
-				GuildID:     m.commandContext.GuildID,
-				UpdatedByID: &m.commandContext.AuthorID,
-				GroceryList: groceryList,
-			},
-		},
+		toAdd,
 		guildID)
 	if rErr != nil {
 		switch rErr.ErrCode {
@@ -43,11 +99,8 @@ func (m *MessageHandlerContext) OnAdd() error {
 			return m.onError(rErr)
 		}
 	}
-	groceryListName := "your grocery list"
-	if groceryList != nil {
-		groceryListName = groceryList.GetName()
-	}
-	err = m.reply(fmt.Sprintf("Added *%s* into %s!", argStr, groceryListName))
+	m.recordChanges(models.GroceryEntryChangeAdd, toAdd)
+	err = m.reply(fmt.Sprintf("Added *%s* into %s!", newEntry.GetDisplayText(), groceryListName))
 	if err != nil {
 		return m.onError(err)
 	}
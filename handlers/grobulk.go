@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 )
 
 func (m *MessageHandlerContext) OnBulk() error {
@@ -13,6 +17,7 @@ func (m *MessageHandlerContext) OnBulk() error {
 	if err != nil {
 		return m.onGetGroceryListError(err)
 	}
+	guildID := groceryList.GetGuildID(m.commandContext.GuildID)
 	items := strings.Split(
 		strings.Trim(argStr, "\n \t"),
 		"\n",
@@ -24,16 +29,22 @@ func (m *MessageHandlerContext) OnBulk() error {
 		aID := m.commandContext.AuthorID
 		cleanedItem := strings.Trim(item, " \n\t")
 		if cleanedItem != "" {
+			itemDesc, quantity, unit := groceryutils.ParseQuantity(cleanedItem)
 			toInsert = append(toInsert, models.GroceryEntry{
-				ItemDesc:    cleanedItem,
-				GuildID:     m.commandContext.GuildID,
+				ItemDesc:    itemDesc,
+				Quantity:    quantity,
+				Unit:        unit,
+				GuildID:     guildID,
 				UpdatedByID: &aID,
 			})
 		}
 	}
 
+	if err := m.groceryService.AssignCategories(context.Background(), guildID, toInsert); err != nil {
+		return m.onError(err)
+	}
+
 	// validate the limit
-	insertedItemsCount := len(toInsert)
 	guildConfig, err := m.getConfig()
 	if err != nil {
 		return m.onError(err)
@@ -43,33 +54,67 @@ func (m *MessageHandlerContext) OnBulk() error {
 	if guildConfig != nil {
 		useGrobulkAppend = guildConfig.UseGrobulkAppend
 	}
+	mergedItemsCount := 0
+	insertedItemsCount := 0
+	var undoComponents []discordgo.MessageComponent
 	if useGrobulkAppend {
+		// duplicates of what's already on the list get merged in; the ones that can't be merged are added as-is
+		merged, toAdd, unmergeable, err := m.groceryService.MergeDuplicates(context.Background(), groceryList, guildID, toInsert)
+		if err != nil {
+			return m.onError(err)
+		}
+		toAdd = append(toAdd, unmergeable...)
+		mergedItemsCount = len(toInsert) - len(toAdd)
+		insertedItemsCount = len(toAdd)
 		if insertedItemsCount > 0 {
-			limitOk, groceryEntryLimit, err := m.ValidateGroceryEntryLimit(m.commandContext.GuildID, insertedItemsCount)
+			limitOk, groceryEntryLimit, err := m.ValidateGroceryEntryLimit(guildID, insertedItemsCount)
 			if err != nil {
 				return m.onError(err)
 			}
 			if !limitOk {
 				return m.reply(msgOverLimit(groceryEntryLimit))
 			}
-			rErr := m.groceryEntryRepo.AddToGroceryList(groceryList, toInsert, m.commandContext.GuildID)
+		}
+		if insertedItemsCount > 0 || len(merged) > 0 {
+			rErr := m.groceryEntryRepo.UpdateAndAddToGroceryList(context.Background(), groceryList, merged, toAdd, guildID)
 			if rErr != nil {
 				return m.onError(rErr)
 			}
+			m.recordChanges(models.GroceryEntryChangeMerge, merged)
+			m.recordChanges(models.GroceryEntryChangeAdd, toAdd)
 		}
 	} else {
+		// the list is replaced, so only merge the duplicates within the new items
+		_, toAdd, _ := groceryutils.MergeDuplicates(nil, toInsert)
+		insertedItemsCount = len(toAdd)
 		if insertedItemsCount > 0 {
-			limitOk, groceryEntryLimit, err := m.ValidateGroceryEntryLimitUsingTotalCount(m.commandContext.GuildID, insertedItemsCount)
+			limitOk, groceryEntryLimit, err := m.ValidateGroceryEntryLimitUsingTotalCount(guildID, insertedItemsCount)
 			if err != nil {
 				return m.onError(err)
 			}
 			if !limitOk {
 				return m.reply(msgOverLimit(groceryEntryLimit))
 			}
-			rErr := m.groceryEntryRepo.ReplaceItemsInGroceryList(groceryList, toInsert, m.commandContext.GuildID)
+			// keep a copy of the list for !groundo
+			replaced, err := m.groceryEntryRepo.FindByQueryWithConfig(
+				&models.GroceryEntry{
+					GuildID:       guildID,
+					GroceryListID: groceryList.GetID(),
+				},
+				repositories.GroceryEntryQueryOpts{
+					IsStrongNilForGroceryListID: true,
+				},
+			)
+			if err != nil {
+				return m.onError(err)
+			}
+			rErr := m.groceryEntryRepo.ReplaceItemsInGroceryList(groceryList, toAdd, guildID)
 			if rErr != nil {
 				return m.onError(rErr)
 			}
+			undoComponents = m.recordUndo(models.UndoOperationReplace, groceryList, &models.UndoSnapshot{GroceryEntries: replaced})
+			m.recordChanges(models.GroceryEntryChangeRemove, replaced)
+			m.recordChanges(models.GroceryEntryChangeAdd, toAdd)
 		}
 	}
 
@@ -79,10 +124,13 @@ func (m *MessageHandlerContext) OnBulk() error {
 		listLabel = groceryList.GetName()
 	}
 	message := fmt.Sprintf("Added %d items into %s!", insertedItemsCount, listLabel)
+	if mergedItemsCount > 0 {
+		message = fmt.Sprintf("Added %d items into %s, and merged %d items into what's already on the list!", insertedItemsCount, listLabel, mergedItemsCount)
+	}
 	if !useGrobulkAppend {
 		message = fmt.Sprintf("I've updated %s - it now has %d items!", listLabel, insertedItemsCount)
 	}
-	if err := m.reply(message); err != nil {
+	if err := m.replyWithComponents(message, undoComponents); err != nil {
 		return m.onError(err)
 	}
 	return m.onEditUpdateGrohereWithGroceryList()
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+)
+
+const groCheckSweepArg = "sweep"
+
+func (m *MessageHandlerContext) OnCheck() error {
+	argStr := strings.TrimSpace(m.commandContext.ArgStr)
+	if argStr == "" {
+		return m.reply("Oops, I can't seem to understand you. Perhaps try typing **!grocheck 1** to check off item #1, or **!grocheck sweep** to remove everything you've checked off?")
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	if strings.ToLower(argStr) == groCheckSweepArg {
+		return m.onCheckSweep(groceryList)
+	}
+	args := strings.Split(argStr, " ")
+	groceries, err := m.groceryEntryRepo.FindByQueryWithConfig(
+		&models.GroceryEntry{
+			GuildID:       groceryList.GetGuildID(m.commandContext.GuildID),
+			GroceryListID: groceryList.GetID(),
+		},
+		repositories.GroceryEntryQueryOpts{
+			IsStrongNilForGroceryListID: true,
+		},
+	)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(groceries) == 0 {
+		return m.reply(fmt.Sprintf("Whoops, you do not have any items in %s.", groceryList.GetName()))
+	}
+	var getItemsToCheckFunc getItemsToRemoveFuncType
+	if _, err := strconv.Atoi(args[0]); err == nil {
+		getItemsToCheckFunc = getItemsToRemoveWithIndex
+	} else {
+		getItemsToCheckFunc = getItemsToRemoveWithName
+	}
+	toToggle, err := getItemsToCheckFunc(args, groceries, groceryList)
+	if err != nil {
+		return m.reply(err.Error())
+	}
+	// checking an already-checked item un-checks it, so that mis-taps can be reverted
+	now := time.Now()
+	checked := make([]models.GroceryEntry, 0, len(toToggle))
+	unchecked := make([]models.GroceryEntry, 0, len(toToggle))
+	seen := make(map[uint]struct{}, len(toToggle))
+	for i := range toToggle {
+		g := &toToggle[i]
+		// !grocheck 1 1 should not check & uncheck the same item
+		if _, ok := seen[g.ID]; ok {
+			continue
+		}
+		seen[g.ID] = struct{}{}
+		if g.IsChecked() {
+			g.CheckedAt = nil
+			g.CheckedByID = nil
+			unchecked = append(unchecked, *g)
+		} else {
+			g.CheckedAt = &now
+			g.CheckedByID = &m.commandContext.AuthorID
+			checked = append(checked, *g)
+		}
+		g.UpdatedByID = &m.commandContext.AuthorID
+		if err := m.groceryEntryRepo.Put(g); err != nil {
+			return m.onError(err)
+		}
+	}
+	m.recordChanges(models.GroceryEntryChangeCheck, checked)
+	m.recordChanges(models.GroceryEntryChangeUncheck, unchecked)
+	replyTokens := make([]string, 0, 2)
+	if len(checked) > 0 {
+		replyTokens = append(replyTokens, fmt.Sprintf("Checked off %s on %s!", prettyItems(checked), groceryList.GetName()))
+	}
+	if len(unchecked) > 0 {
+		replyTokens = append(replyTokens, fmt.Sprintf("Unchecked %s on %s.", prettyItems(unchecked), groceryList.GetName()))
+	}
+	if err := m.reply(strings.Join(replyTokens, "\n")); err != nil {
+		return m.onError(err)
+	}
+	return m.onEditUpdateGrohereWithGroceryList()
+}
+
+func (m *MessageHandlerContext) onCheckSweep(groceryList *models.GroceryList) error {
+	swept, err := m.groceryEntryRepo.DeleteCheckedInGroceryList(context.Background(), groceryList, groceryList.GetGuildID(m.commandContext.GuildID))
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(swept) == 0 {
+		return m.reply(fmt.Sprintf("There's nothing checked off on %s - nothing to sweep!", groceryList.GetName()))
+	}
+	m.recordChanges(models.GroceryEntryChangeRemove, swept)
+	undoComponents := m.recordUndo(models.UndoOperationSweep, groceryList, &models.UndoSnapshot{GroceryEntries: swept})
+	if err := m.replyWithComponents(fmt.Sprintf("Swept %d checked-off item(s) off %s: %s", len(swept), groceryList.GetName(), prettyItems(swept)), undoComponents); err != nil {
+		return m.onError(err)
+	}
+	return m.onEditUpdateGrohereWithGroceryList()
+}
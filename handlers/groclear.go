@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"fmt"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
 )
 
 func (m *MessageHandlerContext) OnClear() error {
@@ -9,12 +12,27 @@ func (m *MessageHandlerContext) OnClear() error {
 	if err != nil {
 		return m.onGetGroceryListError(err)
 	}
-	rowsAffected, rErr := m.groceryEntryRepo.ClearGroceryList(groceryList, m.commandContext.GuildID)
+	// keep a copy for !groundo
+	cleared, err := m.groceryEntryRepo.FindByQueryWithConfig(
+		&models.GroceryEntry{
+			GuildID:       groceryList.GetGuildID(m.commandContext.GuildID),
+			GroceryListID: groceryList.GetID(),
+		},
+		repositories.GroceryEntryQueryOpts{
+			IsStrongNilForGroceryListID: true,
+		},
+	)
+	if err != nil {
+		return m.onError(err)
+	}
+	rowsAffected, rErr := m.groceryEntryRepo.ClearGroceryList(groceryList, groceryList.GetGuildID(m.commandContext.GuildID))
 	if rErr != nil {
 		return m.onError(rErr)
 	}
+	m.recordChanges(models.GroceryEntryChangeRemove, cleared)
+	undoComponents := m.recordUndo(models.UndoOperationClear, groceryList, &models.UndoSnapshot{GroceryEntries: cleared})
 	msg := fmt.Sprintf("Deleted %d items off your grocery list!", rowsAffected)
-	if err := m.reply(msg); err != nil {
+	if err := m.replyWithComponents(msg, undoComponents); err != nil {
 		return m.onError(err)
 	}
 	return m.onEditUpdateGrohereWithGroceryList()
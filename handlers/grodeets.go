@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/andanhm/go-prettytime"
 	"github.com/verzac/grocer-discord-bot/models"
 )
 
@@ -22,7 +24,7 @@ func (m *MessageHandlerContext) OnDetail() error {
 	}
 	g, err := m.groceryEntryRepo.GetByItemIndex(
 		&models.GroceryEntry{
-			GuildID:       m.commandContext.GuildID,
+			GuildID:       groceryList.GetGuildID(m.commandContext.GuildID),
 			GroceryListID: groceryListID,
 		},
 		itemIndex,
@@ -30,5 +32,26 @@ func (m *MessageHandlerContext) OnDetail() error {
 	if err != nil {
 		return m.onError(err)
 	}
-	return m.sendMessage(fmt.Sprintf("Here's what you have for item #%d: %s (%s)", itemIndex, g.ItemDesc, g.GetUpdatedByString()))
+	if g == nil {
+		return m.onItemNotFound(itemIndex)
+	}
+	changes, err := m.historyService.GetGroceryEntryHistory(m.ctx, g.GuildID, g.ID)
+	if err != nil {
+		return m.onError(err)
+	}
+	details := make([]string, 0, 4)
+	if len(changes) > 0 && changes[0].Action == models.GroceryEntryChangeAdd && changes[0].ChangedByID != nil {
+		details = append(details, fmt.Sprintf("added by <@%s> %s", *changes[0].ChangedByID, prettytime.Format(changes[0].CreatedAt)))
+	}
+	if updatedBy := g.GetUpdatedByString(); updatedBy != "" {
+		details = append(details, updatedBy)
+	}
+	if g.IsChecked() {
+		details = append(details, g.GetCheckedByString())
+	}
+	if category := g.GetCategory(); category != "" {
+		details = append(details, "in "+category)
+	}
+	detail := strings.Join(details, ", ")
+	return m.sendMessage(fmt.Sprintf("Here's what you have for item #%d: %s (%s)", itemIndex, g.GetDisplayText(), detail))
 }
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/verzac/grocer-discord-bot/models"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 )
 
 func (m *MessageHandlerContext) OnEdit() error {
@@ -22,7 +24,7 @@ func (m *MessageHandlerContext) OnEdit() error {
 		return m.onGetGroceryListError(err)
 	}
 	newItemDesc := argTokens[1]
-	guildID := m.commandContext.GuildID
+	guildID := groceryList.GetGuildID(m.commandContext.GuildID)
 	var groceryListID *uint
 	if groceryList != nil {
 		groceryListID = &groceryList.ID
@@ -40,13 +42,23 @@ func (m *MessageHandlerContext) OnEdit() error {
 	if g == nil {
 		return m.onItemNotFound(itemIndex)
 	}
-	g.ItemDesc = newItemDesc
+	before := *g
+	// the new entry replaces the quantity too, so that "!groedit 1 Milk 2L" behaves like "!gro Milk 2L"
+	g.ItemDesc, g.Quantity, g.Unit = groceryutils.ParseQuantity(newItemDesc)
+	// the item may have changed entirely, so re-categorise it
+	g.Category = nil
+	edited := []models.GroceryEntry{*g}
+	if err := m.groceryService.AssignCategories(context.Background(), guildID, edited); err != nil {
+		return m.onError(err)
+	}
+	g.Category = edited[0].Category
 	g.UpdatedByID = &m.commandContext.AuthorID
 	if err := m.groceryEntryRepo.Put(g); err != nil {
 		m.LogError(err)
 		return m.reply("Welp, something went wrong while saving. Please try again :)")
 	}
-	if err := m.reply(fmt.Sprintf("Updated item #%d on %s to *%s*", itemIndex, groceryList.GetName(), g.ItemDesc)); err != nil {
+	m.groceryService.OnGroceryEntriesChanged(m.ctx, []models.GroceryEntryChange{models.NewGroceryEntryChange(models.GroceryEntryChangeEdit, &before, g, m.commandContext.AuthorID)})
+	if err := m.reply(fmt.Sprintf("Updated item #%d on %s to *%s*", itemIndex, groceryList.GetName(), g.GetDisplayText())); err != nil {
 		return m.onError(err)
 	}
 	return m.onEditUpdateGrohereWithGroceryList()
@@ -66,7 +66,7 @@ var (
 			},
 			{
 				Name:  "!gro <name>",
-				Value: "Adds an item to your grocery list.\nExample: `!gro Chicken katsu` - adds chicken katsu to your grocery list.",
+				Value: "Adds an item to your grocery list. Quantities (e.g. `2x`, `500g`, `1.5 L`) are picked up automatically.\nExample: `!gro Chicken katsu` - adds chicken katsu to your grocery list.",
 			},
 			{
 				Name:  "!groremove <n> <m> <o>...",
@@ -76,9 +76,17 @@ var (
 				Name:  "!groremove <item name>",
 				Value: "Removes an item which contains <item name> from your grocery list. The item name is case-insensitive. This will delete the first item on your list that contains <new item>.\nExample: `!groremove katsu` - removes \"Chicken katsu\"",
 			},
+			{
+				Name:  "!grocheck <n> <m>... / !grocheck <item name>",
+				Value: "Checks off items you've bought (they'll show up with a strikethrough). Checking an item again un-checks it.\nExample: `!grocheck 1 2` - checks off item #1 and #2.",
+			},
+			{
+				Name:  "!grocheck sweep",
+				Value: "Removes every checked-off item from your grocery list.",
+			},
 			{
 				Name:  "!grolist",
-				Value: "List all the groceries in your grocery list.",
+				Value: "List all the groceries in your grocery list, grouped by aisle (e.g. Produce, Dairy & Eggs). Use `/config category-set` to choose the category of an item yourself.",
 			},
 			{
 				Name:   "!grohere",
@@ -98,6 +106,30 @@ var (
 				Name:  "!groedit <n> <new name>",
 				Value: "Updates item #n to a new name/entry.\nExample: `!groedit 1 Katsudon` - edits item #1 to have the entry Katsudon.",
 			},
+			{
+				Name:  "!grohistory <n>",
+				Value: "Shows the latest n changes to your grocery list (who added, edited, checked off or removed what).\nExample: `!grohistory 5` - shows the last 5 changes.",
+			},
+			{
+				Name:  "!grostaple add <item> every <day> / every <n> days / when checked",
+				Value: "Puts an item back on your grocery list by itself - on a day of the week, every few days, or whenever it's checked off. `!grostaple` lists them.\nExample: `!grostaple add Milk every saturday` - adds Milk every Saturday.",
+			},
+			{
+				Name:  "!groremind add every <days> <time> [in #channel] [dm @users]",
+				Value: "Posts your grocery list on a schedule, in your server's timezone (see `/config set timezone`). `!groremind` lists them.\nExample: `!groremind add every saturday 9am` - posts it here every Saturday at 9am.",
+			},
+			{
+				Name:  "!grotemplate save <name> / apply <name>",
+				Value: "Saves what's on your grocery list as a template, and puts its items back on later (skipping what's already on there). `!grotemplate` lists them.\nExample: `!grotemplate apply weekly shop` - restocks your list from the *weekly shop* template.",
+			},
+			{
+				Name:  "!grorecipe add <name> [servings]",
+				Value: "Adds the ingredients of a recipe from your recipe book (save them from `/ingredients`) to your grocery list, scaled to the number of servings. `!grorecipe` lists them.\nExample: `!grorecipe add lasagne 8` - adds what you need for lasagne for 8.",
+			},
+			{
+				Name:  "!groundo",
+				Value: "Brings back what the last `!groclear`, `!groremove`, `!grobulk`, `!grocheck sweep` or `!groreset` deleted (within 24 hours).",
+			},
 			{
 				Name:  "!groreset",
 				Value: "When you want to clear all of your data from this bot. See our privacy policy at https://grocerybot.net/privacy-policy",
@@ -33,6 +33,9 @@ func (m *MessageHandlerContext) onAttachList() error {
 	if err != nil {
 		return m.onGetGroceryListError(err)
 	}
+	if !groceryList.IsPublic() {
+		return m.reply(fmt.Sprintf("Sorry, **%s** is private, so I can't attach it to a channel that everyone can see. Use `!grolist:%s visibility public` to make it public first.", groceryList.GetName(), groceryList.ListLabel))
+	}
 	if err := m.reply(fmt.Sprintf("Gotcha! Attaching a self-updating grocery list for **%s** to the current channel. Please stand by...", groceryList.GetName())); err != nil {
 		return m.onError(err)
 	}
@@ -52,6 +55,7 @@ func (m *MessageHandlerContext) onAttachList() error {
 		if err != nil {
 			m.LogError(errCannotEditMsgWhenReplacingGrohere, zap.Any("DiscordErr", err))
 		}
+		m.deleteGrohereExtraMessages(record.GrohereChannelID, record.ExtraMessageIDs)
 	}
 	if len(grohereRecords) > 0 {
 		if r := m.db.Delete(grohereRecords); r.Error != nil {
@@ -87,6 +91,11 @@ func (m *MessageHandlerContext) onAttachAll() error {
 	if err := m.reply("Gotcha! Attaching a self-updating grocery list to the current channel. Please stand by..."); err != nil {
 		return m.onError(err)
 	}
+	if oldConfig, err := m.getConfig(); err != nil {
+		m.LogError(err)
+	} else if oldConfig != nil && oldConfig.GrohereChannelID != nil {
+		m.deleteGrohereExtraMessages(*oldConfig.GrohereChannelID, oldConfig.GrohereExtraMessageIDs)
+	}
 	attachMsg, err := m.sess.ChannelMessageSend(m.commandContext.ChannelID, "Placeholder")
 	if err != nil {
 		return m.onError(err)
@@ -136,8 +145,18 @@ func (m *MessageHandlerContext) onListRemoveGrohereRecord(groceryList *models.Gr
 	if err != nil {
 		m.LogError(errCannotEditMsgWhenRemovingGrolist, zap.String("DiscordErr", err.Error()))
 	}
+	m.deleteGrohereExtraMessages(record.GrohereChannelID, record.ExtraMessageIDs)
 	if r := m.db.Delete(record); r.Error != nil {
 		return m.onError(r.Error)
 	}
 	return nil
 }
+
+// deleteGrohereExtraMessages cleans up the messages that a long !grohere has been split into.
+func (m *MessageHandlerContext) deleteGrohereExtraMessages(channelID string, extraMessageIDs string) {
+	for _, messageID := range models.SplitMessageIDs(extraMessageIDs) {
+		if err := m.sess.ChannelMessageDelete(channelID, messageID); err != nil {
+			m.GetLogger().Info("Cannot delete extra !grohere message.", zap.String("MessageID", messageID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/services/history"
+)
+
+// keeps !grohistory under Discord's message length limit
+const maxGroHistoryCount = 25
+
+func (m *MessageHandlerContext) OnHistory() error {
+	count := history.DefaultHistoryLimit
+	if argStr := strings.TrimSpace(m.commandContext.ArgStr); argStr != "" {
+		n, err := strconv.Atoi(argStr)
+		if err != nil || n < 1 {
+			return m.reply(fmt.Sprintf("Oops, I can't seem to understand how many changes you'd like to see. Try something like `!grohistory 5` (max %d).", maxGroHistoryCount))
+		}
+		count = n
+	}
+	if count > maxGroHistoryCount {
+		count = maxGroHistoryCount
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	changes, err := m.historyService.GetGroceryListHistory(m.ctx, groceryList.GetGuildID(m.commandContext.GuildID), groceryList, count)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(changes) == 0 {
+		return m.reply(fmt.Sprintf("Nothing has happened on %s yet - add something with `!gro`!", groceryList.GetName()))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Here's what's been happening on %s (latest first):\n", groceryList.GetName()))
+	for _, c := range changes {
+		sb.WriteString(fmt.Sprintf("- %s\n", c.GetSummary()))
+	}
+	return m.reply(strings.TrimSpace(sb.String()))
+}
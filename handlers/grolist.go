@@ -5,15 +5,23 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
+
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/utils"
 	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 )
 
 const (
 	msgCannotSaveNewGroceryList = "Whoops, can't seem to save your new grocery list. Please try again later!"
-	msgCmdNotFound              = ":thinking: Hmm... Not sure what you're looking for. Here are my available commands:\n`!grolist`\n`!grolist new <new list's label> <new list's fancy name - optional>`\n`!grolist:<label> delete`\n`!grolist:<label> edit-name <new fancy name>`\n`!grolist:<label> edit-label <new label>`"
+	msgCmdNotFound              = ":thinking: Hmm... Not sure what you're looking for. Here are my available commands:\n`!grolist`\n`!grolist new <new list's label> <new list's fancy name - optional>`\n`!grolist:<label> delete`\n`!grolist:<label> edit-name <new fancy name>`\n`!grolist:<label> edit-label <new label>`\n`!grolist:<label> visibility <public|roles|users> <@mentions - optional>`\n`!grolist:<label> share`\n`!grolist join <invite code>`"
 	msgPrefixDefault            = "Here's your grocery list:"
+	// handled by the native slash handlers
+	CustomIDGrolistPage = "grolist_page"
+	// leaves room for the announcements and notices that get appended to replies
+	grolistPageMaxLength = 1400
 )
 
 func (m *MessageHandlerContext) OnList() error {
@@ -32,6 +40,15 @@ func (m *MessageHandlerContext) OnList() error {
 	if strings.HasPrefix(m.commandContext.ArgStr, "edit-label ") {
 		return m.relabelList()
 	}
+	if strings.HasPrefix(m.commandContext.ArgStr, "visibility") {
+		return m.setListVisibility()
+	}
+	if m.commandContext.ArgStr == "share" {
+		return m.shareList()
+	}
+	if strings.HasPrefix(m.commandContext.ArgStr, "join ") {
+		return m.joinList()
+	}
 	if m.commandContext.ArgStr == "all" {
 		return m.displayListAll()
 	}
@@ -40,15 +57,11 @@ func (m *MessageHandlerContext) OnList() error {
 
 func (m *MessageHandlerContext) displayListAll() error {
 	msgPrefix := msgPrefixDefault
-	groceries, err := m.groceryEntryRepo.FindByQuery(
-		&models.GroceryEntry{
-			GuildID: m.commandContext.GuildID,
-		},
-	)
+	groceryLists, groceries, err := m.groceryService.FindGuildGroceryLists(m.ctx, m.commandContext.GuildID)
 	if err != nil {
 		return m.onError(err)
 	}
-	groceryLists, err := m.groceryListRepo.FindByQuery(&models.GroceryList{GuildID: m.commandContext.GuildID})
+	groceryLists, groceries, err = m.groceryService.FilterVisibleGroceryLists(m.ctx, m.getMemberContext(), groceryLists, groceries)
 	if err != nil {
 		return m.onError(err)
 	}
@@ -64,7 +77,7 @@ func (m *MessageHandlerContext) displayListAll() error {
 			textComponents = append(textComponents, strings.TrimRight(textComponent, "\n"))
 		}
 	}
-	return m.reply(strings.Join(textComponents, "\n"))
+	return m.replyPaginated(strings.Join(textComponents, "\n"))
 }
 
 func (m *MessageHandlerContext) displayList() error {
@@ -75,7 +88,7 @@ func (m *MessageHandlerContext) displayList() error {
 	}
 	groceries, err := m.groceryEntryRepo.FindByQueryWithConfig(
 		&models.GroceryEntry{
-			GuildID:       m.commandContext.GuildID,
+			GuildID:       groceryList.GetGuildID(m.commandContext.GuildID),
 			GroceryListID: groceryList.GetID(),
 		},
 		repositories.GroceryEntryQueryOpts{
@@ -115,7 +128,69 @@ func (m *MessageHandlerContext) displayList() error {
 			textComponents = append(textComponents, strings.TrimRight(textComponent, "\n"))
 		}
 	}
-	return m.reply(strings.Join(textComponents, "\n"))
+	msg := strings.Join(textComponents, "\n")
+	// only for a single list - "!grolist all" numbers each list from 1, so a number emoji wouldn't say which entry it's for
+	if len(groceries) > 0 && len(groceryutils.PaginateText(msg, grolistPageMaxLength)) == 1 {
+		config, err := m.getConfig()
+		if err != nil {
+			// not fatal - just reply without the reactions
+			m.LogError(err)
+		} else if config != nil && config.UseGrolistReactions {
+			return m.replyWithGrolistReactions(msg, groceries)
+		}
+	}
+	return m.replyPaginated(msg)
+}
+
+// replyWithGrolistReactions replies with msg, then reacts to the reply with a number emoji for each of the groceries so that they can be checked off.
+func (m *MessageHandlerContext) replyWithGrolistReactions(msg string, groceries []models.GroceryEntry) error {
+	sent, err := m.replyAndGetMessage(msg)
+	if err != nil {
+		return err
+	}
+	if sent == nil {
+		// ephemeral replies can't be reacted to
+		return nil
+	}
+	if err := m.groceryService.AddGrolistReactions(m.ctx, m.commandContext.GuildID, sent, groceries); err != nil {
+		// the list has been shown anyway
+		m.LogError(err)
+	}
+	return nil
+}
+
+// replyPaginated replies with msg, splitting it into pages that can be flicked through if it's too long for one message.
+func (m *MessageHandlerContext) replyPaginated(msg string) error {
+	pages := groceryutils.PaginateText(msg, grolistPageMaxLength)
+	if len(pages) == 1 {
+		return m.reply(msg)
+	}
+	key := m.groceryService.StoreGrolistPages(m.commandContext.GuildID, pages)
+	content, components := GetGrolistPage(key, pages, 0)
+	return m.replyWithComponents(content, components)
+}
+
+// GetGrolistPage renders a page of a long !grolist, along with the buttons to go to the previous/next page.
+func GetGrolistPage(key string, pages []string, page int) (string, []discordgo.MessageComponent) {
+	content := fmt.Sprintf("%s\n\n*Page %d of %d*", pages[page], page+1, len(pages))
+	return content, []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Prev",
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("%s:%s:%d", CustomIDGrolistPage, key, max(page-1, 0)),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("%s:%s:%d", CustomIDGrolistPage, key, min(page+1, len(pages)-1)),
+					Disabled: page == len(pages)-1,
+				},
+			},
+		},
+	}
 }
 
 func (m *MessageHandlerContext) getDisplayListText(groceryLists []models.GroceryList, groceries []models.GroceryEntry) string {
@@ -152,6 +227,13 @@ func (m *MessageHandlerContext) newList() error {
 			groceryListLimit,
 		))
 	}
+	sharedList, err := m.groceryService.GetGuildGroceryList(m.ctx, m.commandContext.GuildID, &models.GroceryList{ListLabel: label})
+	if err != nil {
+		return m.onError(err)
+	}
+	if m.isSharedGroceryList(sharedList) {
+		return m.reply(fmt.Sprintf("Sorry, a grocery list with the label **%s** has been shared with your server already. Please select another label :)", label))
+	}
 	newGroceryList, err := m.groceryListRepo.CreateGroceryList(m.commandContext.GuildID, label, fancyName)
 	if err != nil {
 		switch err {
@@ -162,12 +244,34 @@ func (m *MessageHandlerContext) newList() error {
 			return m.reply(msgCannotSaveNewGroceryList)
 		}
 	}
+	m.groceryService.OnGroceryListCreated(m.ctx, newGroceryList)
 	if err := m.reply(fmt.Sprintf("Yay! Your new grocery list **%s** has been successfully created. Use it in a command like so to add entries to your grocery list: `!gro:%s Chicken`", newGroceryList.GetName(), newGroceryList.ListLabel)); err != nil {
 		return m.onError(err)
 	}
 	return m.onEditUpdateGrohere()
 }
 
+// hideInvisibleGroceryList returns nil if the author cannot see the grocery list, so that it's treated as if it doesn't exist.
+func (m *MessageHandlerContext) hideInvisibleGroceryList(groceryList *models.GroceryList) (*models.GroceryList, error) {
+	if groceryList == nil {
+		return nil, nil
+	}
+	canSee, err := m.groceryService.CanSeeGroceryList(m.ctx, m.getMemberContext(), groceryList)
+	if err != nil || !canSee {
+		return nil, err
+	}
+	return groceryList, nil
+}
+
+// isSharedGroceryList returns true if the grocery list belongs to another guild that has shared it with this one (see !grolist join).
+func (m *MessageHandlerContext) isSharedGroceryList(groceryList *models.GroceryList) bool {
+	return groceryList != nil && groceryList.GuildID != m.commandContext.GuildID
+}
+
+func fmtErrSharedGroceryListNotEditable(groceryList *models.GroceryList) string {
+	return fmt.Sprintf("Sorry, **%s** has been shared with this server by another server, so only that server can change it.", groceryList.GetName())
+}
+
 func fmtErrGroceryListNotFound(label string) string {
 	return fmt.Sprintf("Whoops, I cannot seem to find a grocery list with the label **%s**... Could you please try again?", label)
 }
@@ -177,13 +281,27 @@ func (m *MessageHandlerContext) deleteList() error {
 	if label == "" {
 		return m.reply(msgCmdNotFound)
 	}
-	groceryList, err := m.groceryListRepo.GetByQuery(&models.GroceryList{ListLabel: label})
+	groceryList, err := m.groceryService.GetGuildGroceryList(m.ctx, m.commandContext.GuildID, &models.GroceryList{ListLabel: label})
+	if err != nil {
+		return m.onError(err)
+	}
+	groceryList, err = m.hideInvisibleGroceryList(groceryList)
 	if err != nil {
 		return m.onError(err)
 	}
 	if groceryList == nil {
 		return m.reply(fmtErrGroceryListNotFound(label))
 	}
+	if m.isSharedGroceryList(groceryList) {
+		// the list itself is the other server's to delete
+		if err := m.groceryService.LeaveGroceryList(m.ctx, m.commandContext.GuildID, groceryList); err != nil {
+			return m.onError(err)
+		}
+		if err := m.reply(fmt.Sprintf("Done! **%s** is no longer shared with this server - it's still around in the server that shared it.", groceryList.GetName())); err != nil {
+			return m.onError(err)
+		}
+		return m.onEditUpdateGrohere()
+	}
 	count, rErr := m.groceryEntryRepo.GetCount(&models.GroceryEntry{GuildID: m.commandContext.GuildID, GroceryListID: &groceryList.ID})
 	if rErr != nil {
 		return m.onError(rErr)
@@ -202,6 +320,7 @@ func (m *MessageHandlerContext) deleteList() error {
 			return m.onError(err)
 		}
 	}
+	m.groceryService.OnGroceryListDeleted(m.ctx, groceryList)
 	if err := m.reply(fmt.Sprintf("Successfully deleted **%s**! Feel free to make new ones with `!grolist new`!", label)); err != nil {
 		return m.onError(err)
 	}
@@ -218,13 +337,20 @@ func (m *MessageHandlerContext) editList() error {
 		return m.reply("Sorry, I need to know what you'd like to rename your grocery as. For example: `!grolist:amazon edit-name My Amazon Shopping List` to change a grocery list with the label `amazon` to have the name \"My Amazon Shopping List\". Changing the labels themselves are done through edit-label like so: `!grolist edit-label amazon ebay`.")
 	}
 	newFancyName := splitArgs[1]
-	groceryList, err := m.groceryListRepo.GetByQuery(&models.GroceryList{ListLabel: label})
+	groceryList, err := m.groceryService.GetGuildGroceryList(m.ctx, m.commandContext.GuildID, &models.GroceryList{ListLabel: label})
+	if err != nil {
+		return m.onError(err)
+	}
+	groceryList, err = m.hideInvisibleGroceryList(groceryList)
 	if err != nil {
 		return m.onError(err)
 	}
 	if groceryList == nil {
 		return m.reply(fmt.Sprintf("Whoops, can't seem to find a grocery list with the label **%s**. You can make the grocery list by typing `!grolist new %s %s`.", label, label, newFancyName))
 	}
+	if m.isSharedGroceryList(groceryList) {
+		return m.reply(fmtErrSharedGroceryListNotEditable(groceryList))
+	}
 	groceryList.FancyName = &newFancyName
 	if err := m.groceryListRepo.Save(groceryList); err != nil {
 		return m.onError(err)
@@ -245,13 +371,20 @@ func (m *MessageHandlerContext) relabelList() error {
 		return m.reply("Sorry, I need to know what you'd like to relabel your grocery list as. For example: `!grolist:amazon edit-label ebay` to change your `ebay` list's label to `amazon instead` (all items will be moved to the new list).")
 	}
 	newLabel := splitArgs[1]
-	groceryList, err := m.groceryListRepo.GetByQuery(&models.GroceryList{ListLabel: label})
+	groceryList, err := m.groceryService.GetGuildGroceryList(m.ctx, m.commandContext.GuildID, &models.GroceryList{ListLabel: label})
+	if err != nil {
+		return m.onError(err)
+	}
+	groceryList, err = m.hideInvisibleGroceryList(groceryList)
 	if err != nil {
 		return m.onError(err)
 	}
 	if groceryList == nil {
 		return m.reply(fmt.Sprintf("Whoops, can't seem to find a grocery list with the label **%s**. You can make the grocery list by typing `!grolist new %s My Shopping List`.", label, newLabel))
 	}
+	if m.isSharedGroceryList(groceryList) {
+		return m.reply(fmtErrSharedGroceryListNotEditable(groceryList))
+	}
 	groceryList.ListLabel = newLabel
 	if err := m.groceryListRepo.Save(groceryList); err != nil {
 		return m.onError(err)
@@ -268,3 +401,144 @@ func (m *MessageHandlerContext) relabelList() error {
 	m.commandContext.GrocerySublist = newLabel
 	return m.onEditUpdateGrohereWithGroceryList()
 }
+
+const msgListVisibilityUsage = "Sorry, I need to know who should be able to see the grocery list. For example:\n`!grolist:pharmacy visibility users @Alice @Bob` - only you, Alice and Bob\n`!grolist:pharmacy visibility roles @Housemates` - only you and members with the Housemates role\n`!grolist:pharmacy visibility public` - everyone"
+
+// setListVisibility restricts who can see a grocery list. The first person to make the list non-public becomes its owner, and only they can change its visibility from then on.
+func (m *MessageHandlerContext) setListVisibility() error {
+	if m.commandContext.GrocerySublist == "" {
+		return m.reply(msgCmdNotFound)
+	}
+	if models.IsPersonalGuildID(m.commandContext.GuildID) {
+		return m.reply("Your personal grocery lists can only be seen by you already!")
+	}
+	splitArgs := strings.SplitN(m.commandContext.ArgStr, " ", 3)
+	if len(splitArgs) < 2 {
+		return m.reply(msgListVisibilityUsage)
+	}
+	visibility := strings.ToLower(splitArgs[1])
+	who := ""
+	if len(splitArgs) >= 3 {
+		who = splitArgs[2]
+	}
+	userIDs, roleIDs, unrecognised := utils.ParseMentions(who)
+	if len(unrecognised) > 0 {
+		return m.reply(fmt.Sprintf("Sorry, I can only make grocery lists visible to @mentioned users or roles, and I don't recognise: %s", strings.Join(unrecognised, " ")))
+	}
+	var allowedIDs []string
+	switch visibility {
+	case models.GroceryListVisibilityPublic:
+		if len(userIDs)+len(roleIDs) > 0 {
+			return m.reply(msgListVisibilityUsage)
+		}
+	case models.GroceryListVisibilityRoles:
+		if len(roleIDs) == 0 || len(userIDs) > 0 {
+			return m.reply(msgListVisibilityUsage)
+		}
+		allowedIDs = roleIDs
+	case models.GroceryListVisibilityUsers:
+		if len(roleIDs) > 0 {
+			return m.reply(msgListVisibilityUsage)
+		}
+		allowedIDs = userIDs
+	default:
+		return m.reply(msgListVisibilityUsage)
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	if m.isSharedGroceryList(groceryList) {
+		return m.reply(fmtErrSharedGroceryListNotEditable(groceryList))
+	}
+	authorID := m.commandContext.AuthorID
+	if groceryList.OwnerID != nil && *groceryList.OwnerID != authorID {
+		return m.reply(fmt.Sprintf("Sorry, only the owner of **%s** (<@%s>) can change who can see it.", groceryList.GetName(), *groceryList.OwnerID))
+	}
+	if groceryList.OwnerID == nil && visibility != models.GroceryListVisibilityPublic {
+		groceryList.OwnerID = &authorID
+	}
+	groceryList.Visibility = visibility
+	groceryList.SetAllowedIDs(allowedIDs)
+	if err := m.groceryListRepo.Save(groceryList); err != nil {
+		return m.onError(err)
+	}
+	if err := m.reply(fmt.Sprintf("Done! **%s** can now be seen by %s.", groceryList.GetName(), fmtGroceryListAudience(groceryList))); err != nil {
+		return m.onError(err)
+	}
+	return m.onEditUpdateGrohereWithGroceryList()
+}
+
+func fmtGroceryListAudience(groceryList *models.GroceryList) string {
+	if groceryList.IsPublic() {
+		return "everyone"
+	}
+	mentions := make([]string, 0)
+	if groceryList.OwnerID != nil {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", *groceryList.OwnerID))
+	}
+	for _, id := range groceryList.GetAllowedIDs() {
+		switch groceryList.Visibility {
+		case models.GroceryListVisibilityRoles:
+			mentions = append(mentions, fmt.Sprintf("members of <@&%s>", id))
+		default:
+			if groceryList.OwnerID == nil || id != *groceryList.OwnerID {
+				mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+			}
+		}
+	}
+	if len(mentions) == 0 {
+		return "no one"
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// shareList creates an invite code that another server can use to join the grocery list through !grolist join.
+func (m *MessageHandlerContext) shareList() error {
+	if m.commandContext.GrocerySublist == "" {
+		return m.reply("Sorry, I need to know which grocery list you'd like to share. For example: `!grolist:amazon share`.")
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	if m.isSharedGroceryList(groceryList) {
+		return m.reply(fmt.Sprintf("Sorry, **%s** has been shared with this server by another server, so only that server can share it.", groceryList.GetName()))
+	}
+	invite, err := m.groceryService.ShareGroceryList(m.ctx, groceryList, m.commandContext.AuthorID)
+	switch err {
+	case nil:
+	case grocery.ErrGroceryListNotShareable, grocery.ErrPersonalGroceryListSharing:
+		return m.reply(err.Error())
+	default:
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf(
+		"Here's an invite code for **%s**: `%s`\nUse `/grolist-join` (or `!grolist join %s`) in the other server within the next 24 hours to add the list there - both servers will then be able to see and edit it. The code can only be used once.",
+		groceryList.GetName(),
+		invite.Code,
+		invite.Code,
+	))
+}
+
+// joinList adds another server's grocery list to this server using an invite code from !grolist share.
+func (m *MessageHandlerContext) joinList() error {
+	if m.commandContext.GrocerySublist != "" {
+		return m.reply(msgCmdNotFound)
+	}
+	code := strings.TrimSpace(strings.TrimPrefix(m.commandContext.ArgStr, "join "))
+	groceryList, err := m.groceryService.JoinGroceryList(m.ctx, m.commandContext.GuildID, code, m.commandContext.AuthorID)
+	switch err {
+	case nil:
+	case grocery.ErrGroceryListInviteNotFound, grocery.ErrGroceryListNotShareable, grocery.ErrGroceryListAlreadyInGuild, grocery.ErrPersonalGroceryListSharing:
+		return m.reply(err.Error())
+	case grocery.ErrGroceryListLabelTaken:
+		return m.reply("Sorry, this server already has a grocery list with the same label as the one being shared. Relabel yours with `!grolist:<label> edit-label <new label>` first, then try the invite code again.")
+	default:
+		return m.onError(err)
+	}
+	if err := m.reply(fmt.Sprintf("Yay! **%s** has been shared with this server. Use it like any other grocery list, e.g. `!gro:%s Chicken` - changes show up in both servers.", groceryList.GetName(), groceryList.ListLabel)); err != nil {
+		return m.onError(err)
+	}
+	return m.onEditUpdateGrohere()
+}
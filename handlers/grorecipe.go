@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
+)
+
+const (
+	msgGroRecipeHelp = "Your recipe book keeps the recipes that you've imported with `/ingredients` (press **Save recipe**). Try:\n" +
+		"- `!grorecipe add lasagne` - adds the ingredients of your *lasagne* recipe to your grocery list\n" +
+		"- `!grorecipe add lasagne 8` - does the same, but with the quantities scaled to 8 servings\n" +
+		"- `!grorecipe` - lists your recipes\n" +
+		"- `!grorecipe show lasagne` - shows the recipe's ingredients\n" +
+		"- `!grorecipe delete lasagne` - deletes the recipe\n" +
+		"Use `!grorecipe:<label> add ...` to add them to your other grocery lists."
+	// leaves room for the rest of the reply within Discord's 2000 character limit
+	recipeIngredientsMaxLength = 1700
+)
+
+func (m *MessageHandlerContext) OnRecipe() error {
+	subCmd, name, _ := strings.Cut(strings.TrimSpace(m.commandContext.ArgStr), " ")
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(subCmd) {
+	case "", "list":
+		return m.listRecipes()
+	case "add":
+		return m.addRecipe(name)
+	case "show":
+		return m.showRecipe(name)
+	case "delete":
+		return m.deleteRecipe(name)
+	default:
+		return m.reply(msgGroRecipeHelp)
+	}
+}
+
+func (m *MessageHandlerContext) listRecipes() error {
+	recipeBook, err := m.recipesService.GetRecipes(m.ctx, m.commandContext.GuildID)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(recipeBook) == 0 {
+		return m.reply("Your recipe book is empty - import a recipe with `/ingredients` and press **Save recipe** to keep it here!")
+	}
+	var sb strings.Builder
+	sb.WriteString("Here's your recipe book:\n")
+	for _, recipe := range recipeBook {
+		ingredients, err := recipe.GetIngredients()
+		if err != nil {
+			return m.onError(err)
+		}
+		sb.WriteString(fmt.Sprintf("- **%s**", recipe.Name))
+		if recipe.Title != nil && *recipe.Title != "" {
+			sb.WriteString(fmt.Sprintf(" - %s", *recipe.Title))
+		}
+		sb.WriteString(fmt.Sprintf(" (%d ingredient(s)", len(ingredients)))
+		if recipe.Servings != nil {
+			sb.WriteString(fmt.Sprintf(", serves %d", *recipe.Servings))
+		}
+		sb.WriteString(")\n")
+	}
+	return m.reply(strings.TrimSpace(sb.String()))
+}
+
+func (m *MessageHandlerContext) addRecipe(argStr string) error {
+	name, servings := parseRecipeServings(argStr)
+	if name == "" {
+		return m.reply(msgGroRecipeHelp)
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	recipe, err := m.getRecipe(name)
+	if err != nil || recipe == nil {
+		return err
+	}
+	added, merged, err := m.recipesService.AddRecipe(m.ctx, m.commandContext.GuildID, recipe, servings, groceryList, m.commandContext.AuthorID)
+	var limitErr *recipes.GroceryEntryLimitError
+	if errors.As(err, &limitErr) {
+		return m.reply(msgOverLimit(limitErr.Limit))
+	}
+	if recipes.IsUserError(err) {
+		return m.reply(err.Error())
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	scaled := ""
+	if servings > 0 {
+		scaled = fmt.Sprintf(" (scaled to %d servings)", servings)
+	}
+	msg := fmt.Sprintf("Added %d ingredient(s) from **%s**%s to %s.", len(added)+len(merged), recipe.Name, scaled, groceryList.GetName())
+	if len(merged) > 0 {
+		msg += fmt.Sprintf(" Combined %s with what was already on there.", prettyItems(merged))
+	}
+	return m.reply(msg)
+}
+
+func (m *MessageHandlerContext) showRecipe(name string) error {
+	if name == "" {
+		return m.reply(msgGroRecipeHelp)
+	}
+	recipe, err := m.getRecipe(name)
+	if err != nil || recipe == nil {
+		return err
+	}
+	ingredients, err := recipe.GetIngredients()
+	if err != nil {
+		return m.onError(err)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s**", recipe.GetDisplayName()))
+	if recipe.Servings != nil {
+		sb.WriteString(fmt.Sprintf(" (serves %d)", *recipe.Servings))
+	}
+	if recipe.SourceURL != nil {
+		// wrapped in <> so that Discord doesn't embed the page
+		sb.WriteString(fmt.Sprintf("\n<%s>", *recipe.SourceURL))
+	}
+	sb.WriteString("\n")
+	for i, ingredient := range ingredients {
+		line := fmt.Sprintf("%d. %s\n", i+1, ingredient)
+		if sb.Len()+len(line) > recipeIngredientsMaxLength {
+			sb.WriteString(fmt.Sprintf("...and %d more\n", len(ingredients)-i))
+			break
+		}
+		sb.WriteString(line)
+	}
+	sb.WriteString(fmt.Sprintf("\nUse `!grorecipe add %s` to add them to your grocery list.", recipe.Name))
+	return m.reply(sb.String())
+}
+
+func (m *MessageHandlerContext) deleteRecipe(name string) error {
+	if name == "" {
+		return m.reply(msgGroRecipeHelp)
+	}
+	recipe, err := m.getRecipe(name)
+	if err != nil || recipe == nil {
+		return err
+	}
+	if err := m.recipesService.DeleteRecipe(m.ctx, recipe); err != nil {
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf("Deleted **%s** from your recipe book.", recipe.Name))
+}
+
+// getRecipe returns nil (after letting the user know) if the guild doesn't have a recipe called name.
+func (m *MessageHandlerContext) getRecipe(name string) (*models.Recipe, error) {
+	recipe, err := m.recipesService.GetRecipe(m.ctx, m.commandContext.GuildID, name)
+	if err != nil {
+		return nil, m.onError(err)
+	}
+	if recipe == nil {
+		return nil, m.reply(fmt.Sprintf("Hmm... Can't seem to find a recipe called *%s* - see `!grorecipe` for your recipe book.", name))
+	}
+	return recipe, nil
+}
+
+// parseRecipeServings splits the number of servings off the end of e.g. "lasagne 8". Servings is 0 if there's no number at the end.
+func parseRecipeServings(argStr string) (name string, servings int) {
+	fields := strings.Fields(argStr)
+	if len(fields) > 1 {
+		if n, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			return strings.Join(fields[:len(fields)-1], " "), n
+		}
+	}
+	return strings.Join(fields, " "), 0
+}
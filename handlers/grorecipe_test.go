@@ -0,0 +1,24 @@
+package handlers
+
+import "testing"
+
+func TestParseRecipeServings(t *testing.T) {
+	cases := []struct {
+		argStr   string
+		name     string
+		servings int
+	}{
+		{argStr: "lasagne", name: "lasagne"},
+		{argStr: "lasagne 8", name: "lasagne", servings: 8},
+		{argStr: "mum's  lasagne 2", name: "mum's lasagne", servings: 2},
+		{argStr: "8", name: "8"},
+		{argStr: "pad thai -1", name: "pad thai", servings: -1},
+		{argStr: "", name: ""},
+	}
+	for _, c := range cases {
+		name, servings := parseRecipeServings(c.argStr)
+		if name != c.name || servings != c.servings {
+			t.Errorf("%q: expected (%q, %d), got (%q, %d)", c.argStr, c.name, c.servings, name, servings)
+		}
+	}
+}
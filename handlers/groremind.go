@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/reminders"
+	"github.com/verzac/grocer-discord-bot/utils"
+)
+
+const msgGroRemindHelp = "I can remind you of what's on your grocery list. Try:\n" +
+	"- `!groremind add every saturday 9am` - posts your grocery list here every Saturday at 9am\n" +
+	"- `!groremind add every weekday 6:30pm in #shopping` - posts it in #shopping from Monday to Friday\n" +
+	"- `!groremind add every mon,thu 18:00 dm @bob @alice` - DMs it to Bob & Alice\n" +
+	"- `!groremind add cron 0 9 1 * *` - uses a cron expression (9am on the 1st of every month)\n" +
+	"- `!groremind` - lists your reminders\n" +
+	"- `!groremind remove 1` - removes reminder #1\n" +
+	"Use `!groremind:<label> add ...` for your other grocery lists. Times are in your server's timezone (see `/config set timezone`)."
+
+// maxReminderUsers is how many users a reminder can DM
+const maxReminderUsers = 10
+
+// e.g. "saturday 9am", "weekday at 6:30pm", "mon,thu 18:00"
+var reminderScheduleRegex = regexp.MustCompile(`(?i)^every\s+(.+?)\s+(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+var (
+	regexChannelMention = regexp.MustCompile(`^<#(\d+)>$`)
+	regexUserMention    = regexp.MustCompile(`^<@!?(\d+)>$`)
+)
+
+var errInvalidReminder = errors.New("cannot parse reminder")
+
+func (m *MessageHandlerContext) OnRemind() error {
+	subCmd, argStr, _ := strings.Cut(strings.TrimSpace(m.commandContext.ArgStr), " ")
+	switch strings.ToLower(subCmd) {
+	case "", "list":
+		return m.listReminders()
+	case "add":
+		return m.addReminder(strings.TrimSpace(argStr))
+	case "remove":
+		return m.removeReminder(strings.TrimSpace(argStr))
+	default:
+		return m.reply(msgGroRemindHelp)
+	}
+}
+
+func (m *MessageHandlerContext) listReminders() error {
+	groceryReminders, err := m.remindersService.GetReminders(m.ctx, m.commandContext.GuildID)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(groceryReminders) == 0 {
+		return m.reply("You don't have any reminders yet - add one with `!groremind add every saturday 9am`!")
+	}
+	var sb strings.Builder
+	sb.WriteString("Here are your reminders:\n")
+	for i, reminder := range groceryReminders {
+		listName, err := m.getReminderListName(&reminder)
+		if err != nil {
+			return m.onError(err)
+		}
+		sb.WriteString(fmt.Sprintf("%d: %s to %s - `%s` (next one: %s)\n", i+1, listName, getReminderDestinationText(&reminder), reminder.Cron, getDiscordTimestamp(reminder.NextRunAt)))
+	}
+	return m.reply(strings.TrimSpace(sb.String()))
+}
+
+func (m *MessageHandlerContext) addReminder(argStr string) error {
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	cronExpr, channelID, userIDs, err := parseReminder(argStr)
+	if err != nil {
+		return m.reply(msgGroRemindHelp)
+	}
+	if len(userIDs) > maxReminderUsers {
+		return m.reply(fmt.Sprintf("Sorry, a reminder can only DM up to %d people.", maxReminderUsers))
+	}
+	cc := m.commandContext
+	if models.IsPersonalGuildID(cc.GuildID) {
+		if channelID != "" || len(userIDs) > 0 {
+			return m.reply("In DMs, I can only remind you here - try it without the #channel or @mentions.")
+		}
+		channelID = cc.ChannelID
+	} else if channelID != "" {
+		// make sure nobody points a reminder at another server's channel
+		channel, err := m.sess.Channel(channelID)
+		if err != nil || channel.GuildID != cc.GuildID {
+			return m.reply("Hmm... I can't find that channel in this server :/")
+		}
+	} else if len(userIDs) == 0 {
+		channelID = cc.ChannelID
+	}
+	// same goes for DMing people outside of the server
+	if !areGuildMembers(userIDs, func(userID string) bool {
+		_, err := m.sess.GuildMember(cc.GuildID, userID)
+		return err == nil
+	}) {
+		return m.reply("Hmm... I can't find everyone you've mentioned in this server - I can only DM reminders to its members :/")
+	}
+	reminder := &models.GroceryReminder{
+		GuildID:     cc.GuildID,
+		Cron:        cronExpr,
+		CreatedByID: &cc.AuthorID,
+	}
+	if channelID != "" {
+		reminder.ChannelID = &channelID
+	}
+	reminder.SetUserIDs(userIDs)
+	err = m.remindersService.AddReminder(m.ctx, reminder, groceryList)
+	if errors.Is(err, reminders.ErrReminderLimitReached) || errors.Is(err, reminders.ErrInvalidReminderSchedule) || errors.Is(err, reminders.ErrGroceryListNotPublic) {
+		return m.reply(err.Error())
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	msg := fmt.Sprintf(":alarm_clock: Got it - I'll post %s to %s on `%s`. The first one's on %s.", groceryList.GetName(), getReminderDestinationText(reminder), reminder.Cron, getDiscordTimestamp(reminder.NextRunAt))
+	loc, err := m.remindersService.GetLocation(cc.GuildID)
+	if err != nil {
+		return m.onError(err)
+	}
+	if loc == time.UTC && !models.IsPersonalGuildID(cc.GuildID) {
+		msg += "\nps: I'm going by UTC - set your server's timezone with `/config set timezone` if that's not right."
+	}
+	return m.reply(msg)
+}
+
+func (m *MessageHandlerContext) removeReminder(argStr string) error {
+	reminderIndex, err := toItemIndex(argStr)
+	if err != nil {
+		return m.reply("Oops, I need the number of the reminder to remove - see `!groremind` for their numbers (e.g. `!groremind remove 1`).")
+	}
+	groceryReminders, err := m.remindersService.GetReminders(m.ctx, m.commandContext.GuildID)
+	if err != nil {
+		return m.onError(err)
+	}
+	if reminderIndex > len(groceryReminders) {
+		return m.reply(fmt.Sprintf("Hmm... Can't seem to find reminder #%d :/", reminderIndex))
+	}
+	reminder := &groceryReminders[reminderIndex-1]
+	if err := m.remindersService.RemoveReminder(m.ctx, reminder); err != nil {
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf("Removed reminder #%d - I'll stop posting to %s on `%s`.", reminderIndex, getReminderDestinationText(reminder), reminder.Cron))
+}
+
+func (m *MessageHandlerContext) getReminderListName(reminder *models.GroceryReminder) (string, error) {
+	if reminder.GroceryListID == nil {
+		return (*models.GroceryList)(nil).GetName(), nil
+	}
+	groceryList, err := m.groceryService.GetGuildGroceryList(m.ctx, reminder.GuildID, &models.GroceryList{ID: *reminder.GroceryListID})
+	if err != nil {
+		return "", err
+	}
+	if groceryList == nil {
+		return "a deleted grocery list", nil
+	}
+	return groceryList.GetName(), nil
+}
+
+func getReminderDestinationText(reminder *models.GroceryReminder) string {
+	destinations := make([]string, 0)
+	if reminder.ChannelID != nil {
+		destinations = append(destinations, fmt.Sprintf("<#%s>", *reminder.ChannelID))
+	}
+	for _, userID := range reminder.GetUserIDs() {
+		destinations = append(destinations, fmt.Sprintf("<@%s>", userID))
+	}
+	return strings.Join(destinations, ", ")
+}
+
+// getDiscordTimestamp formats t so that Discord shows it in each user's own timezone.
+func getDiscordTimestamp(t time.Time) string {
+	return fmt.Sprintf("<t:%d:F>", t.Unix())
+}
+
+// areGuildMembers checks that isGuildMember is true for all of userIDs.
+func areGuildMembers(userIDs []string, isGuildMember func(userID string) bool) bool {
+	for _, userID := range userIDs {
+		if !isGuildMember(userID) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseReminder reads the schedule & where to send the reminder from e.g. "every saturday 9am in #shopping dm @bob".
+// channelID & userIDs are empty if they aren't mentioned.
+func parseReminder(argStr string) (cronExpr string, channelID string, userIDs []string, err error) {
+	tokens := strings.Fields(argStr)
+	scheduleTokens := make([]string, 0, len(tokens))
+	for i, token := range tokens {
+		if match := regexChannelMention.FindStringSubmatch(token); match != nil {
+			channelID = match[1]
+			continue
+		}
+		if match := regexUserMention.FindStringSubmatch(token); match != nil {
+			userIDs = append(userIDs, match[1])
+			continue
+		}
+		lower := strings.ToLower(token)
+		if (lower == "in" || lower == "dm") && i+1 < len(tokens) && (regexChannelMention.MatchString(tokens[i+1]) || regexUserMention.MatchString(tokens[i+1])) {
+			continue
+		}
+		scheduleTokens = append(scheduleTokens, token)
+	}
+	cronExpr, err = parseReminderSchedule(strings.Join(scheduleTokens, " "))
+	if err != nil {
+		return "", "", nil, err
+	}
+	return cronExpr, channelID, userIDs, nil
+}
+
+// parseReminderSchedule turns e.g. "every saturday 9am" or "cron 0 9 * * 6" into a cron expression.
+func parseReminderSchedule(schedule string) (string, error) {
+	if cronExpr, ok := strings.CutPrefix(strings.ToLower(schedule), "cron "); ok {
+		cronExpr = strings.Join(strings.Fields(cronExpr), " ")
+		if _, err := utils.ParseCron(cronExpr); err != nil {
+			return "", errInvalidReminder
+		}
+		return cronExpr, nil
+	}
+	match := reminderScheduleRegex.FindStringSubmatch(strings.TrimSpace(schedule))
+	if match == nil {
+		return "", errInvalidReminder
+	}
+	days, err := parseReminderDays(strings.ToLower(match[1]))
+	if err != nil {
+		return "", err
+	}
+	hour, _ := strconv.Atoi(match[2])
+	minute := 0
+	if match[3] != "" {
+		minute, _ = strconv.Atoi(match[3])
+	}
+	switch strings.ToLower(match[4]) {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return "", errInvalidReminder
+		}
+		hour %= 12
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return "", errInvalidReminder
+		}
+		hour = hour%12 + 12
+	}
+	if hour > 23 || minute > 59 {
+		return "", errInvalidReminder
+	}
+	return utils.CronExpression(minute, hour, days), nil
+}
+
+// parseReminderDays reads e.g. "day", "weekday", "weekend" or "mon,thu" (nil means every day).
+func parseReminderDays(s string) ([]time.Weekday, error) {
+	switch s {
+	case "day":
+		return nil, nil
+	case "weekday":
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, nil
+	case "weekend":
+		return []time.Weekday{time.Saturday, time.Sunday}, nil
+	}
+	days := make([]time.Weekday, 0)
+	for _, token := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if token == "and" {
+			continue
+		}
+		weekday, ok := weekdays[token]
+		if !ok {
+			return nil, errInvalidReminder
+		}
+		days = append(days, weekday)
+	}
+	if len(days) == 0 {
+		return nil, errInvalidReminder
+	}
+	return days, nil
+}
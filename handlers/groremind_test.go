@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReminder(t *testing.T) {
+	cases := []struct {
+		argStr    string
+		cron      string
+		channelID string
+		userIDs   string
+	}{
+		{argStr: "every saturday 9am", cron: "0 9 * * 6"},
+		{argStr: "every Saturday at 9:30 PM", cron: "30 21 * * 6"},
+		{argStr: "every day 12am", cron: "0 0 * * *"},
+		{argStr: "every weekday 18:00", cron: "0 18 * * 1,2,3,4,5"},
+		{argStr: "every weekend 12pm", cron: "0 12 * * 6,0"},
+		{argStr: "every mon,thu 7pm", cron: "0 19 * * 1,4"},
+		{argStr: "every mon and thu 7pm", cron: "0 19 * * 1,4"},
+		{argStr: "cron 0  9 1 * *", cron: "0 9 1 * *"},
+		{argStr: "every sat 9am in <#123>", cron: "0 9 * * 6", channelID: "123"},
+		{argStr: "every sat 9am dm <@456> <@!789>", cron: "0 9 * * 6", userIDs: "456,789"},
+		{argStr: "<#123> every sat 9am dm <@456>", cron: "0 9 * * 6", channelID: "123", userIDs: "456"},
+	}
+	for _, c := range cases {
+		cron, channelID, userIDs, err := parseReminder(c.argStr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.argStr, err)
+		}
+		if cron != c.cron || channelID != c.channelID || strings.Join(userIDs, ",") != c.userIDs {
+			t.Errorf("%q: got %q %q %v, want %q %q %q", c.argStr, cron, channelID, userIDs, c.cron, c.channelID, c.userIDs)
+		}
+	}
+}
+
+func TestParseReminder_Invalid(t *testing.T) {
+	for _, argStr := range []string{
+		"",
+		"every saturday",
+		"every funday 9am",
+		"every saturday 13pm",
+		"every saturday 24:00",
+		"every saturday 9:60",
+		"cron 0 9 * *",
+		"cron 60 9 * * *",
+		"saturday 9am",
+	} {
+		if _, _, _, err := parseReminder(argStr); err == nil {
+			t.Errorf("%q: expected an error", argStr)
+		}
+	}
+}
+
+func TestAreGuildMembers(t *testing.T) {
+	members := map[string]bool{"456": true, "789": true}
+	isGuildMember := func(userID string) bool {
+		return members[userID]
+	}
+	cases := []struct {
+		userIDs []string
+		want    bool
+	}{
+		{userIDs: nil, want: true},
+		{userIDs: []string{"456", "789"}, want: true},
+		{userIDs: []string{"456", "123"}, want: false},
+		{userIDs: []string{"123"}, want: false},
+	}
+	for _, c := range cases {
+		if got := areGuildMembers(c.userIDs, isGuildMember); got != c.want {
+			t.Errorf("areGuildMembers(%v) = %v, want %v", c.userIDs, got, c.want)
+		}
+	}
+}
@@ -28,7 +28,7 @@ func (m *MessageHandlerContext) OnRemove() error {
 	}
 	groceries, err := m.groceryEntryRepo.FindByQueryWithConfig(
 		&models.GroceryEntry{
-			GuildID:       m.commandContext.GuildID,
+			GuildID:       groceryList.GetGuildID(m.commandContext.GuildID),
 			GroceryListID: groceryListID,
 		},
 		repositories.GroceryEntryQueryOpts{
@@ -52,10 +52,16 @@ func (m *MessageHandlerContext) OnRemove() error {
 	if err != nil {
 		return m.reply(err.Error())
 	}
-	if rDel := m.db.Delete(toDelete); rDel.Error != nil {
-		return m.onError(rDel.Error)
+	toDeleteIDs := make([]uint, len(toDelete))
+	for i := range toDelete {
+		toDeleteIDs[i] = toDelete[i].ID
 	}
-	if err := m.reply(fmt.Sprintf("Deleted %s off %s!", prettyItems(toDelete), groceryList.GetName())); err != nil {
+	if _, err := m.groceryEntryRepo.DeleteByGuildAndIDs(m.ctx, groceryList.GetGuildID(m.commandContext.GuildID), toDeleteIDs); err != nil {
+		return m.onError(err)
+	}
+	m.recordChanges(models.GroceryEntryChangeRemove, toDelete)
+	undoComponents := m.recordUndo(models.UndoOperationRemove, groceryList, &models.UndoSnapshot{GroceryEntries: toDelete})
+	if err := m.replyWithComponents(fmt.Sprintf("Deleted %s off %s!", prettyItems(toDelete), groceryList.GetName()), undoComponents); err != nil {
 		return m.onError(err)
 	}
 	return m.onEditUpdateGrohereWithGroceryList()
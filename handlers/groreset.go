@@ -1,13 +1,30 @@
 package handlers
 
+import "github.com/verzac/grocer-discord-bot/models"
+
 func (m *MessageHandlerContext) OnReset() error {
 	// if err := m.reply("Deleting all data for this server from my database... Please stand-by... :robot:"); err != nil {
 	// 	return m.onError(err)
 	// }
-	if err := m.guildsService.ResetGuild(m.ctx, m.commandContext.GuildID); err != nil {
+	// keep a copy of the groceries for !groundo
+	guildID := m.commandContext.GuildID
+	groceryEntries, err := m.groceryEntryRepo.FindByQuery(&models.GroceryEntry{GuildID: guildID})
+	if err != nil {
+		return m.onError(err)
+	}
+	groceryLists, err := m.groceryListRepo.FindByQuery(&models.GroceryList{GuildID: guildID})
+	if err != nil {
 		return m.onError(err)
 	}
-	if err := m.reply(":wave: I've successfully deleted all of your data from my database! (p.s. you may need to set up commands such as /grohere or /developer again)"); err != nil {
+	if err := m.guildsService.ResetGuild(m.ctx, guildID); err != nil {
+		return m.onError(err)
+	}
+	undoComponents := m.recordUndo(models.UndoOperationReset, nil, &models.UndoSnapshot{GroceryLists: groceryLists, GroceryEntries: groceryEntries})
+	msg := ":wave: I've successfully deleted all of your data from my database! (p.s. you may need to set up commands such as /grohere or /developer again)"
+	if len(undoComponents) > 0 {
+		msg += "\n\nMade a mistake? Your grocery lists can be brought back with `!groundo` within the next 24 hours, after which they're gone for good."
+	}
+	if err := m.replyWithComponents(msg, undoComponents); err != nil {
 		return m.onError(err)
 	}
 	return nil
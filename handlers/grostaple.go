@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/staples"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+const msgGroStapleHelp = "Staples are items that I put back on your grocery list by myself. Try:\n" +
+	"- `!grostaple add Milk every saturday` - adds Milk every Saturday (at midnight UTC)\n" +
+	"- `!grostaple add Eggs every 3 days` - adds Eggs every 3 days\n" +
+	"- `!grostaple add Coffee when checked` - adds Coffee back whenever it's checked off\n" +
+	"- `!grostaple` - lists your staples\n" +
+	"- `!grostaple remove 1` - removes staple #1\n" +
+	"Use `!grostaple:<label>` for your other grocery lists. Nothing is added if the item is still on your list."
+
+// e.g. "Milk 2L every saturday", "Eggs every 3 days", "Coffee when checked off"
+var stapleRegex = regexp.MustCompile(`(?i)^(.+?)\s+(?:every\s+(?:(\d+)\s+days?|(day)|([a-z]+))|(when\s+checked(?:\s+off)?))$`)
+
+var errInvalidStaple = errors.New("cannot parse staple")
+
+const maxStapleIntervalDays = 365
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+func (m *MessageHandlerContext) OnStaple() error {
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	subCmd, argStr, _ := strings.Cut(strings.TrimSpace(m.commandContext.ArgStr), " ")
+	switch strings.ToLower(subCmd) {
+	case "", "list":
+		return m.listStaples(groceryList)
+	case "add":
+		return m.addStaple(groceryList, strings.TrimSpace(argStr))
+	case "remove":
+		return m.removeStaple(groceryList, strings.TrimSpace(argStr))
+	default:
+		return m.reply(msgGroStapleHelp)
+	}
+}
+
+func (m *MessageHandlerContext) listStaples(groceryList *models.GroceryList) error {
+	groceryStaples, err := m.staplesService.GetStaples(m.ctx, groceryList.GetGuildID(m.commandContext.GuildID), groceryList)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(groceryStaples) == 0 {
+		return m.reply(fmt.Sprintf("%s doesn't have any staples yet - add one with `!grostaple add Milk every saturday`!", groceryList.GetName()))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Here are the staples of %s:\n", groceryList.GetName()))
+	for i, staple := range groceryStaples {
+		sb.WriteString(fmt.Sprintf("%d: %s - %s\n", i+1, staple.GetDisplayText(), staple.GetScheduleText()))
+	}
+	return m.reply(strings.TrimSpace(sb.String()))
+}
+
+func (m *MessageHandlerContext) addStaple(groceryList *models.GroceryList, argStr string) error {
+	staple, err := parseStaple(argStr)
+	if err != nil {
+		return m.reply(msgGroStapleHelp)
+	}
+	staple.GuildID = groceryList.GetGuildID(m.commandContext.GuildID)
+	staple.GroceryListID = groceryList.GetID()
+	staple.CreatedByID = &m.commandContext.AuthorID
+	err = m.staplesService.AddStaple(m.ctx, staple)
+	if errors.Is(err, staples.ErrStapleLimitReached) || errors.Is(err, staples.ErrInvalidSchedule) {
+		return m.reply(err.Error())
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf(":repeat: Got it - I'll put *%s* back on %s %s (unless it's still on there).", staple.GetDisplayText(), groceryList.GetName(), staple.GetScheduleText()))
+}
+
+func (m *MessageHandlerContext) removeStaple(groceryList *models.GroceryList, argStr string) error {
+	stapleIndex, err := toItemIndex(argStr)
+	if err != nil {
+		return m.reply("Oops, I need the number of the staple to remove - see `!grostaple` for their numbers (e.g. `!grostaple remove 1`).")
+	}
+	groceryStaples, err := m.staplesService.GetStaples(m.ctx, groceryList.GetGuildID(m.commandContext.GuildID), groceryList)
+	if err != nil {
+		return m.onError(err)
+	}
+	if stapleIndex > len(groceryStaples) {
+		return m.reply(fmt.Sprintf("Hmm... Can't seem to find staple #%d on %s :/", stapleIndex, groceryList.GetName()))
+	}
+	staple := &groceryStaples[stapleIndex-1]
+	if err := m.staplesService.RemoveStaple(m.ctx, staple); err != nil {
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf("I'll stop putting *%s* back on %s.", staple.GetDisplayText(), groceryList.GetName()))
+}
+
+// parseStaple reads the item (quantities included, just like !gro) & the schedule from e.g. "Milk 2L every saturday".
+func parseStaple(argStr string) (*models.GroceryStaple, error) {
+	match := stapleRegex.FindStringSubmatch(strings.TrimSpace(argStr))
+	if match == nil {
+		return nil, errInvalidStaple
+	}
+	itemDesc, quantity, unit := groceryutils.ParseQuantity(match[1])
+	if itemDesc == "" {
+		return nil, errInvalidStaple
+	}
+	staple := &models.GroceryStaple{
+		ItemDesc: itemDesc,
+		Quantity: quantity,
+		Unit:     unit,
+	}
+	switch {
+	case match[2] != "":
+		intervalDays, err := strconv.Atoi(match[2])
+		if err != nil || intervalDays < 1 || intervalDays > maxStapleIntervalDays {
+			return nil, errInvalidStaple
+		}
+		staple.Schedule = models.GroceryStapleScheduleInterval
+		staple.IntervalDays = &intervalDays
+	case match[3] != "":
+		intervalDays := 1
+		staple.Schedule = models.GroceryStapleScheduleInterval
+		staple.IntervalDays = &intervalDays
+	case match[4] != "":
+		weekday, ok := weekdays[strings.ToLower(match[4])]
+		if !ok {
+			return nil, errInvalidStaple
+		}
+		w := int(weekday)
+		staple.Schedule = models.GroceryStapleScheduleWeekly
+		staple.Weekday = &w
+	default:
+		staple.Schedule = models.GroceryStapleScheduleChecked
+	}
+	return staple, nil
+}
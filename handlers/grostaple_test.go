@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestParseStaple(t *testing.T) {
+	cases := []struct {
+		argStr       string
+		itemDesc     string
+		schedule     string
+		weekday      time.Weekday
+		intervalDays int
+	}{
+		{argStr: "Milk every saturday", itemDesc: "Milk", schedule: models.GroceryStapleScheduleWeekly, weekday: time.Saturday},
+		{argStr: "Oat milk every Mon", itemDesc: "Oat milk", schedule: models.GroceryStapleScheduleWeekly, weekday: time.Monday},
+		{argStr: "Eggs every 3 days", itemDesc: "Eggs", schedule: models.GroceryStapleScheduleInterval, intervalDays: 3},
+		{argStr: "Bread every day", itemDesc: "Bread", schedule: models.GroceryStapleScheduleInterval, intervalDays: 1},
+		{argStr: "Coffee when checked off", itemDesc: "Coffee", schedule: models.GroceryStapleScheduleChecked},
+		{argStr: "Coffee WHEN CHECKED", itemDesc: "Coffee", schedule: models.GroceryStapleScheduleChecked},
+	}
+	for _, c := range cases {
+		staple, err := parseStaple(c.argStr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.argStr, err)
+		}
+		if staple.ItemDesc != c.itemDesc || staple.Schedule != c.schedule {
+			t.Errorf("%q: got %q (%s), want %q (%s)", c.argStr, staple.ItemDesc, staple.Schedule, c.itemDesc, c.schedule)
+		}
+		if c.schedule == models.GroceryStapleScheduleWeekly && (staple.Weekday == nil || time.Weekday(*staple.Weekday) != c.weekday) {
+			t.Errorf("%q: got weekday %v, want %v", c.argStr, staple.Weekday, c.weekday)
+		}
+		if c.schedule == models.GroceryStapleScheduleInterval && (staple.IntervalDays == nil || *staple.IntervalDays != c.intervalDays) {
+			t.Errorf("%q: got interval %v, want %d", c.argStr, staple.IntervalDays, c.intervalDays)
+		}
+	}
+}
+
+func TestParseStaple_Quantity(t *testing.T) {
+	staple, err := parseStaple("Milk 2L every friday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staple.ItemDesc != "Milk" || staple.Quantity == nil || *staple.Quantity != 2 {
+		t.Fatalf("got %q %v", staple.ItemDesc, staple.Quantity)
+	}
+}
+
+func TestParseStaple_Invalid(t *testing.T) {
+	for _, argStr := range []string{"", "Milk", "every saturday", "Milk every someday", "Milk every 0 days", "Milk every 1000 days"} {
+		if _, err := parseStaple(argStr); err == nil {
+			t.Errorf("%q: expected an error", argStr)
+		}
+	}
+}
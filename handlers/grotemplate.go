@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/templates"
+)
+
+const msgGroTemplateHelp = "Templates let you put the same items back on a grocery list in one go. Try:\n" +
+	"- `!grotemplate save camping trip` - saves what's on your grocery list as the *camping trip* template\n" +
+	"- `!grotemplate apply camping trip` - adds the template's items to your grocery list (skipping what's already on there)\n" +
+	"- `!grotemplate` - lists your templates\n" +
+	"- `!grotemplate delete camping trip` - deletes the template\n" +
+	"Use `!grotemplate:<label> save ...` or `!grotemplate:<label> apply ...` for your other grocery lists."
+
+func (m *MessageHandlerContext) OnTemplate() error {
+	subCmd, name, _ := strings.Cut(strings.TrimSpace(m.commandContext.ArgStr), " ")
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(subCmd) {
+	case "", "list":
+		return m.listTemplates()
+	case "save":
+		return m.saveTemplate(name)
+	case "apply":
+		return m.applyTemplate(name)
+	case "delete":
+		return m.deleteTemplate(name)
+	default:
+		return m.reply(msgGroTemplateHelp)
+	}
+}
+
+func (m *MessageHandlerContext) listTemplates() error {
+	groceryTemplates, err := m.templatesService.GetTemplates(m.ctx, m.commandContext.GuildID)
+	if err != nil {
+		return m.onError(err)
+	}
+	if len(groceryTemplates) == 0 {
+		return m.reply("You don't have any templates yet - save your grocery list as one with `!grotemplate save <name>`!")
+	}
+	var sb strings.Builder
+	sb.WriteString("Here are your templates:\n")
+	for _, template := range groceryTemplates {
+		items, err := template.GetItems()
+		if err != nil {
+			return m.onError(err)
+		}
+		sb.WriteString(fmt.Sprintf("- **%s** (%d item(s))\n", template.Name, len(items)))
+	}
+	return m.reply(strings.TrimSpace(sb.String()))
+}
+
+func (m *MessageHandlerContext) saveTemplate(name string) error {
+	if name == "" {
+		return m.reply(msgGroTemplateHelp)
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	template, replaced, err := m.templatesService.SaveTemplate(m.ctx, m.commandContext.GuildID, name, groceryList, m.commandContext.AuthorID)
+	if isTemplateUserError(err) {
+		return m.reply(err.Error())
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	items, err := template.GetItems()
+	if err != nil {
+		return m.onError(err)
+	}
+	verb := "Saved"
+	if replaced {
+		verb = "Updated"
+	}
+	return m.reply(fmt.Sprintf(":floppy_disk: %s the **%s** template with the %d item(s) on %s - use `!grotemplate apply %s` to add them back.", verb, template.Name, len(items), groceryList.GetName(), template.Name))
+}
+
+func (m *MessageHandlerContext) applyTemplate(name string) error {
+	if name == "" {
+		return m.reply(msgGroTemplateHelp)
+	}
+	groceryList, err := m.GetGroceryListFromContext()
+	if err != nil {
+		return m.onGetGroceryListError(err)
+	}
+	template, err := m.getTemplate(name)
+	if err != nil || template == nil {
+		return err
+	}
+	added, skipped, err := m.templatesService.ApplyTemplate(m.ctx, m.commandContext.GuildID, template, groceryList, m.commandContext.AuthorID)
+	var limitErr *templates.GroceryEntryLimitError
+	if errors.As(err, &limitErr) {
+		return m.reply(msgOverLimit(limitErr.Limit))
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	msg := fmt.Sprintf("Added %d item(s) from the **%s** template to %s.", len(added), template.Name, groceryList.GetName())
+	if len(skipped) > 0 {
+		skippedEntries := make([]models.GroceryEntry, len(skipped))
+		for i := range skipped {
+			skippedEntries[i] = skipped[i].ToGroceryEntry()
+		}
+		msg += fmt.Sprintf(" Skipped %s since they're already on there.", prettyItems(skippedEntries))
+	}
+	return m.reply(msg)
+}
+
+func (m *MessageHandlerContext) deleteTemplate(name string) error {
+	if name == "" {
+		return m.reply(msgGroTemplateHelp)
+	}
+	template, err := m.getTemplate(name)
+	if err != nil || template == nil {
+		return err
+	}
+	if err := m.templatesService.DeleteTemplate(m.ctx, template); err != nil {
+		return m.onError(err)
+	}
+	return m.reply(fmt.Sprintf("Deleted the **%s** template.", template.Name))
+}
+
+// getTemplate returns nil (after letting the user know) if the guild doesn't have a template called name.
+func (m *MessageHandlerContext) getTemplate(name string) (*models.GroceryTemplate, error) {
+	template, err := m.templatesService.GetTemplate(m.ctx, m.commandContext.GuildID, name)
+	if err != nil {
+		return nil, m.onError(err)
+	}
+	if template == nil {
+		return nil, m.reply(fmt.Sprintf("Hmm... Can't seem to find a template called *%s* - see `!grotemplate` for your templates.", name))
+	}
+	return template, nil
+}
+
+func isTemplateUserError(err error) bool {
+	for _, target := range []error{
+		templates.ErrTemplateLimitReached,
+		templates.ErrInvalidTemplateName,
+		templates.ErrEmptyTemplate,
+		templates.ErrTemplateTooLarge,
+		templates.ErrGroceryListNotPublic,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/undo"
+	"go.uber.org/zap"
+)
+
+const (
+	CustomIDGroUndo  = "groundo"
+	MsgNothingToUndo = "Hmm, there's nothing for me to undo (I can only undo `!groclear`, `!groremove`, `!grobulk`, `!grocheck sweep` and `!groreset` from the last 24 hours)."
+)
+
+func (m *MessageHandlerContext) OnUndo() error {
+	operation, restored, err := m.undoService.Undo(m.ctx, m.commandContext.GuildID, nil, m.commandContext.AuthorID)
+	if errors.Is(err, undo.ErrNothingToUndo) {
+		return m.reply(MsgNothingToUndo)
+	}
+	var limitErr *undo.GroceryEntryLimitError
+	if errors.As(err, &limitErr) {
+		return m.reply(GetUndoOverLimitMessage(limitErr.Limit))
+	}
+	if err != nil {
+		return m.onError(err)
+	}
+	return m.reply(GetUndoMessage(operation, restored))
+}
+
+// GetUndoMessage describes what an undo has brought back.
+func GetUndoMessage(operation string, restored []models.GroceryEntry) string {
+	switch operation {
+	case models.UndoOperationReset:
+		return fmt.Sprintf(":rewind: Brought back your grocery lists and %d items! (p.s. you'll need to set up commands such as /grohere or /config again)", len(restored))
+	case models.UndoOperationReplace:
+		return fmt.Sprintf(":rewind: Put your grocery list back the way it was before `!grobulk` (%d items).", len(restored))
+	}
+	if len(restored) > 5 {
+		return fmt.Sprintf(":rewind: Brought back %d items!", len(restored))
+	}
+	return fmt.Sprintf(":rewind: Brought back %s!", prettyItems(restored))
+}
+
+// GetUndoOverLimitMessage explains why an undo was refused.
+func GetUndoOverLimitMessage(limit int) string {
+	return fmt.Sprintf("Whoops, I can't undo that - bringing those items back would take you over the limit of %d grocery entries per server. Remove a few items and try again!", limit)
+}
+
+// recordUndo journals the groceries from before a destructive operation, and returns an "Undo" button for the reply.
+// Failing to journal shouldn't fail the command, so this only logs the error & returns no components.
+func (m *MessageHandlerContext) recordUndo(operation string, groceryList *models.GroceryList, snapshot *models.UndoSnapshot) []discordgo.MessageComponent {
+	if len(snapshot.GroceryEntries) == 0 && len(snapshot.GroceryLists) == 0 {
+		return nil
+	}
+	journalID, err := m.undoService.Record(m.ctx, m.commandContext.GuildID, m.commandContext.AuthorID, operation, groceryList, snapshot)
+	if err != nil {
+		m.logger.Error("Failed to record undo journal entry.", zap.Error(err))
+		return nil
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Undo",
+					Style:    discordgo.SecondaryButton,
+					CustomID: CustomIDGroUndo + ":" + strconv.FormatUint(uint64(journalID), 10),
+				},
+			},
+		},
+	}
+}
@@ -17,8 +17,15 @@ import (
 	"github.com/verzac/grocer-discord-bot/repositories"
 	"github.com/verzac/grocer-discord-bot/services/announcement"
 	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
 	"github.com/verzac/grocer-discord-bot/services/guilds"
+	"github.com/verzac/grocer-discord-bot/services/history"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
 	"github.com/verzac/grocer-discord-bot/services/registration"
+	"github.com/verzac/grocer-discord-bot/services/reminders"
+	"github.com/verzac/grocer-discord-bot/services/staples"
+	"github.com/verzac/grocer-discord-bot/services/templates"
+	"github.com/verzac/grocer-discord-bot/services/undo"
 	"github.com/verzac/grocer-discord-bot/utils"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -44,17 +51,24 @@ const CmdPrefix = "!gro"
 
 // Note: make sure this is alphabetically ordered so that we don't get confused
 const (
-	CmdGroAdd    = "!gro"
-	CmdGroPatron = "!gropatron"
-	CmdGroBulk   = "!grobulk"
-	CmdGroClear  = "!groclear"
-	CmdGroDeets  = "!grodeets"
-	CmdGroEdit   = "!groedit"
-	CmdGroHelp   = "!grohelp"
-	CmdGroHere   = "!grohere"
-	CmdGroList   = "!grolist"
-	CmdGroRemove = "!groremove"
-	CmdGroReset  = "!groreset"
+	CmdGroAdd      = "!gro"
+	CmdGroPatron   = "!gropatron"
+	CmdGroBulk     = "!grobulk"
+	CmdGroCheck    = "!grocheck"
+	CmdGroClear    = "!groclear"
+	CmdGroDeets    = "!grodeets"
+	CmdGroEdit     = "!groedit"
+	CmdGroHelp     = "!grohelp"
+	CmdGroHere     = "!grohere"
+	CmdGroHistory  = "!grohistory"
+	CmdGroList     = "!grolist"
+	CmdGroRecipe   = "!grorecipe"
+	CmdGroRemind   = "!groremind"
+	CmdGroRemove   = "!groremove"
+	CmdGroReset    = "!groreset"
+	CmdGroStaple   = "!grostaple"
+	CmdGroTemplate = "!grotemplate"
+	CmdGroUndo     = "!groundo"
 )
 
 // Defines the enums to determine where the command is invoked from
@@ -78,8 +92,15 @@ type MessageHandlerContext struct {
 	registrationService         registration.RegistrationService
 	groceryService              grocery.GroceryService
 	guildsService               guilds.GuildsService
+	guildConfigService          guildconfig.GuildConfigService
 	guildConfigRepo             repositories.GuildConfigRepository
 	announcementService         announcement.AnnouncementService
+	undoService                 undo.UndoService
+	historyService              history.HistoryService
+	staplesService              staples.StaplesService
+	remindersService            reminders.RemindersService
+	templatesService            templates.TemplatesService
+	recipesService              recipes.RecipesService
 	cachedConfig                *models.GuildConfig
 	replyCounter                int
 	registrationContext         *dto.RegistrationContext // do not use directly - use GetRegistrationContext
@@ -99,6 +120,10 @@ type CommandContext struct {
 	CommandSourceType int
 	// nil if CommandSourceType != CommandSourceSlashCommand, ACCESS SPARINGLY
 	Interaction *discordgo.Interaction
+	// nil if unknown - used for permission checks, which look them up through Discord when needed
+	AuthorRoleIDs []string
+	// nil if unknown (e.g. for message commands) - see AuthorRoleIDs
+	AuthorPermissions *int64
 }
 
 func (m *MessageHandlerContext) checkReplyCounter() {
@@ -124,6 +149,16 @@ func (c *MessageHandlerContext) getConfig() (*models.GuildConfig, error) {
 }
 
 func (m *MessageHandlerContext) reply(msg string) error {
+	_, err := m.replyMessage(msg, false)
+	return err
+}
+
+// replyAndGetMessage replies just like reply does, but also returns the reply. The message is nil if the reply is ephemeral (or if it had to be DM-ed instead).
+func (m *MessageHandlerContext) replyAndGetMessage(msg string) (*discordgo.Message, error) {
+	return m.replyMessage(msg, true)
+}
+
+func (m *MessageHandlerContext) replyMessage(msg string, fetchMessage bool) (*discordgo.Message, error) {
 	m.checkReplyCounter()
 	ctx := m.ctx
 
@@ -137,7 +172,7 @@ func (m *MessageHandlerContext) reply(msg string) error {
 	switch m.commandContext.CommandSourceType {
 	case CommandSourceMessageContent:
 		m.replyCounter += 1
-		return m.sendMessage(augmentedMsg)
+		return m.sendMessageAndGet(augmentedMsg)
 	case CommandSourceSlashCommand:
 		m.replyCounter += 1
 		flags := discordgo.MessageFlags(0)
@@ -148,12 +183,58 @@ func (m *MessageHandlerContext) reply(msg string) error {
 		if config != nil && config.UseEphemeral {
 			flags |= discordgo.MessageFlagsEphemeral
 		}
-		return m.sess.InteractionRespond(m.commandContext.Interaction, &discordgo.InteractionResponse{
+		if err := m.sess.InteractionRespond(m.commandContext.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
 				Content: augmentedMsg,
 				Flags:   flags,
 			},
+		}); err != nil {
+			return nil, err
+		}
+		if !fetchMessage || flags&discordgo.MessageFlagsEphemeral != 0 {
+			return nil, nil
+		}
+		return m.sess.InteractionResponse(m.commandContext.Interaction)
+	default:
+		return nil, ErrMessageSourceNotRecognised
+	}
+}
+
+// replyWithComponents replies with interactive components (e.g. buttons), which are then handled by the native slash handlers
+func (m *MessageHandlerContext) replyWithComponents(msg string, components []discordgo.MessageComponent) error {
+	if len(components) == 0 {
+		return m.reply(msg)
+	}
+	m.checkReplyCounter()
+	switch m.commandContext.CommandSourceType {
+	case CommandSourceMessageContent:
+		m.replyCounter += 1
+		_, err := m.sess.ChannelMessageSendComplex(m.commandContext.ChannelID, &discordgo.MessageSend{
+			Content:    msg,
+			Components: components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+		})
+		return err
+	case CommandSourceSlashCommand:
+		m.replyCounter += 1
+		flags := discordgo.MessageFlags(0)
+		config, err := m.getConfig()
+		if err != nil {
+			m.logger.Error("Failed to load guild config. Non-critical error, skipping.", zap.Error(err))
+		}
+		if config != nil && config.UseEphemeral {
+			flags |= discordgo.MessageFlagsEphemeral
+		}
+		return m.sess.InteractionRespond(m.commandContext.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:    msg,
+				Components: components,
+				Flags:      flags,
+			},
 		})
 	default:
 		return ErrMessageSourceNotRecognised
@@ -183,14 +264,23 @@ func (m *MessageHandlerContext) replyWithEmbed(embed *discordgo.MessageEmbed) er
 func (m *MessageHandlerContext) GetGroceryListFromContext() (*models.GroceryList, error) {
 	groceryListLabel := m.commandContext.GrocerySublist
 	if groceryListLabel != "" {
-		groceryList := models.GroceryList{}
-		if r := m.db.Where(&models.GroceryList{ListLabel: groceryListLabel, GuildID: m.commandContext.GuildID}).Take(&groceryList); r.Error != nil {
-			if r.Error == gorm.ErrRecordNotFound {
-				return nil, errGroceryListNotFound
-			}
-			return nil, r.Error
+		// includes the lists that other guilds have shared with this one - use groceryList.GetGuildID() for their entries
+		groceryList, err := m.groceryService.GetGuildGroceryList(m.ctx, m.commandContext.GuildID, &models.GroceryList{ListLabel: groceryListLabel})
+		if err != nil {
+			return nil, err
+		}
+		if groceryList == nil {
+			return nil, errGroceryListNotFound
 		}
-		return &groceryList, nil
+		// lists that the author cannot see might as well not exist
+		canSee, err := m.groceryService.CanSeeGroceryList(m.ctx, m.getMemberContext(), groceryList)
+		if err != nil {
+			return nil, err
+		}
+		if !canSee {
+			return nil, errGroceryListNotFound
+		}
+		return groceryList, nil
 	}
 	return nil, nil
 }
@@ -205,12 +295,12 @@ func (m *MessageHandlerContext) checkListlessGroceries(listlessGroceries []model
 }
 
 func (m *MessageHandlerContext) ValidateGroceryEntryLimit(guildID string, newItemCount int) (limitOk bool, limit int, err error) {
-	registrationContext := m.GetRegistrationContext()
+	registrationContext := m.getRegistrationContextForGuild(guildID)
 	return m.groceryService.ValidateGroceryEntryLimit(context.Background(), registrationContext, guildID, newItemCount)
 }
 
 func (m *MessageHandlerContext) ValidateGroceryEntryLimitUsingTotalCount(guildID string, totalItemCount int) (limitOk bool, limit int, err error) {
-	registrationContext := m.GetRegistrationContext()
+	registrationContext := m.getRegistrationContextForGuild(guildID)
 	return m.groceryService.ValidateGroceryEntryLimitUsingTotalCount(context.Background(), registrationContext, guildID, totalItemCount)
 }
 
@@ -227,6 +317,9 @@ func NewMessageHandler(ctx context.Context, sess *discordgo.Session, msg *discor
 	if err != nil {
 		return nil, err
 	}
+	if msg.Member != nil {
+		cc.AuthorRoleIDs = msg.Member.Roles
+	}
 	newLogger := logger.With(zap.String("Command", cc.Command),
 		zap.String("GuildID", cc.GuildID),
 	)
@@ -247,8 +340,15 @@ func NewHandler(ctx context.Context, sess *discordgo.Session, cc *CommandContext
 		registrationService:         registration.Service,
 		groceryService:              grocery.Service,
 		guildsService:               guilds.Service,
+		guildConfigService:          guildconfig.Service,
 		guildConfigRepo:             &repositories.GuildConfigRepositoryImpl{DB: db},
 		announcementService:         announcement.Service,
+		undoService:                 undo.Service,
+		historyService:              history.Service,
+		staplesService:              staples.Service,
+		remindersService:            reminders.Service,
+		templatesService:            templates.Service,
+		recipesService:              recipes.Service,
 		ctx:                         ctx,
 	}
 }
@@ -334,10 +434,16 @@ func (m *MessageHandlerContext) sendDirectMessage(msg string, userID string) err
 }
 
 func (m *MessageHandlerContext) sendMessage(msg string) error {
+	_, err := m.sendMessageAndGet(msg)
+	return err
+}
+
+// sendMessageAndGet returns nil for the message if it had to be DM-ed to the user instead.
+func (m *MessageHandlerContext) sendMessageAndGet(msg string) (*discordgo.Message, error) {
 	if !m.commandContext.IsMentioned {
 		msg += fmt.Sprintf("\n\n*Psst, since you didn't mention me in your command: due to a Discord policy change, from 31 August 2022, you need to mention <@%s> if you use commands prefixed with `!gro`, otherwise I won't be able to read your commands! Alternatively, you can also use slash commands - just start typing `/gro` (if it doesn't work please re-invite me through https://grocerybot.net)! :person_bowing:\n\nMore details: https://grocerybot.net/blog/message-command-deprecation/*", m.sess.State.User.ID)
 	}
-	_, sErr := m.sess.ChannelMessageSendComplex(m.commandContext.ChannelID, &discordgo.MessageSend{
+	sent, sErr := m.sess.ChannelMessageSendComplex(m.commandContext.ChannelID, &discordgo.MessageSend{
 		Content: msg,
 		AllowedMentions: &discordgo.MessageAllowedMentions{
 			// do not allow mentions by default
@@ -353,10 +459,10 @@ func (m *MessageHandlerContext) sendMessage(msg string) error {
 		), m.commandContext.AuthorID)
 		if dmErr != nil {
 			m.GetLogger().Error("Unable to DM user that GroBot is missing permission.", zap.NamedError("OriginalError", sErr), zap.NamedError("DMError", dmErr))
-			return sErr
+			return nil, sErr
 		}
 	}
-	return nil
+	return sent, nil
 }
 
 func (m *MessageHandlerContext) onGetGroceryListError(err error) error {
@@ -380,6 +486,19 @@ func (m *MessageHandlerContext) GetRegistrationContext() *dto.RegistrationContex
 	return registrationContext
 }
 
+// getRegistrationContextForGuild returns GetRegistrationContext unless it's for another guild, which is the case for
+// grocery lists shared with this one - their entries count against the limits of the guild that owns them.
+func (m *MessageHandlerContext) getRegistrationContextForGuild(guildID string) *dto.RegistrationContext {
+	if guildID == m.commandContext.GuildID {
+		return m.GetRegistrationContext()
+	}
+	registrationContext, err := m.registrationService.GetRegistrationContext(guildID)
+	if err != nil {
+		m.GetLogger().Error("Failed to find registration.", zap.Error(err), zap.String("OwnerGuildID", guildID))
+	}
+	return registrationContext
+}
+
 func toItemIndex(argStr string) (int, error) {
 	itemIndex, err := strconv.Atoi(argStr)
 	if err != nil {
@@ -399,6 +518,11 @@ func prettyItemIndexList(itemIndexes []int) string {
 	return strings.Join(tokens, ", ")
 }
 
+// recordChanges records the same change for each of the entries (see OnGroceryEntriesChanged)
+func (m *MessageHandlerContext) recordChanges(action string, entries []models.GroceryEntry) {
+	m.groceryService.OnGroceryEntriesChanged(m.ctx, models.NewGroceryEntryChanges(action, entries, m.commandContext.AuthorID))
+}
+
 func prettyItems(gList []models.GroceryEntry) string {
 	tokens := make([]string, len(gList))
 	for i, gEntry := range gList {
@@ -406,7 +530,7 @@ func prettyItems(gList []models.GroceryEntry) string {
 		if i == len(gList)-1 && len(gList) > 1 {
 			format = fmt.Sprintf("and %s", format)
 		}
-		tokens[i] = fmt.Sprintf(format, gEntry.ItemDesc)
+		tokens[i] = fmt.Sprintf(format, gEntry.GetDisplayText())
 	}
 	return strings.Join(tokens, ", ")
 }
@@ -425,6 +549,11 @@ func GetCommandContext(body string, guildID string, authorID string, channelID s
 	}
 	isMentioned := mentionRegex.MatchString(body)
 	body = strings.Trim(mentionRegex.ReplaceAllString(body, ""), " \n")
+	if guildID == "" {
+		// sent through DMs, which work on the author's personal grocery lists (and don't need GroceryBot to be mentioned)
+		guildID = models.PersonalGuildID(authorID)
+		isMentioned = true
+	}
 	if !strings.HasPrefix(body, CmdPrefix) {
 		return nil, ErrCmdNotProcessable
 	}
@@ -517,6 +646,13 @@ func (mh *MessageHandlerContext) Handle() (err error) {
 		zap.String("ArgStr", mh.commandContext.ArgStr),
 		zap.String("GrocerySublist", mh.commandContext.GrocerySublist),
 	)
+	missingCapability, err := mh.checkCapabilities()
+	if err != nil {
+		return mh.onError(err)
+	}
+	if missingCapability != "" {
+		return mh.onMissingCapability(missingCapability)
+	}
 	switch mh.commandContext.Command {
 	case CmdGroAdd:
 		err = mh.OnAdd()
@@ -524,6 +660,8 @@ func (mh *MessageHandlerContext) Handle() (err error) {
 		err = mh.OnRemove()
 	case CmdGroEdit:
 		err = mh.OnEdit()
+	case CmdGroCheck:
+		err = mh.OnCheck()
 	case CmdGroBulk:
 		err = mh.OnBulk()
 	case CmdGroList:
@@ -536,8 +674,20 @@ func (mh *MessageHandlerContext) Handle() (err error) {
 		err = mh.OnDetail()
 	case CmdGroHere:
 		err = mh.OnAttach()
+	case CmdGroHistory:
+		err = mh.OnHistory()
+	case CmdGroRecipe:
+		err = mh.OnRecipe()
+	case CmdGroRemind:
+		err = mh.OnRemind()
 	case CmdGroReset:
 		err = mh.OnReset()
+	case CmdGroStaple:
+		err = mh.OnStaple()
+	case CmdGroTemplate:
+		err = mh.OnTemplate()
+	case CmdGroUndo:
+		err = mh.OnUndo()
 	case CmdGroPatron:
 		err = mh.OnPatron()
 	default:
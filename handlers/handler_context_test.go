@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestGetCommandContextInDirectMessage(t *testing.T) {
+	cc, err := GetCommandContext("!gro:pharmacy plasters", "", "123", "dm-channel", "bot", "alice", "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.GuildID != models.PersonalGuildID("123") {
+		t.Errorf("GuildID = %q, want the author's personal guild", cc.GuildID)
+	}
+	if !cc.IsMentioned {
+		t.Error("DMs shouldn't need GroceryBot to be mentioned")
+	}
+	if cc.Command != CmdGroAdd || cc.GrocerySublist != "pharmacy" || cc.ArgStr != "plasters" {
+		t.Errorf("unexpected command context: %+v", cc)
+	}
+
+	cc, err = GetCommandContext("!gro plasters", "guild", "123", "channel", "bot", "alice", "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.GuildID != "guild" || cc.IsMentioned {
+		t.Errorf("unexpected command context in a guild: %+v", cc)
+	}
+}
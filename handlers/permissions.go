@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
+)
+
+// requiredCapabilities returns the capabilities that the command needs (see /config permissions).
+func (m *MessageHandlerContext) requiredCapabilities() ([]string, error) {
+	cc := m.commandContext
+	switch cc.Command {
+	case CmdGroAdd, CmdGroEdit:
+		return []string{models.CapabilityAdd}, nil
+	case CmdGroCheck:
+		if strings.ToLower(strings.TrimSpace(cc.ArgStr)) == groCheckSweepArg {
+			return []string{models.CapabilityRemove}, nil
+		}
+		return []string{models.CapabilityAdd}, nil
+	case CmdGroBulk:
+		config, err := m.getConfig()
+		if err != nil {
+			return nil, err
+		}
+		if config == nil || !config.UseGrobulkAppend {
+			// replaces the list
+			return []string{models.CapabilityAdd, models.CapabilityClear}, nil
+		}
+		return []string{models.CapabilityAdd}, nil
+	case CmdGroRemove, CmdGroUndo:
+		return []string{models.CapabilityRemove}, nil
+	case CmdGroClear:
+		return []string{models.CapabilityClear}, nil
+	case CmdGroList:
+		for _, prefix := range []string{"new ", "delete", "edit-name ", "edit-label ", "visibility", "share", "join "} {
+			if strings.HasPrefix(cc.ArgStr, prefix) {
+				return []string{models.CapabilityManageLists}, nil
+			}
+		}
+		return nil, nil
+	case CmdGroHere:
+		return []string{models.CapabilityManageLists}, nil
+	case CmdGroStaple, CmdGroRemind:
+		for _, prefix := range []string{"add ", "remove "} {
+			if strings.HasPrefix(strings.ToLower(cc.ArgStr), prefix) {
+				return []string{models.CapabilityManageLists}, nil
+			}
+		}
+		return nil, nil
+	case CmdGroRecipe:
+		argStr := strings.ToLower(cc.ArgStr)
+		if strings.HasPrefix(argStr, "add ") {
+			return []string{models.CapabilityAdd}, nil
+		}
+		if strings.HasPrefix(argStr, "delete ") {
+			return []string{models.CapabilityManageLists}, nil
+		}
+		return nil, nil
+	case CmdGroTemplate:
+		argStr := strings.ToLower(cc.ArgStr)
+		if strings.HasPrefix(argStr, "apply ") {
+			return []string{models.CapabilityAdd}, nil
+		}
+		for _, prefix := range []string{"save ", "delete "} {
+			if strings.HasPrefix(argStr, prefix) {
+				return []string{models.CapabilityManageLists}, nil
+			}
+		}
+		return nil, nil
+	case CmdGroReset:
+		return []string{models.CapabilityReset}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// checkCapabilities returns the first capability that the author is missing for the command, or "" if they have all of them.
+func (m *MessageHandlerContext) checkCapabilities() (missingCapability string, err error) {
+	capabilities, err := m.requiredCapabilities()
+	if err != nil {
+		return "", err
+	}
+	member := m.getMemberContext()
+	for _, capability := range capabilities {
+		ok, err := m.guildConfigService.HasCapability(m.ctx, member, capability)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return capability, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *MessageHandlerContext) getMemberContext() *dto.MemberContext {
+	cc := m.commandContext
+	return &dto.MemberContext{
+		GuildID:     cc.GuildID,
+		ChannelID:   cc.ChannelID,
+		UserID:      cc.AuthorID,
+		RoleIDs:     cc.AuthorRoleIDs,
+		Permissions: cc.AuthorPermissions,
+	}
+}
+
+func (m *MessageHandlerContext) onMissingCapability(capability string) error {
+	return m.reply(guildconfig.FmtCapabilityDeniedMessage(capability))
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	cases := []struct {
+		command    string
+		argStr     string
+		grobulkAdd bool
+		want       []string
+	}{
+		{command: CmdGroAdd, argStr: "milk", want: []string{models.CapabilityAdd}},
+		{command: CmdGroCheck, argStr: "1 2", want: []string{models.CapabilityAdd}},
+		{command: CmdGroCheck, argStr: "Sweep", want: []string{models.CapabilityRemove}},
+		{command: CmdGroBulk, argStr: "milk", grobulkAdd: true, want: []string{models.CapabilityAdd}},
+		{command: CmdGroBulk, argStr: "milk", want: []string{models.CapabilityAdd, models.CapabilityClear}},
+		{command: CmdGroList, argStr: "", want: nil},
+		{command: CmdGroList, argStr: "all", want: nil},
+		{command: CmdGroList, argStr: "delete", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroList, argStr: "new amazon", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroList, argStr: "visibility users <@123>", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroList, argStr: "share", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroList, argStr: "join ABCDE23456", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroStaple, argStr: "", want: nil},
+		{command: CmdGroStaple, argStr: "add Milk every saturday", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroStaple, argStr: "remove 1", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroRemind, argStr: "list", want: nil},
+		{command: CmdGroRemind, argStr: "add every saturday 9am", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroRemind, argStr: "remove 1", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroRecipe, argStr: "", want: nil},
+		{command: CmdGroRecipe, argStr: "show lasagne", want: nil},
+		{command: CmdGroRecipe, argStr: "add lasagne 6", want: []string{models.CapabilityAdd}},
+		{command: CmdGroRecipe, argStr: "delete lasagne", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroTemplate, argStr: "", want: nil},
+		{command: CmdGroTemplate, argStr: "apply camping trip", want: []string{models.CapabilityAdd}},
+		{command: CmdGroTemplate, argStr: "save camping trip", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroTemplate, argStr: "Delete camping trip", want: []string{models.CapabilityManageLists}},
+		{command: CmdGroReset, want: []string{models.CapabilityReset}},
+		{command: CmdGroHelp, want: nil},
+	}
+	for _, c := range cases {
+		m := &MessageHandlerContext{
+			commandContext: &CommandContext{Command: c.command, ArgStr: c.argStr},
+			cachedConfig:   &models.GuildConfig{UseGrobulkAppend: c.grobulkAdd},
+		}
+		got, err := m.requiredCapabilities()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%s %q: got %v, want %v", c.command, c.argStr, got, c.want)
+		}
+	}
+}
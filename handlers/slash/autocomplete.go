@@ -1,15 +1,20 @@
 package slash
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
 	"github.com/verzac/grocer-discord-bot/handlers"
 	"github.com/verzac/grocer-discord-bot/handlers/slash/defaults"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
+	"github.com/verzac/grocer-discord-bot/services/templates"
 	"github.com/verzac/grocer-discord-bot/utils"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -18,6 +23,7 @@ import (
 var (
 	ErrAutocompleteCommandNotRecognised = errors.New("not sure which command this auto-complete event is for")
 	ErrAutocompleteMissingOption        = errors.New("missing option")
+	errAutocompleteGroceryListNotFound  = errors.New("cannot find grocery list")
 )
 
 type AutocompleteHandler struct {
@@ -61,10 +67,37 @@ func (a *AutocompleteHandler) GetGroceryList() (*models.GroceryList, error) {
 	if sublistLabel == "" {
 		return nil, nil
 	}
-	return a.groceryListRepo.GetByQuery(&models.GroceryList{
+	groceryList, err := grocery.Service.GetGuildGroceryList(context.Background(), a.guildID, &models.GroceryList{ListLabel: sublistLabel})
+	if err != nil {
+		return nil, err
+	}
+	if groceryList == nil {
+		// nil would mean the default list
+		return nil, errAutocompleteGroceryListNotFound
+	}
+	canSee, err := grocery.Service.CanSeeGroceryList(context.Background(), a.getMemberContext(), groceryList)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee {
+		return nil, errAutocompleteGroceryListNotFound
+	}
+	return groceryList, nil
+}
+
+func (a *AutocompleteHandler) getMemberContext() *dto.MemberContext {
+	m := &dto.MemberContext{
 		GuildID:   a.guildID,
-		ListLabel: sublistLabel,
-	})
+		ChannelID: a.interaction.ChannelID,
+	}
+	if member := a.interaction.Member; member != nil {
+		m.RoleIDs = member.Roles
+		m.Permissions = &member.Permissions
+		if member.User != nil {
+			m.UserID = member.User.ID
+		}
+	}
+	return m
 }
 
 func (a *AutocompleteHandler) Handle() error {
@@ -79,7 +112,7 @@ func (a *AutocompleteHandler) Handle() error {
 		})
 	}
 	switch "!" + a.commandData.Name {
-	case handlers.CmdGroRemove:
+	case handlers.CmdGroRemove, handlers.CmdGroCheck:
 		entry, ok := a.nameToOptionsMap["entry"]
 		if !ok {
 			return ErrAutocompleteMissingOption
@@ -107,6 +140,32 @@ func (a *AutocompleteHandler) Handle() error {
 				},
 			})
 		}
+	case "!grotemplate-apply", "!grotemplate-delete":
+		name, ok := a.nameToOptionsMap["name"]
+		if !ok {
+			return ErrAutocompleteMissingOption
+		}
+		if name.Focused {
+			return a.sess.InteractionRespond(a.interaction.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+				Data: &discordgo.InteractionResponseData{
+					Choices: a.GetTemplateChoices(name.StringValue()),
+				},
+			})
+		}
+	case "!recipe-add", "!recipe-show", "!recipe-delete":
+		name, ok := a.nameToOptionsMap["name"]
+		if !ok {
+			return ErrAutocompleteMissingOption
+		}
+		if name.Focused {
+			return a.sess.InteractionRespond(a.interaction.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+				Data: &discordgo.InteractionResponseData{
+					Choices: a.GetRecipeChoices(name.StringValue()),
+				},
+			})
+		}
 	default:
 		return ErrAutocompleteCommandNotRecognised
 	}
@@ -120,6 +179,17 @@ func (a *AutocompleteHandler) GetGroceryListChoices(queryString string) []*disco
 		a.logger.Error("Failed to load grocery lists.", zap.Error(err))
 		return choices
 	}
+	sharedLists, err := grocery.Service.FindSharedGroceryLists(context.Background(), a.guildID)
+	if err != nil {
+		a.logger.Error("Failed to load shared grocery lists.", zap.Error(err))
+		return choices
+	}
+	groceryLists = append(groceryLists, sharedLists...)
+	groceryLists, _, err = grocery.Service.FilterVisibleGroceryLists(context.Background(), a.getMemberContext(), groceryLists, nil)
+	if err != nil {
+		a.logger.Error("Failed to filter grocery lists.", zap.Error(err))
+		return choices
+	}
 	for _, gl := range groceryLists {
 		if strings.Contains(strings.ToLower(gl.ListLabel), strings.ToLower(queryString)) {
 			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
@@ -131,9 +201,30 @@ func (a *AutocompleteHandler) GetGroceryListChoices(queryString string) []*disco
 	return choices
 }
 
+func (a *AutocompleteHandler) GetTemplateChoices(queryString string) []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	groceryTemplates, err := templates.Service.GetTemplates(context.Background(), a.guildID)
+	if err != nil {
+		a.logger.Error("Failed to load templates.", zap.Error(err))
+		return choices
+	}
+	for _, t := range groceryTemplates {
+		if strings.Contains(t.Name, strings.ToLower(queryString)) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(t.Name),
+				Value: t.Name,
+			})
+		}
+	}
+	return choices
+}
+
 func (a *AutocompleteHandler) GetGroceryEntryChoices(queryString string) []*discordgo.ApplicationCommandOptionChoice {
 	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
 	groceryList, err := a.GetGroceryList()
+	if err == errAutocompleteGroceryListNotFound {
+		return choices
+	}
 	if err != nil {
 		// todo handle err
 		a.logger.Error("Failed to load grocery entries.", zap.Error(err))
@@ -141,7 +232,7 @@ func (a *AutocompleteHandler) GetGroceryEntryChoices(queryString string) []*disc
 	}
 	groceries, err := a.groceryEntryRepo.FindByQueryWithConfig(
 		&models.GroceryEntry{
-			GuildID:       a.guildID,
+			GuildID:       groceryList.GetGuildID(a.guildID),
 			GroceryListID: groceryList.GetID(),
 		},
 		repositories.GroceryEntryQueryOpts{
@@ -155,7 +246,7 @@ func (a *AutocompleteHandler) GetGroceryEntryChoices(queryString string) []*disc
 	for idx, g := range groceries {
 		if strings.Contains(strings.ToLower(g.ItemDesc), strings.ToLower(queryString)) {
 			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
-				Name:  truncate(g.ItemDesc),
+				Name:  truncate(g.GetDisplayText()),
 				Value: strconv.Itoa(idx + 1),
 			})
 		}
@@ -170,3 +261,24 @@ func (a *AutocompleteHandler) GetGroceryEntryChoices(queryString string) []*disc
 func truncate(str string) string {
 	return utils.TruncateStringWithTargetLength(str, 90)
 }
+
+func (a *AutocompleteHandler) GetRecipeChoices(queryString string) []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	recipeBook, err := recipes.Service.GetRecipes(context.Background(), a.guildID)
+	if err != nil {
+		a.logger.Error("Failed to load recipes.", zap.Error(err))
+		return choices
+	}
+	for _, r := range recipeBook {
+		if strings.Contains(r.Name, strings.ToLower(queryString)) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(r.Name),
+				Value: r.Name,
+			})
+		}
+		if len(choices) >= 25 {
+			break
+		}
+	}
+	return choices
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,8 +16,10 @@ import (
 	"github.com/verzac/grocer-discord-bot/handlers/slash/defaults"
 	"github.com/verzac/grocer-discord-bot/handlers/slash/modal"
 	"github.com/verzac/grocer-discord-bot/handlers/slash/native"
+	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/monitoring"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gorm.io/gorm"
@@ -28,6 +31,19 @@ var (
 	ErrIncorrectFormatInt                   = errors.New("expected a number as an input")
 )
 
+// the lowest number of servings that /recipe-add takes (MinValue needs a pointer)
+var minRecipeServings = 1.0
+
+// guildOnlySlashCommands need a server, e.g. because they're about its roles or its registration
+var guildOnlySlashCommands = map[string]bool{
+	"config":             true,
+	"developer":          true,
+	"gropatron":          true,
+	"grolist-visibility": true,
+	"grolist-share":      true,
+	"grolist-join":       true,
+}
+
 type argStrMarshaller = func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error)
 
 type slashCommandHandlerMetadata struct {
@@ -39,6 +55,34 @@ type slashCommandHandlerMetadata struct {
 	commandMappingOverride string
 }
 
+// permissionOptions are shared by /config permissions grant & revoke
+var permissionOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "capability",
+		Description: "What the role can do.",
+		Required:    true,
+		Choices:     getCapabilityChoices(),
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionRole,
+		Name:        "role",
+		Description: "The role.",
+		Required:    true,
+	},
+}
+
+func getCapabilityChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(models.Capabilities))
+	for i, capability := range models.Capabilities {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%s - %s", capability, models.CapabilityDescriptions[capability]),
+			Value: capability,
+		}
+	}
+	return choices
+}
+
 var (
 	commands = []*discordgo.ApplicationCommand{
 		{
@@ -99,6 +143,29 @@ var (
 				defaults.DefaultListLabelOption,
 			},
 		},
+		{
+			Name:        "grocheck",
+			Description: "Check off (or un-check) a grocery entry once it's been bought.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "entry",
+					Description:  "Item name or numbers to check off.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grocheck-sweep",
+			Description: "Remove all checked-off entries from your grocery list.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				defaults.DefaultListLabelOption,
+			},
+		},
 		{
 			Name:        "grohelp",
 			Description: "Get help!",
@@ -146,11 +213,305 @@ var (
 				},
 			},
 		},
+		{
+			Name:        "grolist-visibility",
+			Description: "Choose who can see your grocery list.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         defaults.DefaultListLabelOption.Type,
+					Name:         defaults.DefaultListLabelOption.Name,
+					Description:  defaults.DefaultListLabelOption.Description,
+					Autocomplete: defaults.DefaultListLabelOption.Autocomplete,
+					Required:     true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "visibility",
+					Description: "Who can see the grocery list, other than its owner.",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Everyone", Value: models.GroceryListVisibilityPublic},
+						{Name: "Members with certain roles", Value: models.GroceryListVisibilityRoles},
+						{Name: "Certain members", Value: models.GroceryListVisibilityUsers},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "who",
+					Description: "The @roles or @members who can see the grocery list.",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "grolist-share",
+			Description: "Get an invite code to share your grocery list with another server.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         defaults.DefaultListLabelOption.Type,
+					Name:         defaults.DefaultListLabelOption.Name,
+					Description:  defaults.DefaultListLabelOption.Description,
+					Autocomplete: defaults.DefaultListLabelOption.Autocomplete,
+					Required:     true,
+				},
+			},
+		},
+		{
+			Name:        "grolist-join",
+			Description: "Add a grocery list that another server has shared with you (see /grolist-share).",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "code",
+					Description: "The invite code from /grolist-share.",
+					Required:    true,
+				},
+			},
+		},
 		{
 			Name:        "groreset",
 			Description: "Clear all of your data from GroceryBot.",
 			Type:        discordgo.ChatApplicationCommand,
 		},
+		{
+			Name:        "groundo",
+			Description: "Undo the last clear, removal, bulk replace, sweep or reset.",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "grohistory",
+			Description: "See who has added, edited, checked off or removed what on your grocery list.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "count",
+					Description: "How many of the latest changes to show (defaults to 10).",
+					Required:    false,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grostaple-add",
+			Description: "Have an item put back on your grocery list by itself.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "entry",
+					Description: "The item to put back, e.g. Milk 2L.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "repeat",
+					Description: "When to put it back (UTC).",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Every week (pick a weekday)", Value: models.GroceryStapleScheduleWeekly},
+						{Name: "Every few days (pick the days)", Value: models.GroceryStapleScheduleInterval},
+						{Name: "Whenever it's checked off", Value: models.GroceryStapleScheduleChecked},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "weekday",
+					Description: "The day of the week to put it back on.",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Monday", Value: "monday"},
+						{Name: "Tuesday", Value: "tuesday"},
+						{Name: "Wednesday", Value: "wednesday"},
+						{Name: "Thursday", Value: "thursday"},
+						{Name: "Friday", Value: "friday"},
+						{Name: "Saturday", Value: "saturday"},
+						{Name: "Sunday", Value: "sunday"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "How many days to wait before putting it back again.",
+					Required:    false,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grostaple-remove",
+			Description: "Stop putting an item back on your grocery list.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "number",
+					Description: "The number of the staple, from /grostaple-list.",
+					Required:    true,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grostaple-list",
+			Description: "See the items that get put back on your grocery list by themselves.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "groremind-add",
+			Description: "Have your grocery list posted to a channel or DM-ed to people on a schedule.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "when",
+					Description: "e.g. every saturday 9am, every weekday 6:30pm or cron 0 9 * * 6 (server's timezone).",
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel to post it in (defaults to this one, unless people are DM-ed).",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "dm",
+					Description: "@mention the people to DM it to.",
+					Required:    false,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "groremind-remove",
+			Description: "Stop a shopping reminder.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "number",
+					Description: "The number of the reminder, from /groremind-list.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "groremind-list",
+			Description: "See your shopping reminders.",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "grotemplate-save",
+			Description: "Save what's on your grocery list as a template that you can add back later.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "The template's name, e.g. camping trip (saving over a template replaces its items).",
+					Required:    true,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grotemplate-apply",
+			Description: "Add a template's items to your grocery list (skipping what's already on there).",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "The template's name.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "grotemplate-list",
+			Description: "See your server's templates.",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "grotemplate-delete",
+			Description: "Delete a template.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "The template's name.",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "recipe-add",
+			Description: "Add a recipe's ingredients from your recipe book to your grocery list.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "The recipe's name.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "servings",
+					Description: "How many people you're cooking for - scales the ingredients' quantities to match.",
+					Required:    false,
+					MinValue:    &minRecipeServings,
+					MaxValue:    recipes.MaxRecipeServings,
+				},
+				defaults.DefaultListLabelOption,
+			},
+		},
+		{
+			Name:        "recipe-list",
+			Description: "See the recipes in your recipe book (save them from /ingredients).",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "recipe-show",
+			Description: "See a recipe's ingredients.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "The recipe's name.",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "recipe-delete",
+			Description: "Delete a recipe from your recipe book.",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "The recipe's name.",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
 		{
 			Name:        "grobulk",
 			Description: "Add multiple grocery entries to your list.",
@@ -184,8 +545,46 @@ var (
 		},
 		{
 			Name:        "developer",
-			Description: "Create a new API Client ID & Secret so that you can integrate directly with GroceryBot!",
+			Description: "Integrate directly with GroceryBot through its API & webhooks!",
 			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "api-client",
+					Description: "Create a new API Client ID & Secret so that you can integrate directly with GroceryBot!",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+				},
+				{
+					Name:        "webhook-add",
+					Description: "Get GroceryBot to POST to a URL of yours whenever your groceries change.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "The URL to send events to, e.g. https://example.com/grocerybot",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Name:        "webhook-list",
+					Description: "List your server's webhooks and how their latest deliveries went.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+				},
+				{
+					Name:        "webhook-remove",
+					Description: "Stop sending events to a webhook.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "id",
+							Description: "The ID of the webhook (see /developer webhook-list)",
+							Required:    true,
+						},
+					},
+				},
+			},
 		},
 		{
 			Name:        "config",
@@ -208,6 +607,18 @@ var (
 							Description: native.ContentUseGrobulkReplaceDescription,
 							Required:    false,
 						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "use_grolist_reactions",
+							Description: native.ContentUseGrolistReactionsDescription,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "timezone",
+							Description: native.ContentTimezoneDescription,
+							Required:    false,
+						},
 					},
 				},
 				{
@@ -215,6 +626,62 @@ var (
 					Description: "Get the current configuration for your server.",
 					Type:        discordgo.ApplicationCommandOptionSubCommand,
 				},
+				{
+					Name:        "category-set",
+					Description: "Always put entries containing a keyword into a category.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "keyword",
+							Description: native.ContentCategoryKeywordDescription,
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "category",
+							Description: "The category, e.g. Produce, Dairy & Eggs, Frozen, or one of your own.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Name:        "category-remove",
+					Description: "Remove a category override.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "keyword",
+							Description: "The keyword of the override to remove.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Name:        "permissions",
+					Description: "Choose which roles can do what.",
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Name:        "grant",
+							Description: "Let a role do something - once granted, only roles with it (and administrators) can do it.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options:     permissionOptions,
+						},
+						{
+							Name:        "revoke",
+							Description: "Take something away from a role.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options:     permissionOptions,
+						},
+						{
+							Name:        "list",
+							Description: "See which roles can do what.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+						},
+					},
+				},
 			},
 		},
 		{
@@ -248,6 +715,15 @@ var (
 		"gro": {
 			mainInputOptionKey: "entry",
 		},
+		"grocheck": {
+			mainInputOptionKey: "entry",
+		},
+		"grocheck-sweep": {
+			commandMappingOverride: "!grocheck",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "sweep", nil
+			},
+		},
 		"groedit": {
 			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
 				entryIndex := int64(-1)
@@ -299,6 +775,42 @@ var (
 				return "delete", nil
 			},
 		},
+		"grolist-visibility": {
+			commandMappingOverride: "!grolist",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				visibility := ""
+				who := ""
+				for _, o := range options {
+					switch o.Name {
+					case "visibility":
+						visibility = o.StringValue()
+					case "who":
+						who = o.StringValue()
+					}
+				}
+				if visibility == "" {
+					return "", ErrMissingSlashCommandOption
+				}
+				return strings.TrimSpace("visibility " + visibility + " " + who), nil
+			},
+		},
+		"grolist-share": {
+			commandMappingOverride: "!grolist",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "share", nil
+			},
+		},
+		"grolist-join": {
+			commandMappingOverride: "!grolist",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				for _, o := range options {
+					if o.Name == "code" {
+						return "join " + strings.TrimSpace(o.StringValue()), nil
+					}
+				}
+				return "", ErrMissingSlashCommandOption
+			},
+		},
 		"grolist": {
 			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
 				for _, o := range options {
@@ -319,6 +831,162 @@ var (
 				return "", nil
 			},
 		},
+		"grohistory": {
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				for _, o := range options {
+					if o.Name == "count" {
+						return strconv.FormatInt(o.IntValue(), 10), nil
+					}
+				}
+				return "", nil
+			},
+		},
+		"grostaple-add": {
+			commandMappingOverride: "!grostaple",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				entry, repeat, weekday, days := "", "", "", int64(0)
+				for _, o := range options {
+					switch o.Name {
+					case "entry":
+						entry = strings.TrimSpace(o.StringValue())
+					case "repeat":
+						repeat = o.StringValue()
+					case "weekday":
+						weekday = o.StringValue()
+					case "days":
+						days = o.IntValue()
+					}
+				}
+				if entry == "" {
+					return "", ErrMissingSlashCommandOption
+				}
+				switch repeat {
+				case models.GroceryStapleScheduleWeekly:
+					// !grostaple replies with some help if the weekday's missing
+					return fmt.Sprintf("add %s every %s", entry, weekday), nil
+				case models.GroceryStapleScheduleInterval:
+					return fmt.Sprintf("add %s every %d days", entry, days), nil
+				case models.GroceryStapleScheduleChecked:
+					return fmt.Sprintf("add %s when checked", entry), nil
+				}
+				return "", ErrMissingSlashCommandOption
+			},
+		},
+		"grostaple-remove": {
+			commandMappingOverride: "!grostaple",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				for _, o := range options {
+					if o.Name == "number" {
+						return "remove " + strconv.FormatInt(o.IntValue(), 10), nil
+					}
+				}
+				return "", ErrMissingSlashCommandOption
+			},
+		},
+		"grostaple-list": {
+			commandMappingOverride: "!grostaple",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "list", nil
+			},
+		},
+		"groremind-add": {
+			commandMappingOverride: "!groremind",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				when, channelID, dm := "", "", ""
+				for _, o := range options {
+					switch o.Name {
+					case "when":
+						when = strings.TrimSpace(o.StringValue())
+					case "channel":
+						channelID = o.ChannelValue(nil).ID
+					case "dm":
+						dm = strings.TrimSpace(o.StringValue())
+					}
+				}
+				if when == "" {
+					return "", ErrMissingSlashCommandOption
+				}
+				argStr = "add " + when
+				if channelID != "" {
+					argStr += fmt.Sprintf(" in <#%s>", channelID)
+				}
+				if dm != "" {
+					argStr += " dm " + dm
+				}
+				return argStr, nil
+			},
+		},
+		"groremind-remove": {
+			commandMappingOverride: "!groremind",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				for _, o := range options {
+					if o.Name == "number" {
+						return "remove " + strconv.FormatInt(o.IntValue(), 10), nil
+					}
+				}
+				return "", ErrMissingSlashCommandOption
+			},
+		},
+		"groremind-list": {
+			commandMappingOverride: "!groremind",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "list", nil
+			},
+		},
+		"grotemplate-save": {
+			commandMappingOverride: "!grotemplate",
+			customArgStrMarshaller: newNameArgStrMarshaller("save"),
+		},
+		"grotemplate-apply": {
+			commandMappingOverride: "!grotemplate",
+			customArgStrMarshaller: newNameArgStrMarshaller("apply"),
+		},
+		"grotemplate-list": {
+			commandMappingOverride: "!grotemplate",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "list", nil
+			},
+		},
+		"grotemplate-delete": {
+			commandMappingOverride: "!grotemplate",
+			customArgStrMarshaller: newNameArgStrMarshaller("delete"),
+		},
+		"recipe-add": {
+			commandMappingOverride: "!grorecipe",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				name := ""
+				servings := int64(0)
+				for _, o := range options {
+					switch o.Name {
+					case "name":
+						name = strings.TrimSpace(o.StringValue())
+					case "servings":
+						servings = o.IntValue()
+					}
+				}
+				if name == "" {
+					return "", ErrMissingSlashCommandOption
+				}
+				if servings > 0 {
+					return fmt.Sprintf("add %s %d", name, servings), nil
+				}
+				return "add " + name, nil
+			},
+		},
+		"recipe-list": {
+			commandMappingOverride: "!grorecipe",
+			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+				return "list", nil
+			},
+		},
+		"recipe-show": {
+			commandMappingOverride: "!grorecipe",
+			customArgStrMarshaller: newNameArgStrMarshaller("show"),
+		},
+		"recipe-delete": {
+			commandMappingOverride: "!grorecipe",
+			customArgStrMarshaller: newNameArgStrMarshaller("delete"),
+		},
 		"gropatron": {
 			customArgStrMarshaller: func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
 				for _, o := range options {
@@ -332,6 +1000,18 @@ var (
 	}
 )
 
+// newNameArgStrMarshaller maps commands that take a name, e.g. /grotemplate-save name:camping trip to !grotemplate save camping trip
+func newNameArgStrMarshaller(subCmd string) argStrMarshaller {
+	return func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
+		for _, o := range options {
+			if o.Name == "name" {
+				return subCmd + " " + strings.TrimSpace(o.StringValue()), nil
+			}
+		}
+		return "", ErrMissingSlashCommandOption
+	}
+}
+
 var defaultSlashCommandArgStrMarshaller argStrMarshaller = func(options []*discordgo.ApplicationCommandInteractionDataOption, commandMetadata *slashCommandHandlerMetadata) (argStr string, err error) {
 	if commandMetadata.mainInputOptionKey == "" {
 		return "", ErrMissingOptionKeyForDefaultMarshaller
@@ -402,6 +1082,8 @@ func getMessageCommandContext(i *discordgo.InteractionCreate, commandName string
 		ChannelID:                   i.ChannelID,
 		CommandSourceType:           handlers.CommandSourceSlashCommand,
 		Interaction:                 i.Interaction,
+		AuthorRoleIDs:               i.Member.Roles,
+		AuthorPermissions:           &i.Member.Permissions,
 		AuthorID:                    i.Member.User.ID,
 		AuthorUsername:              i.Member.User.Username,
 		AuthorUsernameDiscriminator: i.Member.User.Discriminator,
@@ -418,6 +1100,14 @@ func getCommandContext(i *discordgo.InteractionCreate, commandName string, db *g
 	}
 }
 
+// getCommandContexts returns where the command can be used - most commands work in DMs too, on the user's personal grocery lists (see models.PersonalGuildID)
+func getCommandContexts(commandName string) *[]discordgo.InteractionContextType {
+	if guildOnlySlashCommands[commandName] {
+		return &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild}
+	}
+	return &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild, discordgo.InteractionContextBotDM}
+}
+
 func registerForGuild(sess *discordgo.Session, logger *zap.Logger, targetGuildID string, commands []*discordgo.ApplicationCommand) ([]*discordgo.ApplicationCommand, error) {
 	loopLog := logger.With(zap.String("TargetGuildID", targetGuildID)).Named("registration")
 	createdCommands, err := sess.ApplicationCommandBulkOverwrite(sess.State.User.ID, targetGuildID, commands)
@@ -447,6 +1137,7 @@ func Register(sess *discordgo.Session, db *gorm.DB, logger *zap.Logger, grobotVe
 	commandsToRegister := make([]*discordgo.ApplicationCommand, 0, len(commands))
 	commandsToRegisterForGuild := map[string][]*discordgo.ApplicationCommand{}
 	for _, cmd := range commands {
+		cmd.Contexts = getCommandContexts(cmd.Name)
 		if _, ok := ignoredSlashCommands[cmd.Name]; !ok {
 			commandsToRegister = append(commandsToRegister, cmd)
 		}
@@ -491,19 +1182,23 @@ func Register(sess *discordgo.Session, db *gorm.DB, logger *zap.Logger, grobotVe
 
 		// check if it's a DM interaction
 		if i.Member == nil {
-			logger := logger.Named("dm-interaction")
-			if i.User != nil {
-				logger = logger.With(zap.String("userID", i.User.ID))
+			if i.User == nil {
+				return
 			}
-			if err := sess.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "I do not currently support commands through my DM. Please put me in a server and I'll work magic for you!",
-				},
-			}); err != nil {
-				logger.Error("Cannot respond to DM interaction.", zap.Error(err))
+			if guildOnlySlashCommands[getCommandName(i)] {
+				if err := sess.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: "Sorry, this command only works in a server. Put me in one and I'll work magic for you!",
+					},
+				}); err != nil {
+					logger.Named("dm-interaction").Error("Cannot respond to DM interaction.", zap.String("userID", i.User.ID), zap.Error(err))
+				}
+				return
 			}
-			return
+			// DMs work on the user's personal grocery lists, which are handled as if they were a server of their own
+			i.GuildID = models.PersonalGuildID(i.User.ID)
+			i.Member = &discordgo.Member{User: i.User}
 		}
 		logger := logger.With(zap.String("GuildID", i.GuildID))
 
@@ -1,13 +1,17 @@
 package modal
 
 import (
+	"context"
 	"errors"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
 	"github.com/verzac/grocer-discord-bot/handlers"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -19,20 +23,27 @@ var (
 
 var (
 	modalCommandHandlers = map[string]func(c *ModalCreationContext) (*discordgo.InteractionResponseData, error){
-		"grobulk":   handleGrobulkCommand,
-		"groremove": handleGroremoveCommand,
+		"grobulk":                       handleGrobulkCommand,
+		"groremove":                     handleGroremoveCommand,
+		groceryutils.CustomIDGrohereAdd: handleGrohereAddCommand,
+	}
+	// checked before the modal is opened, so that nobody fills one in for nothing (submissions are checked again by MessageHandlerContext.Handle)
+	modalCommandCapabilities = map[string]string{
+		"grobulk":                       models.CapabilityAdd,
+		"groremove":                     models.CapabilityRemove,
+		groceryutils.CustomIDGrohereAdd: models.CapabilityAdd,
 	}
 	commandContextGetters = map[string]func(i *discordgo.InteractionCreate, commandName string, groceryListRepo repositories.GroceryListRepository) (*handlers.CommandContext, error){
-		"grobulk":   getGrobulkCommandContext,
-		"groremove": getGroremoveCommandContext,
+		"grobulk":                       getGrobulkCommandContext,
+		"groremove":                     getGroremoveCommandContext,
+		groceryutils.CustomIDGrohereAdd: getGrohereAddCommandContext,
 	}
 )
 
+// modalSubmitGetterKey strips the suffix of custom IDs such as "groremove:<list ID>"
 func modalSubmitGetterKey(customID string) string {
-	if customID == "groremove" || strings.HasPrefix(customID, "groremove:") {
-		return "groremove"
-	}
-	return customID
+	key, _, _ := strings.Cut(customID, ":")
+	return key
 }
 
 func GetCommandContextFromModalSubmission(i *discordgo.InteractionCreate, commandName string, groceryListRepo repositories.GroceryListRepository) (*handlers.CommandContext, error) {
@@ -63,6 +74,9 @@ func (c *ModalCreationContext) Handle() {
 	switch c.interaction.Type {
 	default:
 		if handler, ok := modalCommandHandlers[c.commandName]; ok {
+			if !c.checkCapability() {
+				return
+			}
 			data, err := handler(c)
 			if err != nil {
 				if errors.Is(err, ErrAlreadyHandled) {
@@ -84,6 +98,32 @@ func (c *ModalCreationContext) Handle() {
 	}
 }
 
+// checkCapability responds with a message instead of the modal if the member doesn't have the capability to use it. Returns true if they do.
+func (c *ModalCreationContext) checkCapability() bool {
+	capability, ok := modalCommandCapabilities[c.commandName]
+	if !ok {
+		return true
+	}
+	member := c.interaction.Member
+	hasCapability, err := guildconfig.Service.HasCapability(context.Background(), &dto.MemberContext{
+		GuildID:     c.guildID,
+		ChannelID:   c.interaction.ChannelID,
+		UserID:      c.authorID,
+		RoleIDs:     member.Roles,
+		Permissions: &member.Permissions,
+	}, capability)
+	if err != nil {
+		c.logger.Error("Unable to check capability.", zap.Error(err))
+		return false
+	}
+	if !hasCapability {
+		if err := c.RespondWithMessageInsteadOfModal(guildconfig.FmtCapabilityDeniedMessage(capability)); err != nil && !errors.Is(err, ErrAlreadyHandled) {
+			c.logger.Error("Unable to respond to interaction.", zap.Error(err))
+		}
+	}
+	return hasCapability
+}
+
 func (c *ModalCreationContext) getConfig() (*models.GuildConfig, error) {
 	if c.cachedConfig != nil {
 		return c.cachedConfig, nil
@@ -131,6 +171,11 @@ func NewModalCreationContext(sess *discordgo.Session, db *gorm.DB, logger *zap.L
 				break
 			}
 		}
+	case discordgo.InteractionMessageComponent:
+		// buttons that open modals, e.g. "grohere_add:<scope>"
+		commandKey, _, _ = strings.Cut(i.MessageComponentData().CustomID, ":")
+		_, isHandleable = modalCommandHandlers[commandKey]
+		isHandleable = isHandleable && i.Member != nil
 	}
 	if !isHandleable {
 		return nil
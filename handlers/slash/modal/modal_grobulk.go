@@ -12,7 +12,7 @@ import (
 func getGrobulkInputFromEntries(groceryEntries []models.GroceryEntry) string {
 	msg := ""
 	for _, grocery := range groceryEntries {
-		msg += fmt.Sprintf("%s\n", grocery.ItemDesc)
+		msg += fmt.Sprintf("%s\n", grocery.GetDisplayText())
 	}
 	return msg
 }
@@ -33,6 +33,8 @@ func getGrobulkCommandContext(i *discordgo.InteractionCreate, commandName string
 		AuthorUsernameDiscriminator: i.Member.User.Discriminator,
 		CommandSourceType:           handlers.CommandSourceSlashCommand,
 		Interaction:                 i.Interaction,
+		AuthorRoleIDs:               i.Member.Roles,
+		AuthorPermissions:           &i.Member.Permissions,
 	}, nil
 }
 
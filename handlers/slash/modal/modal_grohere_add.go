@@ -0,0 +1,104 @@
+package modal
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/utils"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+const (
+	grohereAddItemInputCustomID = "item"
+	discordModalTitleMaxLength  = 45
+)
+
+var (
+	errGrohereAddListNotFound = errors.New("grocery list not found for modal custom_id")
+)
+
+// getGrohereAddListLabel returns the label of the grocery list that the "Add item" button of a !grohere message adds to.
+// !grohere all adds to the default list.
+func getGrohereAddListLabel(customID string, guildID string) (string, error) {
+	scope := strings.TrimPrefix(customID, groceryutils.CustomIDGrohereAdd+":")
+	if scope == groceryutils.GrohereScopeAll || scope == "0" {
+		return "", nil
+	}
+	listID, err := strconv.ParseUint(scope, 10, 32)
+	if err != nil {
+		return "", err
+	}
+	gl, err := grocery.Service.GetGuildGroceryList(context.Background(), guildID, &models.GroceryList{ID: uint(listID)})
+	if err != nil {
+		return "", err
+	}
+	if gl == nil {
+		return "", errGrohereAddListNotFound
+	}
+	return gl.ListLabel, nil
+}
+
+func getGrohereAddCommandContext(i *discordgo.InteractionCreate, commandName string, groceryListRepo repositories.GroceryListRepository) (*handlers.CommandContext, error) {
+	data := i.ModalSubmitData()
+	grocerySublist, err := getGrohereAddListLabel(commandName, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	argStr := data.Components[0].(*discordgo.ActionsRow).
+		Components[0].(*discordgo.TextInput).
+		Value
+	return &handlers.CommandContext{
+		Command:                     handlers.CmdGroAdd,
+		GrocerySublist:              grocerySublist,
+		ArgStr:                      strings.TrimSpace(argStr),
+		GuildID:                     i.GuildID,
+		AuthorID:                    i.Member.User.ID,
+		ChannelID:                   i.ChannelID,
+		AuthorUsername:              i.Member.User.Username,
+		AuthorUsernameDiscriminator: i.Member.User.Discriminator,
+		CommandSourceType:           handlers.CommandSourceSlashCommand,
+		Interaction:                 i.Interaction,
+		AuthorRoleIDs:               i.Member.Roles,
+		AuthorPermissions:           &i.Member.Permissions,
+	}, nil
+}
+
+// handleGrohereAddCommand opens the modal for the "Add item" button of !grohere messages
+func handleGrohereAddCommand(c *ModalCreationContext) (*discordgo.InteractionResponseData, error) {
+	customID := c.interaction.MessageComponentData().CustomID
+	listLabel, err := getGrohereAddListLabel(customID, c.guildID)
+	if errors.Is(err, errGrohereAddListNotFound) {
+		return nil, c.RespondWithMessageInsteadOfModal("Sorry, this grocery list doesn't exist anymore.")
+	}
+	if err != nil {
+		return nil, err
+	}
+	title := "Add an item"
+	if listLabel != "" {
+		title = utils.TruncateStringWithTargetLength("Add an item to "+listLabel, discordModalTitleMaxLength)
+	}
+	return &discordgo.InteractionResponseData{
+		CustomID: customID,
+		Title:    title,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    grohereAddItemInputCustomID,
+						Label:       "Item",
+						Placeholder: "e.g. 2 cartons of milk",
+						Required:    true,
+						Style:       discordgo.TextInputShort,
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -1,6 +1,7 @@
 package modal
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/verzac/grocer-discord-bot/handlers/slash/defaults"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
 	"github.com/verzac/grocer-discord-bot/utils"
 )
 
@@ -62,7 +64,7 @@ func buildGroremoveModalComponents(groceries []models.GroceryEntry, preselected
 			value := strconv.Itoa(absIdx + 1)
 			def := false
 			opt := discordgo.CheckboxGroupOption{
-				Label:   groremoveCheckboxOptionLabel(absIdx+1, g.ItemDesc),
+				Label:   groremoveCheckboxOptionLabel(absIdx+1, g.GetDisplayText()),
 				Value:   value,
 				Default: &def,
 			}
@@ -112,7 +114,7 @@ func getGroremoveCommandContext(i *discordgo.InteractionCreate, commandName stri
 		if err != nil {
 			return nil, err
 		}
-		gl, err := groceryListRepo.GetByQuery(&models.GroceryList{ID: uint(listID), GuildID: i.GuildID})
+		gl, err := grocery.Service.GetGuildGroceryList(context.Background(), i.GuildID, &models.GroceryList{ID: uint(listID)})
 		if err != nil {
 			return nil, err
 		}
@@ -132,6 +134,8 @@ func getGroremoveCommandContext(i *discordgo.InteractionCreate, commandName stri
 		AuthorUsernameDiscriminator: i.Member.User.Discriminator,
 		CommandSourceType:           handlers.CommandSourceSlashCommand,
 		Interaction:                 i.Interaction,
+		AuthorRoleIDs:               i.Member.Roles,
+		AuthorPermissions:           &i.Member.Permissions,
 	}, nil
 }
 
@@ -145,7 +149,7 @@ func handleGroremoveCommand(c *ModalCreationContext) (*discordgo.InteractionResp
 	var groceryList *models.GroceryList
 
 	if listLabel != "" {
-		gl, err := c.groceryListRepository.GetByQuery(&models.GroceryList{GuildID: c.guildID, ListLabel: listLabel})
+		gl, err := grocery.Service.GetGuildGroceryList(context.Background(), c.guildID, &models.GroceryList{ListLabel: listLabel})
 		if err != nil {
 			return nil, err
 		}
@@ -153,6 +157,8 @@ func handleGroremoveCommand(c *ModalCreationContext) (*discordgo.InteractionResp
 			return nil, c.RespondWithMessageInsteadOfModal(fmt.Sprintf("Whoops, I can't seem to find the grocery list labeled as *%s*.", listLabel))
 		}
 		groceryList = gl
+		// a shared list's groceries belong to the guild that owns it
+		query.GuildID = gl.GuildID
 		query.GroceryListID = &gl.ID
 		modalCustomID = fmt.Sprintf("groremove:%d", gl.ID)
 	} else {
@@ -1,13 +1,18 @@
 package native
 
 import (
+	"context"
 	"errors"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/handlers"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
 	"github.com/verzac/grocer-discord-bot/utils"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -17,19 +22,23 @@ var (
 )
 
 type NativeSlashHandlingContext struct {
-	s                     *discordgo.Session
-	i                     *discordgo.InteractionCreate
-	apiClientRepository   repositories.ApiClientRepository
-	waitlistIosRepository repositories.WaitlistIosRepository
-	logger                *zap.Logger
-	replyCount            int
-	guildConfigRepository repositories.GuildConfigRepository
-	cachedConfig          *models.GuildConfig
-	customIDSuffix        string
+	s                      *discordgo.Session
+	i                      *discordgo.InteractionCreate
+	apiClientRepository    repositories.ApiClientRepository
+	waitlistIosRepository  repositories.WaitlistIosRepository
+	groceryEntryRepository repositories.GroceryEntryRepository
+	groceryListRepository  repositories.GroceryListRepository
+	logger                 *zap.Logger
+	replyCount             int
+	guildConfigRepository  repositories.GuildConfigRepository
+	cachedConfig           *models.GuildConfig
+	customIDSuffix         string
 }
 
 type replyOptions struct {
-	IsPrivate bool
+	IsPrivate       bool
+	Components      []discordgo.MessageComponent
+	AllowedMentions *discordgo.MessageAllowedMentions
 }
 
 func (c *NativeSlashHandlingContext) replyWithOption(msg string, replyOptions replyOptions) error {
@@ -48,8 +57,10 @@ func (c *NativeSlashHandlingContext) replyWithOption(msg string, replyOptions re
 	return c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: msg,
-			Flags:   flags,
+			Content:         msg,
+			Flags:           flags,
+			Components:      replyOptions.Components,
+			AllowedMentions: replyOptions.AllowedMentions,
 		},
 	})
 }
@@ -80,22 +91,95 @@ func (c *NativeSlashHandlingContext) getConfig() (*models.GuildConfig, error) {
 	return config, nil
 }
 
+// pendingKeyMatchesGuild checks that a pending cache key (formatted as "guildID:uuid") belongs to the guild
+func pendingKeyMatchesGuild(cacheKey, guildID string) bool {
+	g, ok := models.GuildIDFromKey(cacheKey)
+	return ok && g == guildID
+}
+
+func respondComponentError(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: msg,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// getMemberContext must only be called for interactions that come from a guild (i.e. c.i.Member is set).
+func (c *NativeSlashHandlingContext) getMemberContext() *dto.MemberContext {
+	return &dto.MemberContext{
+		GuildID:     c.i.GuildID,
+		ChannelID:   c.i.ChannelID,
+		UserID:      c.i.Member.User.ID,
+		RoleIDs:     c.i.Member.Roles,
+		Permissions: &c.i.Member.Permissions,
+	}
+}
+
+// checkCapability replies (privately) that the member can't do it if they don't have the capability. Returns true if they do.
+func (c *NativeSlashHandlingContext) checkCapability(capability string) bool {
+	if c.i.Member == nil {
+		// handlers reply to DMs themselves
+		return true
+	}
+	ok, err := guildconfig.Service.HasCapability(context.Background(), c.getMemberContext(), capability)
+	if err != nil {
+		c.onError(err)
+		return false
+	}
+	if !ok {
+		if err := c.replyWithOption(guildconfig.FmtCapabilityDeniedMessage(capability), replyOptions{IsPrivate: true}); err != nil {
+			c.logger.Error("Failed to reply that the member is missing a capability.", zap.Error(err))
+		}
+	}
+	return ok
+}
+
 // NativeSlashHandler are functions that are responsible for handling response and replies fully
 type NativeSlashHandler = func(c *NativeSlashHandlingContext)
 
 var (
 	nativeSlashHandlerMap = map[string]NativeSlashHandler{
-		"developer":               handleDeveloper,
-		"generate_new_api_client": handleDeveloperCreateNewApiClient,
-		"config":                  handleConfig,
-		"ingredients":             handleIngredients,
-		"ingredients_confirm":     handleIngredientsConfirm,
-		"ingredients_cancel":      handleIngredientsCancel,
-		"waitlist":                handleWaitlistIos,
-		waitlistIosModalCustomID:  handleWaitlistIosSubmit,
+		"developer":                         handleDeveloper,
+		"generate_new_api_client":           handleDeveloperCreateNewApiClient,
+		"config":                            handleConfig,
+		"ingredients":                       handleIngredients,
+		"ingredients_confirm":               handleIngredientsConfirm,
+		"ingredients_cancel":                handleIngredientsCancel,
+		ingredientsEditCustomID:             handleIngredientsEdit,
+		ingredientsEditSubmitCustomID:       handleIngredientsEditSubmit,
+		ingredientsSaveCustomID:             handleIngredientsSave,
+		ingredientsSaveSubmitCustomID:       handleIngredientsSaveSubmit,
+		handlers.CustomIDGroAddAnyway:       handleGroAddAnyway,
+		handlers.CustomIDGroAddCancel:       handleGroAddCancel,
+		handlers.CustomIDGroUndo:            handleGroUndo,
+		handlers.CustomIDGrolistPage:        handleGrolistPage,
+		groceryutils.CustomIDGrohereCheck:   handleGrohereCheck,
+		groceryutils.CustomIDGrohereSweep:   handleGrohereSweep,
+		groceryutils.CustomIDGrohereRefresh: handleGrohereRefresh,
+		"waitlist":                          handleWaitlistIos,
+		waitlistIosModalCustomID:            handleWaitlistIosSubmit,
 	}
 )
 
+// nativeSlashHandlerCapabilities are checked before running the handler of the same key (see /config permissions)
+var nativeSlashHandlerCapabilities = map[string]string{
+	"developer":                       models.CapabilityApiClients,
+	"generate_new_api_client":         models.CapabilityApiClients,
+	"config":                          models.CapabilityConfigure,
+	"ingredients_confirm":             models.CapabilityAdd,
+	ingredientsEditCustomID:           models.CapabilityAdd,
+	ingredientsEditSubmitCustomID:     models.CapabilityAdd,
+	ingredientsSaveCustomID:           models.CapabilityManageLists,
+	ingredientsSaveSubmitCustomID:     models.CapabilityManageLists,
+	handlers.CustomIDGroAddAnyway:     models.CapabilityAdd,
+	handlers.CustomIDGroUndo:          models.CapabilityRemove,
+	groceryutils.CustomIDGrohereCheck: models.CapabilityAdd,
+	groceryutils.CustomIDGrohereSweep: models.CapabilityRemove,
+}
+
 type NativeSlashHandlingParams struct {
 	Session           *discordgo.Session
 	InteractionCreate *discordgo.InteractionCreate
@@ -116,13 +200,18 @@ func Handle(p NativeSlashHandlingParams) bool {
 		return false
 	}
 	ctx := &NativeSlashHandlingContext{
-		s:                     p.Session,
-		i:                     p.InteractionCreate,
-		apiClientRepository:   &repositories.ApiClientRepositoryImpl{DB: p.DB},
-		waitlistIosRepository: &repositories.WaitlistIosRepositoryImpl{DB: p.DB},
-		guildConfigRepository: &repositories.GuildConfigRepositoryImpl{DB: p.DB},
-		logger:                p.Logger.Named("native"),
-		customIDSuffix:        suffix,
+		s:                      p.Session,
+		i:                      p.InteractionCreate,
+		apiClientRepository:    &repositories.ApiClientRepositoryImpl{DB: p.DB},
+		waitlistIosRepository:  &repositories.WaitlistIosRepositoryImpl{DB: p.DB},
+		groceryEntryRepository: &repositories.GroceryEntryRepositoryImpl{DB: p.DB},
+		groceryListRepository:  &repositories.GroceryListRepositoryImpl{DB: p.DB},
+		guildConfigRepository:  &repositories.GuildConfigRepositoryImpl{DB: p.DB},
+		logger:                 p.Logger.Named("native"),
+		customIDSuffix:         suffix,
+	}
+	if capability, ok := nativeSlashHandlerCapabilities[handlerKey]; ok && !ctx.checkCapability(capability) {
+		return true
 	}
 	handler(ctx)
 	return true
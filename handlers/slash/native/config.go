@@ -1,17 +1,25 @@
 package native
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/reminders"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 )
 
 const (
-	ContentUseEphemeralDescription      = "Enable ephemeral message replies from GroceryBot, which are only visible to you and will disappear."
-	ContentUseGrobulkReplaceDescription = "If enabled, using /grobulk replaces the existing items in your list instead of adding new ones."
+	ContentUseEphemeralDescription        = "Enable ephemeral message replies from GroceryBot, which are only visible to you and will disappear."
+	ContentUseGrobulkReplaceDescription   = "If enabled, using /grobulk replaces the existing items in your list instead of adding new ones."
+	ContentUseGrolistReactionsDescription = "If enabled, react to the numbers under a !grolist reply to check items off (unreact to uncheck)."
+	ContentCategoryKeywordDescription     = "Entries containing this word/phrase will be put into the category (e.g. oat milk)."
+	ContentTimezoneDescription            = "Your server's timezone for /groremind, e.g. Australia/Sydney or America/New_York."
 )
 
 var handleConfig NativeSlashHandler = func(c *NativeSlashHandlingContext) {
@@ -21,15 +29,13 @@ var handleConfig NativeSlashHandler = func(c *NativeSlashHandlingContext) {
 		}
 		return
 	}
-	userPermissions := c.i.Member.Permissions
-	if userPermissions&discordgo.PermissionAdministrator != discordgo.PermissionAdministrator {
-		if err := c.reply("This command can only be used by people with the Administrator permission in your server."); err != nil {
-			c.onError(err)
-		}
-		return
-	}
+	// permissions are checked by Handle (see models.CapabilityConfigure)
 	guildID := c.i.GuildID
 	options := c.i.ApplicationCommandData().Options
+	if len(options) == 1 && options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup && options[0].Name == "permissions" {
+		handlePermissions(c, options[0])
+		return
+	}
 	if len(options) != 1 || options[0].Type != discordgo.ApplicationCommandOptionSubCommand {
 		c.onError(errMissingSubcommand)
 		return
@@ -58,6 +64,10 @@ var handleConfig NativeSlashHandler = func(c *NativeSlashHandlingContext) {
 		setConfig(c, config, optionNameToOptionsMapping)
 	case "get":
 		getConfig(c, config)
+	case "category-set":
+		setCategoryOverride(c, optionNameToOptionsMapping)
+	case "category-remove":
+		removeCategoryOverride(c, optionNameToOptionsMapping)
 	default:
 		c.onError(errors.New("unknown subcommand"))
 		return
@@ -77,9 +87,25 @@ func getConfig(c *NativeSlashHandlingContext, config *models.GuildConfig) {
 # 🔨 Configuration
 - **Use ephemeral**: %s - %s
 - **Use grobulk replace**: %s - %s
+- **Use grolist reactions**: %s - %s
+- **Timezone**: %s - %s
 `,
 		enabledStr(config.UseEphemeral), ContentUseEphemeralDescription,
-		enabledStr(!config.UseGrobulkAppend), ContentUseGrobulkReplaceDescription)
+		enabledStr(!config.UseGrobulkAppend), ContentUseGrobulkReplaceDescription,
+		enabledStr(config.UseGrolistReactions), ContentUseGrolistReactionsDescription,
+		config.GetLocation().String(), ContentTimezoneDescription)
+
+	overrides, err := c.guildConfigRepository.FindCategoryOverrides(context.Background(), config.GuildID)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	if len(overrides) > 0 {
+		message += "\n## 🏷️ Category overrides\n"
+		for _, o := range overrides {
+			message += fmt.Sprintf("- **%s** → %s\n", o.Keyword, o.Category)
+		}
+	}
 
 	if err := c.reply(strings.TrimSpace(message)); err != nil {
 		c.onError(err)
@@ -106,11 +132,39 @@ func setConfig(c *NativeSlashHandlingContext, existingConfig *models.GuildConfig
 		addToUpdatedSettings("Use grobulk replace", !newValue) // note that the user sees the reverse
 	}
 
+	if useGrolistReactions, ok := optionNameToOptionsMapping["use_grolist_reactions"]; ok && useGrolistReactions != nil {
+		newValue := useGrolistReactions.BoolValue()
+		newConfig.UseGrolistReactions = newValue
+		addToUpdatedSettings("Use grolist reactions", newValue)
+	}
+
+	timezoneChanged := false
+	if timezone, ok := optionNameToOptionsMapping["timezone"]; ok && timezone != nil {
+		newValue := strings.TrimSpace(timezone.StringValue())
+		if _, err := time.LoadLocation(newValue); err != nil || newValue == "" || newValue == "Local" {
+			if err := c.reply(fmt.Sprintf("Hmm, I don't know the timezone *%s* - try one like `Australia/Sydney` or `America/New_York` (see https://en.wikipedia.org/wiki/List_of_tz_database_time_zones).", newValue)); err != nil {
+				c.onError(err)
+			}
+			return
+		}
+		timezoneChanged = newValue != existingConfig.Timezone
+		newConfig.Timezone = newValue
+		updatedSettings = append(updatedSettings, fmt.Sprintf("- **Timezone**: %s", newValue))
+	}
+
 	// save
 	if err := c.guildConfigRepository.Put(&newConfig); err != nil {
 		c.onError(err)
 		return
 	}
+	if timezoneChanged {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := reminders.Service.RescheduleReminders(ctx, newConfig.GuildID); err != nil {
+			c.onError(err)
+			return
+		}
+	}
 
 	// reply with specific changes
 	if len(updatedSettings) == 0 {
@@ -124,3 +178,190 @@ func setConfig(c *NativeSlashHandlingContext, existingConfig *models.GuildConfig
 		}
 	}
 }
+
+func setCategoryOverride(c *NativeSlashHandlingContext, optionNameToOptionsMapping map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	keywordOption, hasKeyword := optionNameToOptionsMapping["keyword"]
+	categoryOption, hasCategory := optionNameToOptionsMapping["category"]
+	if !hasKeyword || !hasCategory || keywordOption == nil || categoryOption == nil {
+		c.onError(errors.New("missing keyword or category option"))
+		return
+	}
+	keyword, category := groceryutils.NormalizeItemDesc(keywordOption.StringValue()), strings.TrimSpace(categoryOption.StringValue())
+	if keyword == "" || category == "" {
+		if err := c.reply("Sorry, I need both a keyword and a category (e.g. `/config category-set keyword:oat milk category:Dairy & Eggs`)."); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	updatedCount, err := grocery.Service.SetCategoryOverride(ctx, c.i.GuildID, keyword, category, c.i.Member.User.ID)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	message := fmt.Sprintf("✅ Entries containing **%s** will now go into **%s**.", keyword, groceryutils.CanonicalCategory(category))
+	if updatedCount > 0 {
+		message += fmt.Sprintf(" I've also moved %d existing entries into it.", updatedCount)
+	}
+	if err := c.reply(message); err != nil {
+		c.onError(err)
+	}
+}
+
+func removeCategoryOverride(c *NativeSlashHandlingContext, optionNameToOptionsMapping map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	keywordOption, ok := optionNameToOptionsMapping["keyword"]
+	if !ok || keywordOption == nil {
+		c.onError(errors.New("missing keyword option"))
+		return
+	}
+	keyword := groceryutils.NormalizeItemDesc(keywordOption.StringValue())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	removed, err := grocery.Service.RemoveCategoryOverride(ctx, c.i.GuildID, keyword, c.i.Member.User.ID)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	message := fmt.Sprintf("✅ Removed the category override for **%s**.", keyword)
+	if !removed {
+		message = fmt.Sprintf("Hmm, there's no category override for **%s**. Use `/config get` to see them all.", keyword)
+	}
+	if err := c.reply(message); err != nil {
+		c.onError(err)
+	}
+}
+
+// handlePermissions handles /config permissions. Only administrators can grant & revoke, so that nobody can give themselves more than they have.
+func handlePermissions(c *NativeSlashHandlingContext, group *discordgo.ApplicationCommandInteractionDataOption) {
+	if len(group.Options) != 1 || group.Options[0].Type != discordgo.ApplicationCommandOptionSubCommand {
+		c.onError(errMissingSubcommand)
+		return
+	}
+	subCommand := group.Options[0]
+	optionNameToOptionsMapping := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subCommand.Options))
+	for _, option := range subCommand.Options {
+		optionNameToOptionsMapping[option.Name] = option
+	}
+	if subCommand.Name == "list" {
+		listPermissions(c)
+		return
+	}
+	if c.i.Member.Permissions&discordgo.PermissionAdministrator != discordgo.PermissionAdministrator {
+		if err := c.reply("Only people with the Administrator permission in your server can change who's allowed to do what."); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	capabilityOption, hasCapability := optionNameToOptionsMapping["capability"]
+	roleOption, hasRole := optionNameToOptionsMapping["role"]
+	if !hasCapability || !hasRole || capabilityOption == nil || roleOption == nil {
+		c.onError(errors.New("missing capability or role option"))
+		return
+	}
+	capability := capabilityOption.StringValue()
+	if _, ok := models.CapabilityDescriptions[capability]; !ok {
+		c.onError(fmt.Errorf("unknown capability: %s", capability))
+		return
+	}
+	roleID := roleOption.RoleValue(nil, "").ID
+	switch subCommand.Name {
+	case "grant":
+		grantPermission(c, capability, roleID)
+	case "revoke":
+		revokePermission(c, capability, roleID)
+	default:
+		c.onError(errors.New("unknown subcommand"))
+	}
+}
+
+// permissionHolders describes who has the capability when it hasn't been granted to any role
+func permissionHolders(capability string) string {
+	if models.IsAdministratorOnlyByDefault(capability) {
+		return "only administrators"
+	}
+	return "everyone"
+}
+
+func (c *NativeSlashHandlingContext) replyWithoutMentions(msg string) error {
+	return c.replyWithOption(msg, replyOptions{
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			// role mentions are only there to show which role it is
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+}
+
+func grantPermission(c *NativeSlashHandlingContext, capability string, roleID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.guildConfigRepository.PutRolePermission(ctx, &models.GuildRolePermission{
+		GuildID:    c.i.GuildID,
+		RoleID:     roleID,
+		Capability: capability,
+	}); err != nil {
+		c.onError(err)
+		return
+	}
+	message := fmt.Sprintf(
+		"✅ <@&%s> can now %s.\n\nOnly members with a role that has **%s** (and administrators) can do that now - see `/config permissions list`.",
+		roleID, models.CapabilityDescriptions[capability], capability,
+	)
+	if err := c.replyWithoutMentions(message); err != nil {
+		c.onError(err)
+	}
+}
+
+func revokePermission(c *NativeSlashHandlingContext, capability string, roleID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	removed, err := c.guildConfigRepository.DeleteRolePermission(ctx, c.i.GuildID, roleID, capability)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	if removed == 0 {
+		if err := c.replyWithoutMentions(fmt.Sprintf("Hmm, <@&%s> doesn't have **%s**. Use `/config permissions list` to see who does.", roleID, capability)); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	message := fmt.Sprintf("✅ <@&%s> no longer has **%s**.", roleID, capability)
+	remaining, err := c.guildConfigRepository.FindRolePermissions(ctx, c.i.GuildID, capability)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	if len(remaining) == 0 {
+		message += fmt.Sprintf(" Since no role has it any more, %s can %s.", permissionHolders(capability), models.CapabilityDescriptions[capability])
+	}
+	if err := c.replyWithoutMentions(message); err != nil {
+		c.onError(err)
+	}
+}
+
+func listPermissions(c *NativeSlashHandlingContext) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	permissions, err := c.guildConfigRepository.FindRolePermissions(ctx, c.i.GuildID, "")
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	roleMentionsByCapability := make(map[string][]string, len(models.Capabilities))
+	for _, p := range permissions {
+		roleMentionsByCapability[p.Capability] = append(roleMentionsByCapability[p.Capability], fmt.Sprintf("<@&%s>", p.RoleID))
+	}
+	var sb strings.Builder
+	sb.WriteString("# 🔐 Permissions\nAdministrators can always do everything.\n")
+	for _, capability := range models.Capabilities {
+		holders := permissionHolders(capability)
+		if roleMentions := roleMentionsByCapability[capability]; len(roleMentions) > 0 {
+			holders = strings.Join(roleMentions, ", ")
+		}
+		sb.WriteString(fmt.Sprintf("- **%s** (%s): %s\n", capability, models.CapabilityDescriptions[capability], holders))
+	}
+	if err := c.replyWithoutMentions(strings.TrimSpace(sb.String())); err != nil {
+		c.onError(err)
+	}
+}
@@ -2,6 +2,7 @@ package native
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
@@ -19,14 +20,33 @@ var handleDeveloper NativeSlashHandler = func(c *NativeSlashHandlingContext) {
 		}
 		return
 	}
-	// check for perms
-	userPermissions := c.i.Member.Permissions
-	if userPermissions&discordgo.PermissionAdministrator != discordgo.PermissionAdministrator {
-		if err := c.reply("This command can only be used by people with the Administrator permission in your server."); err != nil {
-			c.onError(err)
-		}
+	// permissions are checked by Handle (see models.CapabilityApiClients)
+	options := c.i.ApplicationCommandData().Options
+	if len(options) != 1 || options[0].Type != discordgo.ApplicationCommandOptionSubCommand {
+		c.onError(errMissingSubcommand)
 		return
 	}
+	subCommand := options[0]
+	optionNameToOptionsMapping := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subCommand.Options))
+	for _, option := range subCommand.Options {
+		optionNameToOptionsMapping[option.Name] = option
+	}
+
+	switch subCommand.Name {
+	case "api-client":
+		handleDeveloperApiClient(c)
+	case "webhook-add":
+		addWebhook(c, optionNameToOptionsMapping)
+	case "webhook-list":
+		listWebhooks(c)
+	case "webhook-remove":
+		removeWebhook(c, optionNameToOptionsMapping)
+	default:
+		c.onError(errors.New("unknown subcommand"))
+	}
+}
+
+func handleDeveloperApiClient(c *NativeSlashHandlingContext) {
 	// check for existing API client
 	guildID := c.i.GuildID
 	clients, err := c.apiClientRepository.FindApiClientsByGuildID(guildID)
@@ -137,7 +157,7 @@ e.g.
 `+"```"+`
 Authorization: Basic %s
 `+"```"+`
-*Please store this somewhere safe!* We can't retrieve this at a later time - if you lose these you'd have to re-generate your API client by running `+"`"+`/developer`+"`"+` again.
+*Please store this somewhere safe!* We can't retrieve this at a later time - if you lose these you'd have to re-generate your API client by running `+"`"+`/developer api-client`+"`"+` again.
 `, clientID, clientSecret, base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", clientID, clientSecret)))), replyOptions{IsPrivate: true})
 	if err != nil {
 		c.onError(err)
@@ -0,0 +1,126 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andanhm/go-prettytime"
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/webhook"
+)
+
+func addWebhook(c *NativeSlashHandlingContext, optionNameToOptionsMapping map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	urlOption, ok := optionNameToOptionsMapping["url"]
+	if !ok || urlOption == nil {
+		c.onError(errors.New("missing url option"))
+		return
+	}
+	subscription, secret, err := webhook.Service.Subscribe(context.Background(), c.i.GuildID, urlOption.StringValue(), c.i.Member.User.ID)
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidURL) || errors.Is(err, webhook.ErrLimitReached) {
+			if err := c.replyWithOption(err.Error(), replyOptions{IsPrivate: true}); err != nil {
+				c.onError(err)
+			}
+			return
+		}
+		c.onError(err)
+		return
+	}
+	err = c.replyWithOption(fmt.Sprintf(`
+Yay, GroceryBot will now POST to %s (webhook #%d) whenever your groceries change! Here's the secret for it:
+`+"```"+`
+%s
+`+"```"+`
+Every delivery comes with a `+"`"+`%s`+"`"+` header, which is `+"`"+`sha256=`+"`"+` followed by the hex-encoded HMAC-SHA256 of `+"`"+`<%s header>.<body>`+"`"+` using the secret above. Check it to make sure that the delivery came from GroceryBot!
+
+*Please store this somewhere safe!* We can't retrieve this at a later time - if you lose it you'd have to remove the webhook and add it again.
+`, subscription.URL, subscription.ID, secret, webhook.HeaderSignature, webhook.HeaderTimestamp), replyOptions{IsPrivate: true})
+	if err != nil {
+		c.onError(err)
+		return
+	}
+
+	followUpMsg := fmt.Sprintf(":wave: Heyo! Just letting yall know that %s has just added a webhook for this server. This means that GroceryBot will send changes made to your groceries to their programs / applications. Thank you, and have a nice day!", c.i.Member.Mention())
+	if _, err := c.s.FollowupMessageCreate(c.i.Interaction, true, &discordgo.WebhookParams{
+		Content: followUpMsg,
+	}); err != nil {
+		c.onError(err)
+		return
+	}
+}
+
+func listWebhooks(c *NativeSlashHandlingContext) {
+	ctx := context.Background()
+	subscriptions, err := webhook.Service.GetSubscriptions(ctx, c.i.GuildID)
+	if err != nil {
+		c.onError(err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		if err := c.replyWithOption("You don't have any webhooks yet - add one with `/developer webhook-add`!", replyOptions{IsPrivate: true}); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("# 🪝 Webhooks\n")
+	for _, s := range subscriptions {
+		sb.WriteString(fmt.Sprintf("- **#%d** %s", s.ID, s.URL))
+		if s.CreatedByID != nil {
+			sb.WriteString(fmt.Sprintf(" (added by <@%s>)", *s.CreatedByID))
+		}
+		deliveries, err := webhook.Service.GetDeliveries(ctx, c.i.GuildID, s.ID, 1)
+		if err != nil {
+			c.onError(err)
+			return
+		}
+		if len(deliveries) == 0 {
+			sb.WriteString(" - nothing sent yet\n")
+			continue
+		}
+		d := deliveries[0]
+		status := "❌"
+		if d.Succeeded {
+			status = "✅"
+		}
+		result := "no response"
+		if d.StatusCode != nil {
+			result = fmt.Sprintf("HTTP %d", *d.StatusCode)
+		}
+		sb.WriteString(fmt.Sprintf(" - last sent %s: %s %s (`%s`, attempt %d)\n", prettytime.Format(d.CreatedAt), status, result, d.EventType, d.Attempt))
+	}
+	if err := c.replyWithOption(strings.TrimSpace(sb.String()), replyOptions{IsPrivate: true}); err != nil {
+		c.onError(err)
+	}
+}
+
+func removeWebhook(c *NativeSlashHandlingContext, optionNameToOptionsMapping map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	idOption, ok := optionNameToOptionsMapping["id"]
+	if !ok || idOption == nil {
+		c.onError(errors.New("missing id option"))
+		return
+	}
+	id := idOption.IntValue()
+	if id < 1 {
+		if err := c.replyWithOption(repositories.ErrWebhookNotFound.Error(), replyOptions{IsPrivate: true}); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	if err := webhook.Service.Unsubscribe(context.Background(), c.i.GuildID, uint(id)); err != nil {
+		if errors.Is(err, repositories.ErrWebhookNotFound) {
+			if err := c.replyWithOption(err.Error(), replyOptions{IsPrivate: true}); err != nil {
+				c.onError(err)
+			}
+			return
+		}
+		c.onError(err)
+		return
+	}
+	if err := c.reply(fmt.Sprintf("Done! GroceryBot won't send anything to webhook #%d anymore.", id)); err != nil {
+		c.onError(err)
+	}
+}
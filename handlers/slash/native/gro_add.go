@@ -0,0 +1,103 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+	"github.com/verzac/grocer-discord-bot/utils"
+	"go.uber.org/zap"
+)
+
+// handleGroAddAnyway handles the "Add anyway" button that !gro shows when the new entry is already on the list
+func handleGroAddAnyway(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("gro_add_anyway: guild mismatch", zap.Error(err))
+		}
+		return
+	}
+	registrationContext, err := registration.Service.GetRegistrationContext(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("gro_add_anyway: registration lookup failed", zap.Error(err))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	entry, groceryList, err := grocery.Service.ConfirmPendingAdd(ctx, key, c.i.Member.User.ID, registrationContext)
+	if errors.Is(err, grocery.ErrPendingAddNotFound) {
+		if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    "Sorry, this has expired. Please use `/gro` again if you still want to add it.",
+				Components: []discordgo.MessageComponent{},
+			},
+		}); err != nil {
+			c.logger.Error("gro_add_anyway: update expired", zap.Error(err))
+		}
+		return
+	}
+	if errors.Is(err, grocery.ErrPendingAddWrongAuthor) {
+		if err := respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever added the item."); err != nil {
+			c.logger.Error("gro_add_anyway: wrong author", zap.Error(err))
+		}
+		return
+	}
+	if err != nil && entry == nil {
+		c.logger.Error("gro_add_anyway: failed to add", zap.Error(err))
+		if err := respondComponentError(c.s, c.i, utils.GenericErrorMessage(err)); err != nil {
+			c.logger.Error("gro_add_anyway: error respond", zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		// the entry has been added, but the !grohere messages could not be updated
+		c.logger.Error("gro_add_anyway: OnGroceryListEdit failed", zap.Error(err))
+	}
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("Added *%s* into %s!", entry.GetDisplayText(), groceryList.GetName()),
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		c.logger.Error("gro_add_anyway: update success but respond failed", zap.Error(err))
+	}
+}
+
+func handleGroAddCancel(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("gro_add_cancel: guild mismatch", zap.Error(err))
+		}
+		return
+	}
+	if authorID, ok := grocery.Service.PendingAddAuthorID(key); ok && authorID != c.i.Member.User.ID {
+		if err := respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever added the item."); err != nil {
+			c.logger.Error("gro_add_cancel: wrong author", zap.Error(err))
+		}
+		return
+	}
+	grocery.Service.CancelPendingAdd(key)
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    "No worries - I didn't add it again.",
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		c.logger.Error("gro_add_cancel: update", zap.Error(err))
+	}
+}
@@ -0,0 +1,260 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/undo"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+	"go.uber.org/zap"
+)
+
+var (
+	errGrohereScopeNotFound = errors.New("this grocery list doesn't exist anymore")
+)
+
+// getGrohereScope returns the grocery lists that a !grohere message's components act on, which are encoded in their custom IDs (see groceryutils.GetGrohereScope).
+// The lists are looked up in the interaction's guild (along with the lists shared with it), so that components can't be used to edit another guild's groceries.
+func (c *NativeSlashHandlingContext) getGrohereScope(ctx context.Context) (groceryLists []*models.GroceryList, err error) {
+	scope, _, _ := strings.Cut(strings.TrimSpace(c.customIDSuffix), ":")
+	if scope == groceryutils.GrohereScopeAll {
+		found, err := c.groceryListRepository.FindByQuery(&models.GroceryList{GuildID: c.i.GuildID})
+		if err != nil {
+			return nil, err
+		}
+		sharedLists, err := grocery.Service.FindSharedGroceryLists(ctx, c.i.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, sharedLists...)
+		// nil is the default list
+		groceryLists = []*models.GroceryList{nil}
+		for i := range found {
+			// private lists aren't shown on !grohere messages
+			if found[i].IsPublic() {
+				groceryLists = append(groceryLists, &found[i])
+			}
+		}
+		return groceryLists, nil
+	}
+	id, err := strconv.ParseUint(scope, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return []*models.GroceryList{nil}, nil
+	}
+	gl, err := grocery.Service.GetGuildGroceryList(ctx, c.i.GuildID, &models.GroceryList{ID: uint(id)})
+	if err != nil {
+		return nil, err
+	}
+	if gl == nil || !gl.IsPublic() {
+		return nil, errGrohereScopeNotFound
+	}
+	return []*models.GroceryList{gl}, nil
+}
+
+func isInGrohereScope(groceryLists []*models.GroceryList, g *models.GroceryEntry) bool {
+	for _, gl := range groceryLists {
+		if gl.GetID() == nil && g.GroceryListID == nil {
+			return true
+		}
+		if gl != nil && g.GroceryListID != nil && gl.ID == *g.GroceryListID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGrohereComponent makes sure that a !grohere component has been used by a member of the guild that it belongs to, and responds if it hasn't.
+func (c *NativeSlashHandlingContext) checkGrohereComponent() bool {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return false
+	}
+	if c.i.GuildID == "" || c.i.Member == nil || c.i.Member.User == nil {
+		if err := respondComponentError(c.s, c.i, "Sorry, this can only be used in a server."); err != nil {
+			c.logger.Error("grohere: not in a guild", zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+func (c *NativeSlashHandlingContext) respondGrohereComponentError(err error) {
+	msg := "Something went wrong... Please try again."
+	if errors.Is(err, errGrohereScopeNotFound) {
+		msg = "Sorry, this grocery list doesn't exist anymore."
+	} else {
+		c.logger.Error("grohere: failed to handle component", zap.Error(err))
+	}
+	if err := respondComponentError(c.s, c.i, msg); err != nil {
+		c.logger.Error("grohere: error respond", zap.Error(err))
+	}
+}
+
+// acknowledgeGrohereComponent leaves the message as it is - the !grohere worker will update it shortly.
+func (c *NativeSlashHandlingContext) acknowledgeGrohereComponent() {
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		c.logger.Error("grohere: acknowledge", zap.Error(err))
+	}
+}
+
+// handleGrohereCheck handles the select menus of !grohere messages, which tick the selected items off (or untick them, if they've been ticked off already)
+func handleGrohereCheck(c *NativeSlashHandlingContext) {
+	if !c.checkGrohereComponent() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	groceryLists, err := c.getGrohereScope(ctx)
+	if err != nil {
+		c.respondGrohereComponentError(err)
+		return
+	}
+	values := c.i.MessageComponentData().Values
+	ids := make([]uint, 0, len(values))
+	for _, v := range values {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.respondGrohereComponentError(err)
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	guildID := c.i.GuildID
+	authorID := c.i.Member.User.ID
+	found, err := grocery.Service.FindGuildGroceryEntries(ctx, guildID, ids)
+	if err != nil {
+		c.respondGrohereComponentError(err)
+		return
+	}
+	// the menu may have been rendered before one of the lists was made private
+	toToggle := make([]models.GroceryEntry, 0, len(found))
+	for _, g := range found {
+		if isInGrohereScope(groceryLists, &g) {
+			toToggle = append(toToggle, g)
+		}
+	}
+	now := time.Now()
+	checked := make([]models.GroceryEntry, 0, len(toToggle))
+	unchecked := make([]models.GroceryEntry, 0, len(toToggle))
+	for i := range toToggle {
+		g := &toToggle[i]
+		if g.IsChecked() {
+			g.CheckedAt = nil
+			g.CheckedByID = nil
+			unchecked = append(unchecked, *g)
+		} else {
+			g.CheckedAt = &now
+			g.CheckedByID = &authorID
+			checked = append(checked, *g)
+		}
+		g.UpdatedByID = &authorID
+		if err := c.groceryEntryRepository.Put(g); err != nil {
+			c.respondGrohereComponentError(err)
+			return
+		}
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeCheck, checked, authorID))
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeUncheck, unchecked, authorID))
+	if err := grocery.Service.OnGroceryEntriesEdit(ctx, guildID, toToggle); err != nil {
+		c.logger.Error("grohere_check: OnGroceryEntriesEdit failed", zap.Error(err))
+	}
+	c.acknowledgeGrohereComponent()
+}
+
+// handleGrohereSweep handles the "Remove ticked items" button of !grohere messages
+func handleGrohereSweep(c *NativeSlashHandlingContext) {
+	if !c.checkGrohereComponent() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	groceryLists, err := c.getGrohereScope(ctx)
+	if err != nil {
+		c.respondGrohereComponentError(err)
+		return
+	}
+	guildID := c.i.GuildID
+	authorID := c.i.Member.User.ID
+	swept := make([]models.GroceryEntry, 0)
+	for _, gl := range groceryLists {
+		s, err := c.groceryEntryRepository.DeleteCheckedInGroceryList(ctx, gl, gl.GetGuildID(guildID))
+		if err != nil {
+			c.respondGrohereComponentError(err)
+			return
+		}
+		swept = append(swept, s...)
+	}
+	if len(swept) == 0 {
+		if err := respondComponentError(c.s, c.i, "There's nothing ticked off - nothing to remove!"); err != nil {
+			c.logger.Error("grohere_sweep: nothing to sweep", zap.Error(err))
+		}
+		return
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeRemove, swept, authorID))
+	var groceryList *models.GroceryList
+	if len(groceryLists) == 1 {
+		groceryList = groceryLists[0]
+	}
+	var components []discordgo.MessageComponent
+	journalID, err := undo.Service.Record(ctx, guildID, authorID, models.UndoOperationSweep, groceryList, &models.UndoSnapshot{GroceryEntries: swept})
+	if err != nil {
+		c.logger.Error("grohere_sweep: failed to record undo journal entry", zap.Error(err))
+	} else {
+		components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Undo",
+						Style:    discordgo.SecondaryButton,
+						CustomID: handlers.CustomIDGroUndo + ":" + strconv.FormatUint(uint64(journalID), 10),
+					},
+				},
+			},
+		}
+	}
+	if err := grocery.Service.OnGroceryEntriesEdit(ctx, guildID, swept); err != nil {
+		c.logger.Error("grohere_sweep: OnGroceryEntriesEdit failed", zap.Error(err))
+	}
+	if err := c.replyWithOption(fmt.Sprintf("Removed %d ticked-off item(s)!", len(swept)), replyOptions{
+		Components: components,
+	}); err != nil {
+		c.logger.Error("grohere_sweep: respond", zap.Error(err))
+	}
+}
+
+// handleGrohereRefresh handles the "Refresh" button of !grohere messages
+func handleGrohereRefresh(c *NativeSlashHandlingContext) {
+	if !c.checkGrohereComponent() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	groceryLists, err := c.getGrohereScope(ctx)
+	if err != nil {
+		c.respondGrohereComponentError(err)
+		return
+	}
+	scope, _, _ := strings.Cut(c.customIDSuffix, ":")
+	if scope == groceryutils.GrohereScopeAll {
+		err = grocery.Service.UpdateGuildGrohere(ctx, c.i.GuildID)
+	} else {
+		err = grocery.Service.OnGroceryListEdit(ctx, groceryLists[0], c.i.GuildID)
+	}
+	if err != nil {
+		c.respondGrohereComponentError(err)
+		return
+	}
+	c.acknowledgeGrohereComponent()
+}
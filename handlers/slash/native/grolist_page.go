@@ -0,0 +1,54 @@
+package native
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"go.uber.org/zap"
+)
+
+// handleGrolistPage handles the Prev/Next buttons of a !grolist that's too long for one message
+func handleGrolistPage(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	// the suffix is formatted as "guildID:uuid:page"
+	suffix := strings.TrimSpace(c.customIDSuffix)
+	sep := strings.LastIndex(suffix, ":")
+	page := -1
+	if sep != -1 {
+		if p, err := strconv.Atoi(suffix[sep+1:]); err == nil {
+			page = p
+		}
+	}
+	if page < 0 || !pendingKeyMatchesGuild(suffix[:sep], c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("grolist_page: invalid custom ID", zap.Error(err))
+		}
+		return
+	}
+	key := suffix[:sep]
+	pages, ok := grocery.Service.GetGrolistPages(key)
+	if !ok {
+		if err := respondComponentError(c.s, c.i, "Sorry, this list has expired. Please use `/grolist` again to see the latest version."); err != nil {
+			c.logger.Error("grolist_page: expired", zap.Error(err))
+		}
+		return
+	}
+	if page >= len(pages) {
+		page = len(pages) - 1
+	}
+	content, components := handlers.GetGrolistPage(key, pages, page)
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: components,
+		},
+	}); err != nil {
+		c.logger.Error("grolist_page: update message", zap.Error(err))
+	}
+}
@@ -0,0 +1,91 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/handlers"
+	"github.com/verzac/grocer-discord-bot/services/undo"
+	"github.com/verzac/grocer-discord-bot/utils"
+	"go.uber.org/zap"
+)
+
+// handleGroUndo handles both /groundo (undoes the latest destructive operation) and the "Undo" button (undoes that specific operation)
+func handleGroUndo(c *NativeSlashHandlingContext) {
+	if c.i.GuildID == "" {
+		if err := c.reply("This command can only be used in a server."); err != nil {
+			c.onError(err)
+		}
+		return
+	}
+	var journalID *uint
+	if c.i.Type == discordgo.InteractionMessageComponent {
+		id, err := strconv.ParseUint(strings.TrimSpace(c.customIDSuffix), 10, 64)
+		if err != nil {
+			if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+				c.logger.Error("groundo: invalid custom ID", zap.Error(err))
+			}
+			return
+		}
+		uintID := uint(id)
+		journalID = &uintID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	operation, restored, err := undo.Service.Undo(ctx, c.i.GuildID, journalID, c.i.Member.User.ID)
+	var limitErr *undo.GroceryEntryLimitError
+	if journalID == nil {
+		switch {
+		case errors.Is(err, undo.ErrNothingToUndo):
+			if err := c.reply(handlers.MsgNothingToUndo); err != nil {
+				c.onError(err)
+			}
+		case errors.As(err, &limitErr):
+			if err := c.reply(handlers.GetUndoOverLimitMessage(limitErr.Limit)); err != nil {
+				c.onError(err)
+			}
+		case err != nil:
+			c.onError(err)
+		default:
+			if err := c.reply(handlers.GetUndoMessage(operation, restored)); err != nil {
+				c.onError(err)
+			}
+		}
+		return
+	}
+	if errors.As(err, &limitErr) {
+		// the button is left alone, so that it can be pressed again once there's room
+		if err := respondComponentError(c.s, c.i, handlers.GetUndoOverLimitMessage(limitErr.Limit)); err != nil {
+			c.logger.Error("groundo: over limit respond", zap.Error(err))
+		}
+		return
+	}
+	if err != nil && !errors.Is(err, undo.ErrNothingToUndo) {
+		c.logger.Error("groundo: failed to undo", zap.Error(err))
+		if err := respondComponentError(c.s, c.i, utils.GenericErrorMessage(err)); err != nil {
+			c.logger.Error("groundo: error respond", zap.Error(err))
+		}
+		return
+	}
+	msg := "This has already been undone (or it's too old to be undone)."
+	if err == nil {
+		msg = handlers.GetUndoMessage(operation, restored)
+	}
+	content := msg
+	if c.i.Message != nil && c.i.Message.Content != "" {
+		content = c.i.Message.Content + "\n\n" + msg
+	}
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		c.logger.Error("groundo: update message", zap.Error(err))
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/verzac/grocer-discord-bot/config"
 	"github.com/verzac/grocer-discord-bot/handlers/slash/defaults"
 	"github.com/verzac/grocer-discord-bot/services/ingredients"
 	"github.com/verzac/grocer-discord-bot/utils"
@@ -21,13 +20,6 @@ func handleIngredients(c *NativeSlashHandlingContext) {
 	if c.i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
-	if !config.IsN8NEnabled() {
-		c.logger.Error("ingredients: disabled because n8n is disabled")
-		if err := c.reply("`/ingredients` isn't ready yet. Please check back in later, or ask the team in the support server when it'll be ready. Thanks!"); err != nil {
-			c.onError(err)
-		}
-		return
-	}
 	url := ""
 	for _, o := range c.i.ApplicationCommandData().Options {
 		if o.Name == "url" {
@@ -42,6 +34,18 @@ func handleIngredients(c *NativeSlashHandlingContext) {
 		return
 	}
 	listLabel := defaults.ListLabelFromSlashOptions(c.i.ApplicationCommandData().Options)
+	// checked before fetching the recipe, so that the author doesn't have to wait to find out
+	if _, err := ingredients.Service.ResolveGroceryList(context.Background(), c.getMemberContext(), listLabel); err != nil {
+		var listErr *ingredients.GroceryListNotFoundError
+		if errors.As(err, &listErr) {
+			if err := c.replyWithOption(listErr.Error(), replyOptions{IsPrivate: true}); err != nil {
+				c.logger.Error("ingredients: reply list not found", zap.Error(err))
+			}
+			return
+		}
+		c.onError(err)
+		return
+	}
 
 	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
@@ -54,14 +58,14 @@ func handleIngredients(c *NativeSlashHandlingContext) {
 	}
 
 	// Discord requires an initial interaction response within ~3s; the deferred ack above satisfies that.
-	// The n8n request and FollowupMessageCreate may take ~10s+. discordgo already runs AddHandler callbacks
+	// Fetching the recipe (the page, then n8n) and FollowupMessageCreate may take ~10s+. discordgo already runs AddHandler callbacks
 	// on their own goroutine when Session.SyncEvents is false (the default; see Session.handle in event.go),
 	// so this blocking work does not stall the WebSocket read loop—only this handler's goroutine.
 
 	// separate context as it is _technically_ async (the outer context may still have a timeout)
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
-	items, err := ingredients.Service.FetchIngredients(ctx, url)
+	recipe, err := ingredients.Service.FetchIngredients(ctx, url)
 	if err != nil {
 		c.logger.Error("ingredients: fetch error", zap.Error(err))
 		fetchErrMsg := "Could not fetch ingredients, please try again later. If the problem persists, please contact my hooman. Thanks!"
@@ -75,6 +79,7 @@ func handleIngredients(c *NativeSlashHandlingContext) {
 		}
 		return
 	}
+	items := recipe.Ingredients
 	if len(items) == 0 {
 		if _, ferr := c.s.FollowupMessageCreate(c.i.Interaction, true, &discordgo.WebhookParams{
 			Content: "I didn't get any ingredients back from that link. Try a different URL or add items manually with `/grobulk`.",
@@ -83,33 +88,47 @@ func handleIngredients(c *NativeSlashHandlingContext) {
 		}
 		return
 	}
-	cacheKey := ingredients.Service.StorePending(items, c.i.GuildID, c.i.Member.User.ID, listLabel)
-	body := formatIngredientsFollowupBody(items)
+	cacheKey := ingredients.Service.StorePending(recipe, c.i.GuildID, c.i.Member.User.ID, listLabel)
+	body := formatIngredientsFollowupBody(items, listLabel)
 	_, ferr := c.s.FollowupMessageCreate(c.i.Interaction, true, &discordgo.WebhookParams{
-		Content: body,
-		Components: []discordgo.MessageComponent{
-			discordgo.ActionsRow{
-				Components: []discordgo.MessageComponent{
-					discordgo.Button{
-						Label:    "YES",
-						Style:    discordgo.SuccessButton,
-						CustomID: "ingredients_confirm:" + cacheKey,
-					},
-					discordgo.Button{
-						Label:    "NO",
-						Style:    discordgo.SecondaryButton,
-						CustomID: "ingredients_cancel:" + cacheKey,
-					},
-				},
-			},
-		},
+		Content:    body,
+		Components: ingredientsFollowupComponents(cacheKey),
 	})
 	if ferr != nil {
 		c.logger.Error("ingredients: followup with buttons failed", zap.Error(ferr))
 	}
 }
 
-func formatIngredientsFollowupBody(items []string) string {
+func ingredientsFollowupComponents(cacheKey string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "YES",
+					Style:    discordgo.SuccessButton,
+					CustomID: "ingredients_confirm:" + cacheKey,
+				},
+				discordgo.Button{
+					Label:    "NO",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "ingredients_cancel:" + cacheKey,
+				},
+				discordgo.Button{
+					Label:    "Edit",
+					Style:    discordgo.SecondaryButton,
+					CustomID: ingredientsEditCustomID + ":" + cacheKey,
+				},
+				discordgo.Button{
+					Label:    "Save recipe",
+					Style:    discordgo.PrimaryButton,
+					CustomID: ingredientsSaveCustomID + ":" + cacheKey,
+				},
+			},
+		},
+	}
+}
+
+func formatIngredientsFollowupBody(items []string, listLabel string) string {
 	itemsSection := ""
 	isTruncated := false
 	outFmt := `
@@ -117,7 +136,7 @@ Here are the ingredients I found from your recipe:
 
 %s
 
-Does this look right to you?
+Does this look right to you? Press **Edit** to take out what you already have, or save it to your recipe book for later.
 `
 	truncatedFromIdx := 0
 	outFmt = strings.TrimSpace(outFmt)
@@ -137,6 +156,9 @@ Does this look right to you?
 		itemsSection += fmt.Sprintf("\n**and %d other grocery items**\n", len(items)-1-truncatedFromIdx)
 	}
 	out := fmt.Sprintf(outFmt, itemsSection)
+	if listLabel != "" {
+		out += fmt.Sprintf("\nThey'll go on your *%s* list.", listLabel)
+	}
 
 	return out
 }
@@ -147,20 +169,20 @@ func handleIngredientsConfirm(c *NativeSlashHandlingContext) {
 	}
 	key := strings.TrimSpace(c.customIDSuffix)
 	if key == "" {
-		if err := respondIngredientsComponentError(c.s, c.i, "Missing confirmation id."); err != nil {
+		if err := respondComponentError(c.s, c.i, "Missing confirmation id."); err != nil {
 			c.logger.Error("ingredients_confirm: respond", zap.Error(err))
 		}
 		return
 	}
-	if !ingredientsPendingKeyMatchesGuild(key, c.i.GuildID) {
-		if err := respondIngredientsComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+	if !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
 			c.logger.Error("ingredients_confirm: guild mismatch", zap.Error(err))
 		}
 		return
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	n, err := ingredients.Service.ConfirmAndAdd(ctx, key, c.i.Member.User.ID)
+	n, err := ingredients.Service.ConfirmAndAdd(ctx, key, c.getMemberContext())
 	if errors.Is(err, ingredients.ErrPendingNotFound) {
 		if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseUpdateMessage,
@@ -215,13 +237,13 @@ func handleIngredientsCancel(c *NativeSlashHandlingContext) {
 	}
 	key := strings.TrimSpace(c.customIDSuffix)
 	if key == "" {
-		if err := respondIngredientsComponentError(c.s, c.i, "Missing confirmation id."); err != nil {
+		if err := respondComponentError(c.s, c.i, "Missing confirmation id."); err != nil {
 			c.logger.Error("ingredients_cancel: respond", zap.Error(err))
 		}
 		return
 	}
-	if !ingredientsPendingKeyMatchesGuild(key, c.i.GuildID) {
-		if err := respondIngredientsComponentError(c.s, c.i, "This confirmation doesn't belong to this server."); err != nil {
+	if !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "This confirmation doesn't belong to this server."); err != nil {
 			c.logger.Error("ingredients_cancel: guild mismatch", zap.Error(err))
 		}
 		return
@@ -250,18 +272,3 @@ func handleIngredientsCancel(c *NativeSlashHandlingContext) {
 		c.logger.Error("ingredients_cancel: update", zap.Error(err))
 	}
 }
-
-func ingredientsPendingKeyMatchesGuild(cacheKey, guildID string) bool {
-	g, _, ok := strings.Cut(cacheKey, ":")
-	return ok && g == guildID
-}
-
-func respondIngredientsComponentError(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) error {
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: msg,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
-	})
-}
@@ -0,0 +1,163 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/services/ingredients"
+	"go.uber.org/zap"
+)
+
+const (
+	ingredientsEditCustomID               = "ingredients_edit"
+	ingredientsEditSubmitCustomID         = "ingredients_edit_submit"
+	ingredientsEditLinesInputCustomID     = "ingredients_edit_lines"
+	ingredientsEditListLabelInputCustomID = "ingredients_edit_list_label"
+	// Discord's limit for text inputs
+	ingredientsEditMaxLength = 4000
+)
+
+// handleIngredientsEdit opens a modal with the pending ingredients (one per line) and the grocery list that they'll go on,
+// so that the author can take out or rewrite lines before pressing YES.
+func handleIngredientsEdit(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if key == "" || !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("ingredients_edit: invalid key", zap.Error(err))
+		}
+		return
+	}
+	recipe, authorID, listLabel, ok := ingredients.Service.PendingRecipe(key)
+	if !ok {
+		if err := respondComponentError(c.s, c.i, "Sorry, these ingredients have expired - run `/ingredients` again to edit them."); err != nil {
+			c.logger.Error("ingredients_edit: expired", zap.Error(err))
+		}
+		return
+	}
+	if authorID != c.i.Member.User.ID {
+		if err := respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever ran `/ingredients`."); err != nil {
+			c.logger.Error("ingredients_edit: wrong author", zap.Error(err))
+		}
+		return
+	}
+	lines := strings.Join(recipe.Ingredients, "\n")
+	if len([]rune(lines)) > ingredientsEditMaxLength {
+		if err := respondComponentError(c.s, c.i, "Sorry, there are too many ingredients to edit them here. Press **NO** and add the ones you need with `/grobulk` instead."); err != nil {
+			c.logger.Error("ingredients_edit: too long", zap.Error(err))
+		}
+		return
+	}
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: ingredientsEditSubmitCustomID + ":" + key,
+			Title:    "Edit ingredients",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    ingredientsEditLinesInputCustomID,
+							Label:       "Ingredients - one per line",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "Delete the lines for what you already have, or change them to what you need.",
+							Value:       lines,
+							Required:    true,
+							MaxLength:   ingredientsEditMaxLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    ingredientsEditListLabelInputCustomID,
+							Label:       "Grocery list label (optional)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "Leave empty for your main grocery list",
+							Value:       listLabel,
+							Required:    false,
+							MaxLength:   100,
+						},
+					},
+				},
+			},
+		},
+	}); err != nil {
+		c.logger.Error("ingredients_edit: open modal failed", zap.Error(err))
+	}
+}
+
+// handleIngredientsEditSubmit updates the pending ingredients, then the /ingredients message (along with its buttons) to match.
+func handleIngredientsEditSubmit(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionModalSubmit {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if key == "" || !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("ingredients_edit_submit: invalid key", zap.Error(err))
+		}
+		return
+	}
+	linesRaw, listLabel := parseIngredientsEditModalValues(c.i.ModalSubmitData().Components)
+	lines := strings.Split(linesRaw, "\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := ingredients.Service.UpdatePending(ctx, key, c.getMemberContext(), lines, listLabel)
+	var listErr *ingredients.GroceryListNotFoundError
+	switch {
+	case errors.Is(err, ingredients.ErrPendingNotFound):
+		err = respondComponentError(c.s, c.i, "Sorry, these ingredients have expired - run `/ingredients` again to edit them.")
+	case errors.Is(err, ingredients.ErrWrongAuthor):
+		err = respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever ran `/ingredients`.")
+	case errors.Is(err, ingredients.ErrNoIngredients):
+		err = respondComponentError(c.s, c.i, "You've taken out all of the ingredients - press **NO** if you don't need any of them.")
+	case errors.As(err, &listErr):
+		err = respondComponentError(c.s, c.i, listErr.Error())
+	case err != nil:
+		c.onError(err)
+		return
+	default:
+		recipe, _, _, ok := ingredients.Service.PendingRecipe(key)
+		if !ok {
+			err = respondComponentError(c.s, c.i, "Sorry, these ingredients have expired - run `/ingredients` again to edit them.")
+			break
+		}
+		err = c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    formatIngredientsFollowupBody(recipe.Ingredients, listLabel),
+				Components: ingredientsFollowupComponents(key),
+			},
+		})
+	}
+	if err != nil {
+		c.logger.Error("ingredients_edit_submit: respond failed", zap.Error(err))
+	}
+}
+
+func parseIngredientsEditModalValues(components []discordgo.MessageComponent) (lines string, listLabel string) {
+	for _, row := range components {
+		ar, ok := row.(*discordgo.ActionsRow)
+		if !ok || len(ar.Components) == 0 {
+			continue
+		}
+		ti, ok := ar.Components[0].(*discordgo.TextInput)
+		if !ok {
+			continue
+		}
+		switch ti.CustomID {
+		case ingredientsEditLinesInputCustomID:
+			lines = ti.Value
+		case ingredientsEditListLabelInputCustomID:
+			listLabel = strings.TrimSpace(ti.Value)
+		}
+	}
+	return lines, listLabel
+}
@@ -0,0 +1,169 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/ingredients"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
+	"go.uber.org/zap"
+)
+
+const (
+	ingredientsSaveCustomID              = "ingredients_save"
+	ingredientsSaveSubmitCustomID        = "ingredients_save_submit"
+	ingredientsSaveNameInputCustomID     = "ingredients_save_name"
+	ingredientsSaveServingsInputCustomID = "ingredients_save_servings"
+)
+
+// handleIngredientsSave opens a modal that asks for the name (and servings) of the recipe that /ingredients imported.
+func handleIngredientsSave(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if key == "" || !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("ingredients_save: invalid key", zap.Error(err))
+		}
+		return
+	}
+	recipe, authorID, _, ok := ingredients.Service.PendingRecipe(key)
+	if !ok {
+		if err := respondComponentError(c.s, c.i, "Sorry, this recipe has expired - run `/ingredients` again to save it."); err != nil {
+			c.logger.Error("ingredients_save: expired", zap.Error(err))
+		}
+		return
+	}
+	if authorID != c.i.Member.User.ID {
+		if err := respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever ran `/ingredients`."); err != nil {
+			c.logger.Error("ingredients_save: wrong author", zap.Error(err))
+		}
+		return
+	}
+
+	name := []rune(models.NormaliseRecipeName(recipe.Title))
+	if len(name) > recipes.MaxRecipeNameLength {
+		name = name[:recipes.MaxRecipeNameLength]
+	}
+	servings := ""
+	if recipe.Servings > 0 {
+		servings = strconv.Itoa(recipe.Servings)
+	}
+	if err := c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: ingredientsSaveSubmitCustomID + ":" + key,
+			Title:    "Save to your recipe book",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    ingredientsSaveNameInputCustomID,
+							Label:       "Name",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "e.g. lasagne",
+							Value:       strings.TrimSpace(string(name)),
+							Required:    true,
+							MaxLength:   recipes.MaxRecipeNameLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    ingredientsSaveServingsInputCustomID,
+							Label:       "Servings (optional)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "How many people it's for - lets you scale it later",
+							Value:       servings,
+							Required:    false,
+							MaxLength:   3,
+						},
+					},
+				},
+			},
+		},
+	}); err != nil {
+		c.logger.Error("ingredients_save: open modal failed", zap.Error(err))
+	}
+}
+
+func handleIngredientsSaveSubmit(c *NativeSlashHandlingContext) {
+	if c.i.Type != discordgo.InteractionModalSubmit {
+		return
+	}
+	key := strings.TrimSpace(c.customIDSuffix)
+	if key == "" || !pendingKeyMatchesGuild(key, c.i.GuildID) {
+		if err := respondComponentError(c.s, c.i, "Something went wrong... Please try again."); err != nil {
+			c.logger.Error("ingredients_save_submit: invalid key", zap.Error(err))
+		}
+		return
+	}
+	name, servingsRaw := parseIngredientsSaveModalValues(c.i.ModalSubmitData().Components)
+	servings := 0
+	if servingsRaw != "" {
+		s, err := strconv.Atoi(servingsRaw)
+		if err != nil || s < 1 {
+			if err := respondComponentError(c.s, c.i, recipes.ErrInvalidServings.Error()); err != nil {
+				c.logger.Error("ingredients_save_submit: invalid servings", zap.Error(err))
+			}
+			return
+		}
+		servings = s
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	recipe, replaced, err := ingredients.Service.SaveRecipe(ctx, key, c.i.Member.User.ID, name, servings)
+	switch {
+	case errors.Is(err, ingredients.ErrPendingNotFound):
+		err = respondComponentError(c.s, c.i, "Sorry, this recipe has expired - run `/ingredients` again to save it.")
+	case errors.Is(err, ingredients.ErrWrongAuthor):
+		err = respondComponentError(c.s, c.i, "Oops, that button can only be pressed by whoever ran `/ingredients`.")
+	case recipes.IsUserError(err):
+		err = respondComponentError(c.s, c.i, err.Error())
+	case err != nil:
+		c.onError(err)
+		return
+	default:
+		verb := "Saved"
+		if replaced {
+			verb = "Updated"
+		}
+		servingsText := ""
+		if recipe.Servings != nil {
+			servingsText = fmt.Sprintf(" (serves %d)", *recipe.Servings)
+		}
+		err = c.reply(fmt.Sprintf(":green_book: %s **%s** in your recipe book%s - use `/recipe-add name:%s` to add its ingredients to your grocery list whenever you need them.", verb, recipe.Name, servingsText, recipe.Name))
+	}
+	if err != nil {
+		c.logger.Error("ingredients_save_submit: respond failed", zap.Error(err))
+	}
+}
+
+func parseIngredientsSaveModalValues(components []discordgo.MessageComponent) (name string, servings string) {
+	for _, row := range components {
+		ar, ok := row.(*discordgo.ActionsRow)
+		if !ok || len(ar.Components) == 0 {
+			continue
+		}
+		ti, ok := ar.Components[0].(*discordgo.TextInput)
+		if !ok {
+			continue
+		}
+		switch ti.CustomID {
+		case ingredientsSaveNameInputCustomID:
+			name = strings.TrimSpace(ti.Value)
+		case ingredientsSaveServingsInputCustomID:
+			servings = strings.TrimSpace(ti.Value)
+		}
+	}
+	return name, servings
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"syscall"
 	"time"
+	// the Docker image doesn't come with a timezone database, which guild timezones (see /config set) need
+	_ "time/tzdata"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -19,9 +21,11 @@ import (
 	"github.com/verzac/grocer-discord-bot/handlers"
 	"github.com/verzac/grocer-discord-bot/handlers/api"
 	"github.com/verzac/grocer-discord-bot/handlers/slash"
+	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/monitoring"
 	"github.com/verzac/grocer-discord-bot/monitoring/groprometheus"
 	"github.com/verzac/grocer-discord-bot/services"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
 	"github.com/verzac/grocer-discord-bot/services/guildconfig"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -61,6 +65,23 @@ func onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 }
 
+// onGrolistReaction checks off entries when people react to !grolist replies (see UseGrolistReactions)
+func onGrolistReaction(s *discordgo.Session, r *discordgo.MessageReaction, member *discordgo.Member, checked bool) {
+	defer handlers.Recover(logger)
+	if r.UserID == s.State.User.ID {
+		return
+	}
+	if r.GuildID == "" {
+		// reacted to a reply in DMs, which is for the user's personal grocery lists
+		r.GuildID = models.PersonalGuildID(r.UserID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := grocery.Service.OnGrolistReaction(ctx, r, member, checked); err != nil {
+		logger.Named("reaction.handler").Error("Cannot handle reaction to !grolist reply.", zap.String("GuildID", r.GuildID), zap.Error(err))
+	}
+}
+
 func logPanic() {
 	if r := recover(); r != nil && logger != nil {
 		logger.Error(
@@ -132,6 +153,13 @@ func main() {
 	}()
 	logger.Info("Setting up discordgo...")
 	d.AddHandler(onMessage)
+	d.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		onGrolistReaction(s, r.MessageReaction, r.Member, true)
+	})
+	d.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+		// Discord doesn't send the member for removed reactions
+		onGrolistReaction(s, r.MessageReaction, nil, false)
+	})
 
 	d.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
 		logger := logger.Named("activity")
@@ -201,7 +229,7 @@ func main() {
 		logger.Warn("Discord websocket disconnected")
 	})
 
-	d.Identify.Intents = discordgo.IntentsGuildMessages
+	d.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessages | discordgo.IntentsDirectMessageReactions
 	if err := d.Open(); err != nil {
 		panic(err)
 	}
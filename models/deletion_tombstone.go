@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	TombstoneEntityGroceryEntry = "grocery_entry"
+	TombstoneEntityGroceryList  = "grocery_list"
+	// the whole guild has been wiped through !groreset, so everything before the tombstone is gone
+	TombstoneEntityGuild = "guild"
+)
+
+// DeletionTombstone records that something has been deleted, so that GET /sync can tell clients to delete it too.
+type DeletionTombstone struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GuildID    string    `gorm:"not null" json:"guild_id"`
+	EntityType string    `gorm:"not null" json:"entity_type"`
+	EntityID   uint      `gorm:"not null" json:"entity_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
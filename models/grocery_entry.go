@@ -1,7 +1,9 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/andanhm/go-prettytime"
@@ -16,6 +18,57 @@ type GroceryEntry struct {
 	UpdatedByID   *string      `json:"updated_by_id"`
 	GroceryListID *uint        `json:"grocery_list_id"`
 	GroceryList   *GroceryList `json:"grocery_list"`
+	CheckedAt     *time.Time   `json:"checked_at"`
+	CheckedByID   *string      `json:"checked_by_id"`
+	Quantity      *float64     `json:"quantity" validate:"omitempty,gt=0"`
+	Unit          *string      `json:"unit"`
+	Category      *string      `json:"category"`
+	// Version goes up by one every time the entry is updated (see GetETag)
+	Version uint `gorm:"not null;default:1" json:"version"`
+}
+
+// GetCategory returns the category of the entry, or an empty string if it's uncategorised.
+func (g *GroceryEntry) GetCategory() string {
+	if g.Category == nil {
+		return ""
+	}
+	return *g.Category
+}
+
+// GetDisplayText returns the item name along with its quantity & unit (if any), e.g. "Milk — 2 L".
+func (g *GroceryEntry) GetDisplayText() string {
+	if g.Quantity == nil {
+		return g.ItemDesc
+	}
+	quantityText := strconv.FormatFloat(*g.Quantity, 'f', -1, 64)
+	if g.Unit != nil && *g.Unit != "" {
+		quantityText += " " + *g.Unit
+	}
+	return fmt.Sprintf("%s — %s", g.ItemDesc, quantityText)
+}
+
+// GetETag returns the entity tag that API consumers send back through If-Match, e.g. `"3"`.
+func (g *GroceryEntry) GetETag() string {
+	return formatETag(g.Version)
+}
+
+// MarshalJSON adds the computed display_text so that API consumers render entries the same way as grolist & grohere.
+func (g GroceryEntry) MarshalJSON() ([]byte, error) {
+	type groceryEntryAlias GroceryEntry
+	return json.Marshal(&struct {
+		groceryEntryAlias
+		DisplayText string `json:"display_text"`
+		ETag        string `json:"etag"`
+	}{
+		groceryEntryAlias: groceryEntryAlias(g),
+		DisplayText:       g.GetDisplayText(),
+		ETag:              g.GetETag(),
+	})
+}
+
+// IsChecked returns true if the entry has been checked off (i.e. bought) but not swept yet.
+func (g *GroceryEntry) IsChecked() bool {
+	return g.CheckedAt != nil
 }
 
 func (g *GroceryEntry) GetUpdatedByString() string {
@@ -25,3 +78,11 @@ func (g *GroceryEntry) GetUpdatedByString() string {
 	}
 	return updatedByString
 }
+
+func (g *GroceryEntry) GetCheckedByString() string {
+	checkedByString := ""
+	if g.CheckedAt != nil && g.CheckedByID != nil {
+		checkedByString = fmt.Sprintf("checked off by <@%s> %s", *g.CheckedByID, prettytime.Format(*g.CheckedAt))
+	}
+	return checkedByString
+}
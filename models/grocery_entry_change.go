@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andanhm/go-prettytime"
+)
+
+const (
+	GroceryEntryChangeAdd     = "add"
+	GroceryEntryChangeEdit    = "edit"
+	GroceryEntryChangeMerge   = "merge"
+	GroceryEntryChangeCheck   = "check"
+	GroceryEntryChangeUncheck = "uncheck"
+	GroceryEntryChangeRemove  = "remove"
+	GroceryEntryChangeRestore = "restore"
+	// the entry has been moved into another category through /config
+	GroceryEntryChangeCategorise = "categorise"
+)
+
+// GroceryEntryChange is an append-only record of something that happened to a grocery entry.
+type GroceryEntryChange struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	GuildID        string `gorm:"not null;index:idx_grocery_entry_changes_guild_id_grocery_list_id" json:"guild_id"`
+	GroceryListID  *uint  `gorm:"index:idx_grocery_entry_changes_guild_id_grocery_list_id" json:"grocery_list_id"`
+	GroceryEntryID uint   `gorm:"not null;index" json:"grocery_entry_id"`
+	Action         string `gorm:"not null" json:"action"`
+	// the entry's display text before the change (nil if it didn't exist yet)
+	Before *string `json:"before"`
+	// the entry's display text after the change (nil if it has been removed)
+	After       *string   `json:"after"`
+	ChangedByID *string   `json:"changed_by_id"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// NewGroceryEntryChanges records the same action for multiple entries, e.g. when items are added or removed.
+// changedByID can be empty for changes made by GroceryBot itself.
+func NewGroceryEntryChanges(action string, entries []GroceryEntry, changedByID string) []GroceryEntryChange {
+	changes := make([]GroceryEntryChange, 0, len(entries))
+	for i := range entries {
+		var before, after *GroceryEntry
+		switch action {
+		case GroceryEntryChangeAdd, GroceryEntryChangeRestore, GroceryEntryChangeMerge:
+			after = &entries[i]
+		case GroceryEntryChangeRemove:
+			before = &entries[i]
+		default:
+			before, after = &entries[i], &entries[i]
+		}
+		changes = append(changes, NewGroceryEntryChange(action, before, after, changedByID))
+	}
+	return changes
+}
+
+// NewGroceryEntryChange records a change from before to after (either can be nil, but not both).
+func NewGroceryEntryChange(action string, before *GroceryEntry, after *GroceryEntry, changedByID string) GroceryEntryChange {
+	entry := after
+	if entry == nil {
+		entry = before
+	}
+	change := GroceryEntryChange{
+		GuildID:        entry.GuildID,
+		GroceryListID:  entry.GroceryListID,
+		GroceryEntryID: entry.ID,
+		Action:         action,
+	}
+	if before != nil {
+		beforeText := before.GetDisplayText()
+		change.Before = &beforeText
+	}
+	if after != nil {
+		afterText := after.GetDisplayText()
+		change.After = &afterText
+	}
+	if changedByID != "" {
+		change.ChangedByID = &changedByID
+	}
+	return change
+}
+
+func (c *GroceryEntryChange) getBefore() string {
+	if c.Before == nil {
+		return ""
+	}
+	return *c.Before
+}
+
+func (c *GroceryEntryChange) getAfter() string {
+	if c.After == nil {
+		return ""
+	}
+	return *c.After
+}
+
+// GetSummary describes the change for !grohistory, e.g. "<@123> edited *Milk* to *Oat milk* 2 hours ago"
+func (c *GroceryEntryChange) GetSummary() string {
+	who := "GroceryBot"
+	if c.ChangedByID != nil {
+		who = fmt.Sprintf("<@%s>", *c.ChangedByID)
+	}
+	what := ""
+	switch c.Action {
+	case GroceryEntryChangeAdd:
+		what = fmt.Sprintf("added *%s*", c.getAfter())
+	case GroceryEntryChangeEdit:
+		what = fmt.Sprintf("edited *%s* to *%s*", c.getBefore(), c.getAfter())
+	case GroceryEntryChangeMerge:
+		what = fmt.Sprintf("bumped up *%s*", c.getAfter())
+	case GroceryEntryChangeCheck:
+		what = fmt.Sprintf("checked off *%s*", c.getAfter())
+	case GroceryEntryChangeUncheck:
+		what = fmt.Sprintf("un-checked *%s*", c.getAfter())
+	case GroceryEntryChangeRemove:
+		what = fmt.Sprintf("removed *%s*", c.getBefore())
+	case GroceryEntryChangeRestore:
+		what = fmt.Sprintf("brought back *%s*", c.getAfter())
+	case GroceryEntryChangeCategorise:
+		what = fmt.Sprintf("re-categorised *%s*", c.getAfter())
+	default:
+		what = fmt.Sprintf("changed *%s*", c.getAfter())
+	}
+	return fmt.Sprintf("%s %s %s", who, what, prettytime.Format(c.CreatedAt))
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -9,6 +12,15 @@ const (
 	EnumDefaultGroceryList = -1
 )
 
+// Who can see a grocery list (see GroceryList.IsVisibleTo)
+const (
+	GroceryListVisibilityPublic = "public"
+	// only members with one of the AllowedIDs roles
+	GroceryListVisibilityRoles = "roles"
+	// only the AllowedIDs users
+	GroceryListVisibilityUsers = "users"
+)
+
 type GroceryList struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	GuildID   string    `json:"guild_id" gorm:"not null;index"`
@@ -16,6 +28,95 @@ type GroceryList struct {
 	FancyName *string   `json:"fancy_name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Version goes up by one every time the list is updated (see GetETag)
+	Version uint `json:"version" gorm:"not null;default:1"`
+	// see GroceryListVisibility*
+	Visibility string `json:"visibility" gorm:"not null;default:public"`
+	// the owner can always see the list - set when the list is first made non-public
+	OwnerID *string `json:"owner_id"`
+	// comma-separated role/user IDs, depending on Visibility (see GetAllowedIDs)
+	AllowedIDs string `json:"-" gorm:"not null"`
+}
+
+// GetETag returns the entity tag that API consumers send back through If-Match, e.g. `"3"`.
+func (gl *GroceryList) GetETag() string {
+	return formatETag(gl.Version)
+}
+
+// MarshalJSON adds the etag so that API consumers don't have to build it from the version themselves.
+func (gl GroceryList) MarshalJSON() ([]byte, error) {
+	type groceryListAlias GroceryList
+	return json.Marshal(&struct {
+		groceryListAlias
+		ETag       string   `json:"etag"`
+		AllowedIDs []string `json:"allowed_ids"`
+	}{
+		groceryListAlias: groceryListAlias(gl),
+		ETag:             gl.GetETag(),
+		AllowedIDs:       gl.GetAllowedIDs(),
+	})
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON, so that lists survive being snapshotted (e.g. by !groundo).
+func (gl *GroceryList) UnmarshalJSON(b []byte) error {
+	type groceryListAlias GroceryList
+	aux := &struct {
+		*groceryListAlias
+		AllowedIDs []string `json:"allowed_ids"`
+	}{
+		groceryListAlias: (*groceryListAlias)(gl),
+	}
+	if err := json.Unmarshal(b, aux); err != nil {
+		return err
+	}
+	gl.SetAllowedIDs(aux.AllowedIDs)
+	return nil
+}
+
+func (gl *GroceryList) GetAllowedIDs() []string {
+	if gl.AllowedIDs == "" {
+		return []string{}
+	}
+	return strings.Split(gl.AllowedIDs, ",")
+}
+
+func (gl *GroceryList) SetAllowedIDs(ids []string) {
+	gl.AllowedIDs = strings.Join(ids, ",")
+}
+
+// IsPublic returns true if everyone in the guild can see the list. The default list is always public.
+func (gl *GroceryList) IsPublic() bool {
+	return gl == nil || gl.Visibility == "" || gl.Visibility == GroceryListVisibilityPublic
+}
+
+// IsVisibleTo checks whether the user can see the list. roleIDs are only needed for GroceryListVisibilityRoles.
+func (gl *GroceryList) IsVisibleTo(userID string, roleIDs []string) bool {
+	if gl.IsPublic() {
+		return true
+	}
+	if userID == "" {
+		// API clients aren't anyone in particular
+		return false
+	}
+	if gl.OwnerID != nil && *gl.OwnerID == userID {
+		return true
+	}
+	allowedIDs := gl.GetAllowedIDs()
+	switch gl.Visibility {
+	case GroceryListVisibilityUsers:
+		return slices.Contains(allowedIDs, userID)
+	case GroceryListVisibilityRoles:
+		for _, roleID := range roleIDs {
+			if slices.Contains(allowedIDs, roleID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatETag(version uint) string {
+	return fmt.Sprintf("\"%d\"", version)
 }
 
 func (gl *GroceryList) GetName() string {
@@ -46,6 +147,15 @@ func (gl *GroceryList) GetID() *uint {
 	return nil
 }
 
+// GetGuildID returns the guild that owns the grocery list and its entries, which is another guild if it has been shared with guildID.
+// The default list (nil) always belongs to guildID.
+func (gl *GroceryList) GetGuildID(guildID string) string {
+	if gl != nil {
+		return gl.GuildID
+	}
+	return guildID
+}
+
 func (gl *GroceryList) GetLabelSuffix() string {
 	if gl == nil {
 		return ""
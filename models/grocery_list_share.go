@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// GroceryListShare makes a grocery list show up in another guild (see /grolist-join). The list (and its entries) still belong to
+// the guild that created it, so it's that guild's limits that its entries count against.
+type GroceryListShare struct {
+	ID            uint   `gorm:"primaryKey"`
+	GroceryListID uint   `gorm:"not null;uniqueIndex:idx_grocery_list_shares_grocery_list_id_guild_id"`
+	GuildID       string `gorm:"not null;uniqueIndex:idx_grocery_list_shares_grocery_list_id_guild_id;index"`
+	CreatedByID   *string
+	CreatedAt     time.Time
+}
+
+// GroceryListInvite is a single-use code (see /grolist-share) that lets another guild join a grocery list.
+type GroceryListInvite struct {
+	Code          string `gorm:"primaryKey"`
+	GroceryListID uint   `gorm:"not null;index"`
+	// the guild that owns the grocery list
+	GuildID     string `gorm:"not null"`
+	CreatedByID *string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"not null"`
+}
+
+func (i *GroceryListInvite) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// NormaliseInviteCode lets people type in invite codes without worrying about their case or any stray spaces.
+func NormaliseInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
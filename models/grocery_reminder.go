@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// GroceryReminder posts a grocery list to a channel and/or DMs it to users on a schedule (see /groremind).
+type GroceryReminder struct {
+	ID      uint   `gorm:"primaryKey"`
+	GuildID string `gorm:"not null;index"`
+	// nil for the default list - can also be a list that another guild has shared with this one
+	GroceryListID *uint
+	// nil if the reminder is only DM-ed
+	ChannelID *string
+	// comma-separated IDs of the users to DM (see GetUserIDs)
+	UserIDs string `gorm:"not null;default:''"`
+	// a 5-field cron expression (see utils.ParseCron), in the guild's timezone
+	Cron        string    `gorm:"not null"`
+	NextRunAt   time.Time `gorm:"not null;index"`
+	LastRunAt   *time.Time
+	CreatedByID *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (r *GroceryReminder) GetUserIDs() []string {
+	if r.UserIDs == "" {
+		return []string{}
+	}
+	return strings.Split(r.UserIDs, ",")
+}
+
+func (r *GroceryReminder) SetUserIDs(ids []string) {
+	r.UserIDs = strings.Join(ids, ",")
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// re-added every week on GroceryStaple.Weekday
+	GroceryStapleScheduleWeekly = "weekly"
+	// re-added every GroceryStaple.IntervalDays days
+	GroceryStapleScheduleInterval = "interval"
+	// re-added whenever it gets checked off
+	GroceryStapleScheduleChecked = "checked"
+)
+
+// GroceryStaple is an item that GroceryBot puts back on a grocery list by itself (see /grostaple-add).
+// Staples run at midnight UTC on the day that they're due.
+type GroceryStaple struct {
+	ID uint `gorm:"primaryKey"`
+	// the guild that owns the grocery list, which is what the staple's entries count against
+	GuildID string `gorm:"not null;index:idx_grocery_staples_guild_id_grocery_list_id"`
+	// nil for the default list
+	GroceryListID *uint  `gorm:"index:idx_grocery_staples_guild_id_grocery_list_id"`
+	ItemDesc      string `gorm:"not null"`
+	Quantity      *float64
+	Unit          *string
+	Schedule      string `gorm:"not null"`
+	// only for GroceryStapleScheduleWeekly
+	Weekday *int
+	// only for GroceryStapleScheduleInterval
+	IntervalDays *int
+	// nil for GroceryStapleScheduleChecked
+	NextRunAt *time.Time `gorm:"index"`
+	// staples are only re-added for check-offs after this
+	LastRunAt   time.Time
+	CreatedByID *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ToGroceryEntry returns a new grocery entry for the staple, which still needs to be added into its grocery list.
+func (s *GroceryStaple) ToGroceryEntry() GroceryEntry {
+	return GroceryEntry{
+		ItemDesc:      s.ItemDesc,
+		Quantity:      s.Quantity,
+		Unit:          s.Unit,
+		GuildID:       s.GuildID,
+		GroceryListID: s.GroceryListID,
+	}
+}
+
+func (s *GroceryStaple) GetDisplayText() string {
+	entry := s.ToGroceryEntry()
+	return entry.GetDisplayText()
+}
+
+// GetScheduleText describes when the staple gets re-added, e.g. "every Saturday".
+func (s *GroceryStaple) GetScheduleText() string {
+	switch s.Schedule {
+	case GroceryStapleScheduleWeekly:
+		if s.Weekday != nil {
+			return fmt.Sprintf("every %s", time.Weekday(*s.Weekday))
+		}
+	case GroceryStapleScheduleInterval:
+		if s.IntervalDays != nil {
+			if *s.IntervalDays == 1 {
+				return "every day"
+			}
+			return fmt.Sprintf("every %d days", *s.IntervalDays)
+		}
+	case GroceryStapleScheduleChecked:
+		return "whenever it's checked off"
+	}
+	return "never"
+}
+
+// GetNextRunAfter returns when the staple is next due after t, in t's location (zero for staples that aren't on a timer).
+func (s *GroceryStaple) GetNextRunAfter(t time.Time) time.Time {
+	u := t.UTC()
+	day := time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+	switch s.Schedule {
+	case GroceryStapleScheduleWeekly:
+		if s.Weekday == nil {
+			return time.Time{}
+		}
+		days := (*s.Weekday - int(day.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return day.AddDate(0, 0, days).In(t.Location())
+	case GroceryStapleScheduleInterval:
+		if s.IntervalDays == nil || *s.IntervalDays < 1 {
+			return time.Time{}
+		}
+		return day.AddDate(0, 0, *s.IntervalDays).In(t.Location())
+	}
+	return time.Time{}
+}
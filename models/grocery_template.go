@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// GroceryTemplate is a named copy of what was on a grocery list (see /grotemplate), which can be put back onto any list later.
+type GroceryTemplate struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	GuildID string `gorm:"not null;uniqueIndex:idx_grocery_templates_guild_id_name" json:"guild_id"`
+	// see NormaliseTemplateName
+	Name string `gorm:"not null;uniqueIndex:idx_grocery_templates_guild_id_name" json:"name"`
+	// JSON-encoded []GroceryTemplateItem (see GetItems)
+	Items       string    `gorm:"not null" json:"-"`
+	CreatedByID *string   `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type GroceryTemplateItem struct {
+	ItemDesc string   `json:"item_desc"`
+	Quantity *float64 `json:"quantity"`
+	Unit     *string  `json:"unit"`
+	Category *string  `json:"category"`
+}
+
+func NewGroceryTemplateItem(g *GroceryEntry) GroceryTemplateItem {
+	return GroceryTemplateItem{
+		ItemDesc: g.ItemDesc,
+		Quantity: g.Quantity,
+		Unit:     g.Unit,
+		Category: g.Category,
+	}
+}
+
+// ToGroceryEntry returns a new grocery entry for the item, which still needs to be added into a grocery list.
+func (i *GroceryTemplateItem) ToGroceryEntry() GroceryEntry {
+	return GroceryEntry{
+		ItemDesc: i.ItemDesc,
+		Quantity: i.Quantity,
+		Unit:     i.Unit,
+		Category: i.Category,
+	}
+}
+
+func (i *GroceryTemplateItem) GetDisplayText() string {
+	entry := i.ToGroceryEntry()
+	return entry.GetDisplayText()
+}
+
+func (t *GroceryTemplate) GetItems() ([]GroceryTemplateItem, error) {
+	items := make([]GroceryTemplateItem, 0)
+	if err := json.Unmarshal([]byte(t.Items), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (t *GroceryTemplate) SetItems(items []GroceryTemplateItem) error {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	t.Items = string(b)
+	return nil
+}
+
+// MarshalJSON returns the template's items as a list so that API consumers don't need to decode them.
+func (t GroceryTemplate) MarshalJSON() ([]byte, error) {
+	type groceryTemplateAlias GroceryTemplate
+	items, err := t.GetItems()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		groceryTemplateAlias
+		Items []GroceryTemplateItem `json:"items"`
+	}{
+		groceryTemplateAlias: groceryTemplateAlias(t),
+		Items:                items,
+	})
+}
+
+// NormaliseTemplateName lets people refer to templates without worrying about their case or any stray spaces,
+// e.g. " Camping  Trip" is "camping trip".
+func NormaliseTemplateName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type GrohereRecord struct {
 	ID               uint `gorm:"primaryKey"`
@@ -9,7 +12,22 @@ type GrohereRecord struct {
 	GroceryList      *GroceryList
 	GrohereChannelID string
 	GrohereMessageID string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// the messages that continue the list when it doesn't fit in one (see SplitMessageIDs)
+	ExtraMessageIDs string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 	// LastSeenAt       *time.Time
 }
+
+// SplitMessageIDs parses the comma-separated message IDs of a !grohere's extra messages.
+func SplitMessageIDs(messageIDs string) []string {
+	if messageIDs == "" {
+		return []string{}
+	}
+	return strings.Split(messageIDs, ",")
+}
+
+// JoinMessageIDs is the inverse of SplitMessageIDs.
+func JoinMessageIDs(messageIDs []string) string {
+	return strings.Join(messageIDs, ",")
+}
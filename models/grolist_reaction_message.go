@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GrolistReactionMessage remembers which grocery entries a !grolist reply rendered, so that reacting to the reply checks off the
+// entry that was shown - even if the list has changed since.
+type GrolistReactionMessage struct {
+	MessageID string `gorm:"primaryKey"`
+	GuildID   string `gorm:"not null"`
+	ChannelID string `gorm:"not null"`
+	// comma-separated IDs of the rendered entries, in the order that they were displayed (see GetGroceryEntryIDs)
+	GroceryEntryIDs string `gorm:"not null"`
+	CreatedAt       time.Time
+}
+
+func (m *GrolistReactionMessage) GetGroceryEntryIDs() []uint {
+	if m.GroceryEntryIDs == "" {
+		return []uint{}
+	}
+	tokens := strings.Split(m.GroceryEntryIDs, ",")
+	ids := make([]uint, 0, len(tokens))
+	for _, t := range tokens {
+		id, err := strconv.ParseUint(t, 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+func (m *GrolistReactionMessage) SetGroceryEntryIDs(ids []uint) {
+	tokens := make([]string, len(ids))
+	for i, id := range ids {
+		tokens[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	m.GroceryEntryIDs = strings.Join(tokens, ",")
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// GuildCategoryOverride maps a keyword to a category for a guild, taking precedence over the built-in categoriser.
+type GuildCategoryOverride struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GuildID   string    `gorm:"not null;uniqueIndex:idx_guild_category_overrides_guild_id_keyword" json:"guild_id"`
+	Keyword   string    `gorm:"not null;uniqueIndex:idx_guild_category_overrides_guild_id_keyword" json:"keyword"`
+	Category  string    `gorm:"not null" json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
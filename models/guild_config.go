@@ -6,10 +6,26 @@ type GuildConfig struct {
 	GuildID                 string `gorm:"primaryKey"`
 	GrohereChannelID        *string
 	GrohereMessageID        *string
+	GrohereExtraMessageIDs  string // see SplitMessageIDs
 	CreatedAt               time.Time
 	UpdatedAt               time.Time
 	UseEphemeral            bool
 	UseGrobulkAppend        bool // legacy opt-in flag for backwards compatibility - most guilds should have this be disabled
+	UseGrolistReactions     bool
 	LastAnnouncementVersion int
+	// an IANA timezone, e.g. Australia/Sydney - empty for UTC (see GetLocation)
+	Timezone string `gorm:"not null;default:''"`
 	// LastSeenAt       *time.Time
 }
+
+// GetLocation returns the guild's timezone (UTC unless it has been set through /config set), which reminders run in.
+func (c *GuildConfig) GetLocation() *time.Location {
+	if c == nil || c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Capabilities that can be restricted to roles through /config permissions
+const (
+	CapabilityAdd         = "add"
+	CapabilityRemove      = "remove"
+	CapabilityClear       = "clear"
+	CapabilityManageLists = "manage_lists"
+	CapabilityReset       = "reset"
+	CapabilityConfigure   = "configure"
+	CapabilityApiClients  = "api_clients"
+)
+
+// Capabilities are ordered the way they're shown to users.
+var Capabilities = []string{
+	CapabilityAdd,
+	CapabilityRemove,
+	CapabilityClear,
+	CapabilityManageLists,
+	CapabilityReset,
+	CapabilityConfigure,
+	CapabilityApiClients,
+}
+
+// CapabilityDescriptions describe what each capability lets people do, e.g. "Sorry, you can't <description>".
+var CapabilityDescriptions = map[string]string{
+	CapabilityAdd:         "add, edit or check off items",
+	CapabilityRemove:      "remove items or undo removals",
+	CapabilityClear:       "clear grocery lists",
+	CapabilityManageLists: "create, edit, delete or attach grocery lists, and set up their staples",
+	CapabilityReset:       "reset GroceryBot's data for this server",
+	CapabilityConfigure:   "configure GroceryBot",
+	CapabilityApiClients:  "manage API clients and webhooks",
+}
+
+// IsAdministratorOnlyByDefault returns true for capabilities that only administrators have until they're granted to a role.
+// Everyone else has every other capability until it's granted to a role.
+func IsAdministratorOnlyByDefault(capability string) bool {
+	return capability == CapabilityConfigure || capability == CapabilityApiClients
+}
+
+// GuildRolePermission grants a capability to a role. Once a capability has been granted to any role in a guild,
+// only members with one of those roles (and administrators) have it.
+type GuildRolePermission struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GuildID    string    `gorm:"not null;uniqueIndex:idx_guild_role_permissions_guild_id_role_id_capability" json:"guild_id"`
+	RoleID     string    `gorm:"not null;uniqueIndex:idx_guild_role_permissions_guild_id_role_id_capability" json:"role_id"`
+	Capability string    `gorm:"not null;uniqueIndex:idx_guild_role_permissions_guild_id_role_id_capability" json:"capability"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "strings"
+
+// Personal grocery lists are the ones that people keep in their DMs with GroceryBot. They're stored just like a guild's,
+// under a GuildID that's made from the user's ID (see PersonalGuildID), so that everything that works on a guild works on them too.
+const personalGuildIDPrefix = "user:"
+
+// PersonalGuildID returns the GuildID of the user's personal grocery lists.
+func PersonalGuildID(userID string) string {
+	return personalGuildIDPrefix + userID
+}
+
+// IsPersonalGuildID checks whether the GuildID belongs to someone's personal grocery lists rather than a Discord server.
+func IsPersonalGuildID(guildID string) bool {
+	return strings.HasPrefix(guildID, personalGuildIDPrefix)
+}
+
+// GuildIDFromKey returns the guild ID at the start of a key formatted as "guildID:id" (e.g. the keys of our pending caches).
+// Personal guild IDs have a colon in them too, so the guild ID is everything before the last colon.
+func GuildIDFromKey(key string) (guildID string, ok bool) {
+	sep := strings.LastIndex(key, ":")
+	if sep <= 0 || sep == len(key)-1 {
+		return "", false
+	}
+	return key[:sep], true
+}
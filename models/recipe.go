@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Recipe is a recipe in a guild's recipe book (see /recipe-add), usually saved from what /ingredients imported.
+type Recipe struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	GuildID string `gorm:"not null;uniqueIndex:idx_recipes_guild_id_name" json:"guild_id"`
+	// see NormaliseRecipeName
+	Name      string  `gorm:"not null;uniqueIndex:idx_recipes_guild_id_name" json:"name"`
+	Title     *string `json:"title"`
+	SourceURL *string `json:"source_url"`
+	// JSON-encoded []string of ingredient lines, e.g. "2 cups flour" (see GetIngredients)
+	Ingredients string `gorm:"not null" json:"-"`
+	// how many people the ingredients are for - nil if the recipe doesn't say, in which case it cannot be scaled
+	Servings    *int      `json:"servings"`
+	CreatedByID *string   `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r *Recipe) GetIngredients() ([]string, error) {
+	ingredients := make([]string, 0)
+	if err := json.Unmarshal([]byte(r.Ingredients), &ingredients); err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *Recipe) SetIngredients(ingredients []string) error {
+	b, err := json.Marshal(ingredients)
+	if err != nil {
+		return err
+	}
+	r.Ingredients = string(b)
+	return nil
+}
+
+// GetDisplayName returns the recipe's title if it has one, or its name otherwise.
+func (r *Recipe) GetDisplayName() string {
+	if r.Title != nil && *r.Title != "" {
+		return *r.Title
+	}
+	return r.Name
+}
+
+// MarshalJSON returns the recipe's ingredients as a list so that API consumers don't need to decode them.
+func (r Recipe) MarshalJSON() ([]byte, error) {
+	type recipeAlias Recipe
+	ingredients, err := r.GetIngredients()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		recipeAlias
+		Ingredients []string `json:"ingredients"`
+	}{
+		recipeAlias: recipeAlias(r),
+		Ingredients: ingredients,
+	})
+}
+
+// NormaliseRecipeName works just like NormaliseTemplateName, e.g. " Mum's  Lasagne" is "mum's lasagne".
+func NormaliseRecipeName(name string) string {
+	return NormaliseTemplateName(name)
+}
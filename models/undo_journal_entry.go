@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operations that can be undone through !groundo
+const (
+	UndoOperationClear   = "clear"
+	UndoOperationRemove  = "remove"
+	UndoOperationReplace = "replace"
+	UndoOperationReset   = "reset"
+	UndoOperationSweep   = "sweep"
+)
+
+// UndoJournalEntry records what a guild's groceries looked like before a destructive operation, so that it can be undone.
+type UndoJournalEntry struct {
+	ID        uint   `gorm:"primaryKey"`
+	GuildID   string `gorm:"not null;index"`
+	Operation string `gorm:"not null"`
+	// the grocery list that the operation was run on (nil for the default list & for resets)
+	GroceryListID *uint
+	// JSON-encoded UndoSnapshot
+	Snapshot    string `gorm:"not null"`
+	CreatedByID *string
+	CreatedAt   time.Time
+}
+
+type UndoSnapshot struct {
+	GroceryLists   []GroceryList  `json:"grocery_lists,omitempty"`
+	GroceryEntries []GroceryEntry `json:"grocery_entries"`
+}
+
+func (u *UndoJournalEntry) GetSnapshot() (*UndoSnapshot, error) {
+	snapshot := &UndoSnapshot{}
+	if err := json.Unmarshal([]byte(u.Snapshot), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (u *UndoJournalEntry) SetSnapshot(snapshot *UndoSnapshot) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	u.Snapshot = string(b)
+	return nil
+}
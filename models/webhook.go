@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WebhookSubscription is a URL that GroceryBot POSTs events to whenever a guild's groceries change (see /developer webhook-add).
+type WebhookSubscription struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	GuildID string `gorm:"not null" json:"guild_id"`
+	URL     string `gorm:"not null" json:"url"`
+	// Secret signs every delivery so that receivers can check that it came from us. It has to be kept as-is (not hashed) to be able to sign with it.
+	Secret      string    `gorm:"not null" json:"-"`
+	CreatedByID *string   `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one attempt at delivering an event to a webhook subscription.
+type WebhookDelivery struct {
+	ID                    uint   `gorm:"primaryKey" json:"id"`
+	GuildID               string `gorm:"not null" json:"guild_id"`
+	WebhookSubscriptionID uint   `gorm:"not null" json:"webhook_subscription_id"`
+	EventID               string `gorm:"not null" json:"event_id"`
+	EventType             string `gorm:"not null" json:"event_type"`
+	Attempt               int    `gorm:"not null" json:"attempt"`
+	// StatusCode is nil if the receiver couldn't be reached at all
+	StatusCode *int      `json:"status_code"`
+	Error      *string   `json:"error"`
+	Succeeded  bool      `gorm:"not null" json:"succeeded"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -34,6 +34,14 @@ var (
 		},
 		[]string{"command_name"},
 	)
+
+	// Gauge to track !grohere message edits that are waiting to be sent to Discord
+	grohereUpdateQueueDepthGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "grocer_bot_grohere_update_queue_depth",
+			Help: "Number of !grohere message updates waiting to be rendered and sent to Discord",
+		},
+	)
 )
 
 // SetDB sets the database connection for on-demand metrics
@@ -63,6 +71,11 @@ func IncrementCommandInvocation(commandName string) {
 	commandInvocationCounter.WithLabelValues(commandName).Inc()
 }
 
+// UpdateGrohereUpdateQueueDepth updates the number of queued !grohere message updates
+func UpdateGrohereUpdateQueueDepth(depth int) {
+	grohereUpdateQueueDepthGauge.Set(float64(depth))
+}
+
 // InitMetrics initializes and registers all metrics
 func InitMetrics(logger *zap.Logger) {
 	logger = logger.Named("prometheus")
@@ -74,6 +87,7 @@ func InitMetrics(logger *zap.Logger) {
 	// Register the metrics with our custom registry
 	registry.MustRegister(discordServersGauge)
 	registry.MustRegister(commandInvocationCounter)
+	registry.MustRegister(grohereUpdateQueueDepthGauge)
 
 	// Register the on-demand collector if we have a database connection
 	if db != nil {
@@ -1,5 +1,7 @@
 package repositories
 
+import "errors"
+
 const (
 	ErrCodeValidationError = iota
 	ErrInternal
@@ -7,6 +9,9 @@ const (
 
 var _ error = &RepositoryError{}
 
+// ErrVersionMismatch is returned when something has been changed (or deleted) by someone else since it was read
+var ErrVersionMismatch = errors.New("Someone else has changed this in the meantime - please fetch it again and retry.")
+
 const activeClause = "expires_at IS NULL OR expires_at > ?"
 
 type RepositoryError struct {
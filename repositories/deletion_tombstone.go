@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ DeletionTombstoneRepository = &DeletionTombstoneRepositoryImpl{}
+
+// DeletionTombstoneRepository reads the tombstones that are left behind when grocery entries & lists are deleted.
+// Tombstones are written by the repositories that delete things, in the same transaction as the delete.
+type DeletionTombstoneRepository interface {
+	// FindCreatedSince returns the guild's tombstones created after since, oldest first.
+	FindCreatedSince(ctx context.Context, guildID string, since time.Time) ([]models.DeletionTombstone, error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error)
+}
+
+type DeletionTombstoneRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *DeletionTombstoneRepositoryImpl) FindCreatedSince(ctx context.Context, guildID string, since time.Time) ([]models.DeletionTombstone, error) {
+	tombstones := make([]models.DeletionTombstone, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND created_at > ?", guildID, since).Order("id").Find(&tombstones).Error; err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
+
+func (r *DeletionTombstoneRepositoryImpl) DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where("created_at <= ?", before).Delete(&models.DeletionTombstone{})
+	return res.RowsAffected, res.Error
+}
+
+// createTombstones should be called in the same transaction as the delete.
+func createTombstones(tx *gorm.DB, guildID string, entityType string, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tombstones := make([]models.DeletionTombstone, len(ids))
+	for i, id := range ids {
+		tombstones[i] = models.DeletionTombstone{
+			GuildID:    guildID,
+			EntityType: entityType,
+			EntityID:   id,
+		}
+	}
+	return tx.Create(&tombstones).Error
+}
+
+// deleteGroceryEntries deletes the guild's grocery entries that match the conditions, and leaves tombstones behind for them.
+func deleteGroceryEntries(tx *gorm.DB, guildID string, conds ...interface{}) (rowsAffected int64, err error) {
+	ids := make([]uint, 0)
+	q := tx.Model(&models.GroceryEntry{}).Where("guild_id = ?", guildID)
+	if len(conds) > 0 {
+		q = q.Where(conds[0], conds[1:]...)
+	}
+	if err := q.Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := tx.Where("guild_id = ? AND id IN ?", guildID, ids).Delete(&models.GroceryEntry{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, createTombstones(tx, guildID, models.TombstoneEntityGroceryEntry, ids)
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/verzac/grocer-discord-bot/models"
 	"gorm.io/gorm"
@@ -38,6 +39,14 @@ type GroceryEntryRepository interface {
 	Delete(ctx context.Context, entry *models.GroceryEntry) error
 	FindByGuildAndIDs(ctx context.Context, guildID string, ids []uint) ([]models.GroceryEntry, error)
 	DeleteByGuildAndIDs(ctx context.Context, guildID string, ids []uint) (int64, error)
+	DeleteCheckedInGroceryList(ctx context.Context, groceryList *models.GroceryList, guildID string) ([]models.GroceryEntry, error)
+	UpdateAndAddToGroceryList(ctx context.Context, groceryList *models.GroceryList, toUpdate []models.GroceryEntry, toAdd []models.GroceryEntry, guildID string) *RepositoryError
+	// FindUpdatedSince returns the guild's entries that have been created or updated after since (see GET /sync).
+	FindUpdatedSince(ctx context.Context, guildID string, since time.Time) ([]models.GroceryEntry, error)
+	// PutIfVersion saves g only if the entry hasn't been changed since it was at version, otherwise it returns ErrVersionMismatch.
+	PutIfVersion(ctx context.Context, g *models.GroceryEntry, version uint) error
+	// DeleteIfVersion deletes the entry only if it hasn't been changed since it was at version, otherwise it returns ErrVersionMismatch.
+	DeleteIfVersion(ctx context.Context, entry *models.GroceryEntry, version uint) error
 }
 
 type GroceryEntryRepositoryImpl struct {
@@ -105,6 +114,7 @@ func (r *GroceryEntryRepositoryImpl) addToGroceryListWithDB(groceryList *models.
 			}
 		}
 		groceryEntries[i].GuildID = guildID
+		groceryEntries[i].Version = 1
 		if groceryList != nil {
 			groceryEntries[i].GroceryListID = &groceryList.ID
 		}
@@ -159,8 +169,68 @@ func (r *GroceryEntryRepositoryImpl) ReplaceItemsInGroceryList(groceryList *mode
 	return nil
 }
 
+// UpdateAndAddToGroceryList saves existing entries (e.g. merged duplicates) and adds new entries to the grocery list in a single transaction.
+func (r *GroceryEntryRepositoryImpl) UpdateAndAddToGroceryList(ctx context.Context, groceryList *models.GroceryList, toUpdate []models.GroceryEntry, toAdd []models.GroceryEntry, guildID string) *RepositoryError {
+	if groceryList != nil && groceryList.GuildID != guildID {
+		return ErrGroceryListGuildIDMismatch
+	}
+	tx := r.DB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return &RepositoryError{
+			ErrCode: ErrInternal,
+			Message: tx.Error.Error(),
+		}
+	}
+	defer tx.Rollback()
+	for i := range toUpdate {
+		if toUpdate[i].ID == 0 || toUpdate[i].GuildID != guildID {
+			return &RepositoryError{
+				ErrCode: ErrInternal,
+				Message: "Cannot update a grocery entry that does not exist in this guild.",
+			}
+		}
+		toUpdate[i].Version++
+		if res := tx.Omit("GroceryList").Save(&toUpdate[i]); res.Error != nil {
+			return &RepositoryError{
+				ErrCode: ErrInternal,
+				Message: res.Error.Error(),
+			}
+		}
+	}
+	if len(toAdd) > 0 {
+		if err := r.addToGroceryListWithDB(groceryList, toAdd, guildID, tx); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return &RepositoryError{
+			ErrCode: ErrInternal,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func (r *GroceryEntryRepositoryImpl) ClearGroceryList(groceryList *models.GroceryList, guildID string) (rowsAffected int64, err *RepositoryError) {
-	return r.clearGroceryListWithDB(groceryList, guildID, r.DB)
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return 0, &RepositoryError{
+			ErrCode: ErrInternal,
+			Message: tx.Error.Error(),
+		}
+	}
+	defer tx.Rollback()
+	rowsAffected, err = r.clearGroceryListWithDB(groceryList, guildID, tx)
+	if err != nil {
+		return 0, err
+	}
+	if cErr := tx.Commit().Error; cErr != nil {
+		return 0, &RepositoryError{
+			ErrCode: ErrInternal,
+			Message: cErr.Error(),
+		}
+	}
+	return rowsAffected, nil
 }
 
 // should only be used internally for transactions
@@ -169,23 +239,24 @@ func (r *GroceryEntryRepositoryImpl) clearGroceryListWithDB(groceryList *models.
 	if groceryList != nil && groceryList.GuildID != guildID {
 		return 0, ErrGroceryListGuildIDMismatch
 	}
-	var res *gorm.DB
+	var dErr error
 	if groceryList != nil {
-		res = db.Delete(models.GroceryEntry{}, "guild_id = ? AND grocery_list_id = ?", guildID, groceryList.ID)
+		rowsAffected, dErr = deleteGroceryEntries(db, guildID, "grocery_list_id = ?", groceryList.ID)
 	} else {
-		res = db.Delete(models.GroceryEntry{}, "guild_id = ? AND grocery_list_id IS NULL", guildID)
+		rowsAffected, dErr = deleteGroceryEntries(db, guildID, queryGroceryListIDIsNil)
 	}
-	if res.Error != nil {
+	if dErr != nil {
 		return 0, &RepositoryError{
-			Message: res.Error.Error(),
+			Message: dErr.Error(),
 			ErrCode: ErrInternal,
 		}
 	}
-	return res.RowsAffected, nil
+	return rowsAffected, nil
 
 }
 
 func (r *GroceryEntryRepositoryImpl) Put(g *models.GroceryEntry) error {
+	g.Version++
 	if r := r.DB.Save(g); r.Error != nil {
 		return r.Error
 	}
@@ -200,11 +271,10 @@ func (r *GroceryEntryRepositoryImpl) GetCount(query *models.GroceryEntry) (count
 }
 
 func (r *GroceryEntryRepositoryImpl) Delete(ctx context.Context, entry *models.GroceryEntry) error {
-	res := r.DB.WithContext(ctx).Delete(entry)
-	if res.Error != nil {
-		return res.Error
-	}
-	return nil
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := deleteGroceryEntries(tx, entry.GuildID, "id = ?", entry.ID)
+		return err
+	})
 }
 
 func (r *GroceryEntryRepositoryImpl) FindByGuildAndIDs(ctx context.Context, guildID string, ids []uint) ([]models.GroceryEntry, error) {
@@ -223,9 +293,78 @@ func (r *GroceryEntryRepositoryImpl) DeleteByGuildAndIDs(ctx context.Context, gu
 	if len(ids) == 0 {
 		return 0, nil
 	}
-	res := r.DB.WithContext(ctx).Where("guild_id = ? AND id IN ?", guildID, ids).Delete(&models.GroceryEntry{})
+	var rowsAffected int64
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		rowsAffected, err = deleteGroceryEntries(tx, guildID, "id IN ?", ids)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+// DeleteCheckedInGroceryList removes all checked-off entries from a grocery list (nil for the default list) and returns the removed entries.
+func (r *GroceryEntryRepositoryImpl) DeleteCheckedInGroceryList(ctx context.Context, groceryList *models.GroceryList, guildID string) ([]models.GroceryEntry, error) {
+	if groceryList != nil && groceryList.GuildID != guildID {
+		return nil, ErrGroceryListGuildIDMismatch
+	}
+	var removed []models.GroceryEntry
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Where("guild_id = ? AND checked_at IS NOT NULL", guildID)
+		if groceryList != nil {
+			q = q.Where("grocery_list_id = ?", groceryList.ID)
+		} else {
+			q = q.Where(queryGroceryListIDIsNil)
+		}
+		if res := q.Find(&removed); res.Error != nil {
+			return res.Error
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(removed))
+		for i := range removed {
+			ids[i] = removed[i].ID
+		}
+		_, err := deleteGroceryEntries(tx, guildID, "id IN ?", ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (r *GroceryEntryRepositoryImpl) FindUpdatedSince(ctx context.Context, guildID string, since time.Time) ([]models.GroceryEntry, error) {
+	entries := make([]models.GroceryEntry, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND updated_at > ?", guildID, since).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GroceryEntryRepositoryImpl) PutIfVersion(ctx context.Context, g *models.GroceryEntry, version uint) error {
+	g.Version = version + 1
+	res := r.DB.WithContext(ctx).Model(g).Where("version = ?", version).Select("*").Omit("GroceryList", "CreatedAt").Updates(g)
 	if res.Error != nil {
-		return 0, res.Error
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVersionMismatch
 	}
-	return res.RowsAffected, nil
+	return nil
+}
+
+func (r *GroceryEntryRepositoryImpl) DeleteIfVersion(ctx context.Context, entry *models.GroceryEntry, version uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rowsAffected, err := deleteGroceryEntries(tx, entry.GuildID, "id = ? AND version = ?", entry.ID, version)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrVersionMismatch
+		}
+		return nil
+	})
 }
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GroceryEntryChangeRepository = &GroceryEntryChangeRepositoryImpl{}
+
+// GroceryEntryChangeRepository is append-only - changes are only ever deleted through !groreset.
+type GroceryEntryChangeRepository interface {
+	Create(ctx context.Context, changes []models.GroceryEntryChange) error
+	// FindByGroceryList returns the latest changes for a grocery list (nil for the default list), newest first.
+	FindByGroceryList(ctx context.Context, guildID string, groceryListID *uint, limit int) ([]models.GroceryEntryChange, error)
+	// FindByGroceryEntry returns all changes for a grocery entry, oldest first.
+	FindByGroceryEntry(ctx context.Context, guildID string, groceryEntryID uint) ([]models.GroceryEntryChange, error)
+	// FindByActionBetween returns the changes of every guild with the given action, made after since and up to until, oldest first.
+	FindByActionBetween(ctx context.Context, action string, since time.Time, until time.Time) ([]models.GroceryEntryChange, error)
+}
+
+type GroceryEntryChangeRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GroceryEntryChangeRepositoryImpl) Create(ctx context.Context, changes []models.GroceryEntryChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.DB.WithContext(ctx).Create(&changes).Error
+}
+
+func (r *GroceryEntryChangeRepositoryImpl) FindByGroceryList(ctx context.Context, guildID string, groceryListID *uint, limit int) ([]models.GroceryEntryChange, error) {
+	changes := make([]models.GroceryEntryChange, 0)
+	q := r.DB.WithContext(ctx).Where("guild_id = ?", guildID)
+	if groceryListID != nil {
+		q = q.Where("grocery_list_id = ?", *groceryListID)
+	} else {
+		q = q.Where(queryGroceryListIDIsNil)
+	}
+	if err := q.Order("id DESC").Limit(limit).Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *GroceryEntryChangeRepositoryImpl) FindByGroceryEntry(ctx context.Context, guildID string, groceryEntryID uint) ([]models.GroceryEntryChange, error) {
+	changes := make([]models.GroceryEntryChange, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND grocery_entry_id = ?", guildID, groceryEntryID).Order("id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *GroceryEntryChangeRepositoryImpl) FindByActionBetween(ctx context.Context, action string, since time.Time, until time.Time) ([]models.GroceryEntryChange, error) {
+	changes := make([]models.GroceryEntryChange, 0)
+	if err := r.DB.WithContext(ctx).Where("action = ? AND created_at > ? AND created_at <= ?", action, since, until).Order("id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/verzac/grocer-discord-bot/models"
 	"gorm.io/gorm"
@@ -19,10 +20,17 @@ type GroceryListRepository interface {
 	WithContext(ctx context.Context) GroceryListRepository
 	GetByQuery(q *models.GroceryList) (*models.GroceryList, error)
 	FindByQuery(q *models.GroceryList) ([]models.GroceryList, error)
+	FindByIDs(ids []uint) ([]models.GroceryList, error)
 	Count(q *models.GroceryList) (existingCount int64, err error)
 	CreateGroceryList(guildID string, listLabel string, fancyName string) (*models.GroceryList, error)
 	Delete(groceryList *models.GroceryList) error
 	Save(groceryList *models.GroceryList) error
+	// FindUpdatedSince returns the guild's grocery lists that have been created or updated after since (see GET /sync).
+	FindUpdatedSince(ctx context.Context, guildID string, since time.Time) ([]models.GroceryList, error)
+	// SaveIfVersion saves the list only if it hasn't been changed since it was at version, otherwise it returns ErrVersionMismatch.
+	SaveIfVersion(groceryList *models.GroceryList, version uint) error
+	// DeleteIfVersion deletes the list only if it hasn't been changed since it was at version, otherwise it returns ErrVersionMismatch.
+	DeleteIfVersion(groceryList *models.GroceryList, version uint) error
 }
 
 type GroceryListRepositoryImpl struct {
@@ -55,6 +63,17 @@ func (r *GroceryListRepositoryImpl) FindByQuery(q *models.GroceryList) ([]models
 	return gLists, nil
 }
 
+func (r *GroceryListRepositoryImpl) FindByIDs(ids []uint) ([]models.GroceryList, error) {
+	gLists := make([]models.GroceryList, 0, len(ids))
+	if len(ids) == 0 {
+		return gLists, nil
+	}
+	if res := r.DB.Where("id IN ?", ids).Order("id").Find(&gLists); res.Error != nil {
+		return nil, res.Error
+	}
+	return gLists, nil
+}
+
 func (r *GroceryListRepositoryImpl) Count(q *models.GroceryList) (existingCount int64, err error) {
 	countR := r.DB.Model(&models.GroceryList{}).Where(q).Count(&existingCount)
 	if countR.Error != nil && countR.Error != gorm.ErrRecordNotFound {
@@ -76,6 +95,7 @@ func (r *GroceryListRepositoryImpl) CreateGroceryList(guildID string, listLabel
 	newGroceryList := models.GroceryList{
 		GuildID:   guildID,
 		ListLabel: listLabel,
+		Version:   1,
 	}
 	if fancyName == "" {
 		newGroceryList.FancyName = nil
@@ -90,16 +110,52 @@ func (r *GroceryListRepositoryImpl) CreateGroceryList(guildID string, listLabel
 }
 
 func (r *GroceryListRepositoryImpl) Delete(groceryList *models.GroceryList) error {
-	res := r.DB.Delete(groceryList)
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(groceryList)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrGroceryListNotFound
+		}
+		return createTombstones(tx, groceryList.GuildID, models.TombstoneEntityGroceryList, []uint{groceryList.ID})
+	})
+}
+
+func (r *GroceryListRepositoryImpl) DeleteIfVersion(groceryList *models.GroceryList, version uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("version = ?", version).Delete(groceryList)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionMismatch
+		}
+		return createTombstones(tx, groceryList.GuildID, models.TombstoneEntityGroceryList, []uint{groceryList.ID})
+	})
+}
+
+func (r *GroceryListRepositoryImpl) Save(groceryList *models.GroceryList) error {
+	groceryList.Version++
+	return r.DB.Save(groceryList).Error
+}
+
+func (r *GroceryListRepositoryImpl) SaveIfVersion(groceryList *models.GroceryList, version uint) error {
+	groceryList.Version = version + 1
+	res := r.DB.Model(groceryList).Where("version = ?", version).Select("*").Omit("CreatedAt").Updates(groceryList)
 	if res.Error != nil {
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return ErrGroceryListNotFound
+		return ErrVersionMismatch
 	}
 	return nil
 }
 
-func (r *GroceryListRepositoryImpl) Save(groceryList *models.GroceryList) error {
-	return r.DB.Save(groceryList).Error
+func (r *GroceryListRepositoryImpl) FindUpdatedSince(ctx context.Context, guildID string, since time.Time) ([]models.GroceryList, error) {
+	gLists := make([]models.GroceryList, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND updated_at > ?", guildID, since).Find(&gLists).Error; err != nil {
+		return nil, err
+	}
+	return gLists, nil
 }
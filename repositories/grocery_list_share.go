@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GroceryListShareRepository = &GroceryListShareRepositoryImpl{}
+
+type GroceryListShareRepository interface {
+	Create(ctx context.Context, share *models.GroceryListShare) error
+	// GetByGroceryListAndGuild returns nil if the grocery list hasn't been shared into the guild.
+	GetByGroceryListAndGuild(ctx context.Context, groceryListID uint, guildID string) (*models.GroceryListShare, error)
+	FindByGroceryListID(ctx context.Context, groceryListID uint) ([]models.GroceryListShare, error)
+	// FindByGuildID returns the shares of other guilds' grocery lists that the guild has joined.
+	FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryListShare, error)
+	// Delete removes the grocery list from the guild, leaving tombstones behind in the guild for the list & its entries (see GET /sync).
+	Delete(ctx context.Context, share *models.GroceryListShare) error
+	// DeleteByGroceryListID removes the grocery list from every guild it has been shared into, along with its invites.
+	DeleteByGroceryListID(ctx context.Context, groceryListID uint) error
+	CreateInvite(ctx context.Context, invite *models.GroceryListInvite) error
+	// GetInvite returns nil if there's no such invite (expired or not).
+	GetInvite(ctx context.Context, code string) (*models.GroceryListInvite, error)
+	// TakeInvite deletes the invite so that it can only be used once. Returns nil if there's no such invite (expired or not).
+	TakeInvite(ctx context.Context, code string) (*models.GroceryListInvite, error)
+	DeleteInvitesExpiredBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error)
+}
+
+type GroceryListShareRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GroceryListShareRepositoryImpl) Create(ctx context.Context, share *models.GroceryListShare) error {
+	return r.DB.WithContext(ctx).Create(share).Error
+}
+
+func (r *GroceryListShareRepositoryImpl) GetByGroceryListAndGuild(ctx context.Context, groceryListID uint, guildID string) (*models.GroceryListShare, error) {
+	share := &models.GroceryListShare{}
+	if err := r.DB.WithContext(ctx).Where("grocery_list_id = ? AND guild_id = ?", groceryListID, guildID).Take(share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return share, nil
+}
+
+func (r *GroceryListShareRepositoryImpl) FindByGroceryListID(ctx context.Context, groceryListID uint) ([]models.GroceryListShare, error) {
+	shares := make([]models.GroceryListShare, 0)
+	if err := r.DB.WithContext(ctx).Where("grocery_list_id = ?", groceryListID).Order("id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (r *GroceryListShareRepositoryImpl) FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryListShare, error) {
+	shares := make([]models.GroceryListShare, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ?", guildID).Order("id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (r *GroceryListShareRepositoryImpl) Delete(ctx context.Context, share *models.GroceryListShare) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(share).Error; err != nil {
+			return err
+		}
+		entryIDs := make([]uint, 0)
+		if err := tx.Model(&models.GroceryEntry{}).Where("grocery_list_id = ?", share.GroceryListID).Pluck("id", &entryIDs).Error; err != nil {
+			return err
+		}
+		if err := createTombstones(tx, share.GuildID, models.TombstoneEntityGroceryEntry, entryIDs); err != nil {
+			return err
+		}
+		return createTombstones(tx, share.GuildID, models.TombstoneEntityGroceryList, []uint{share.GroceryListID})
+	})
+}
+
+func (r *GroceryListShareRepositoryImpl) DeleteByGroceryListID(ctx context.Context, groceryListID uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("grocery_list_id = ?", groceryListID).Delete(&models.GroceryListShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("grocery_list_id = ?", groceryListID).Delete(&models.GroceryListInvite{}).Error
+	})
+}
+
+func (r *GroceryListShareRepositoryImpl) CreateInvite(ctx context.Context, invite *models.GroceryListInvite) error {
+	return r.DB.WithContext(ctx).Create(invite).Error
+}
+
+func (r *GroceryListShareRepositoryImpl) GetInvite(ctx context.Context, code string) (*models.GroceryListInvite, error) {
+	invite := &models.GroceryListInvite{}
+	if err := r.DB.WithContext(ctx).Where("code = ?", code).Take(invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invite, nil
+}
+
+func (r *GroceryListShareRepositoryImpl) TakeInvite(ctx context.Context, code string) (*models.GroceryListInvite, error) {
+	invite := &models.GroceryListInvite{}
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code = ?", code).Take(invite).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(invite)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// someone else got to it first
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invite, nil
+}
+
+func (r *GroceryListShareRepositoryImpl) DeleteInvitesExpiredBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where("expires_at <= ?", before).Delete(&models.GroceryListInvite{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GroceryReminderRepository = &GroceryReminderRepositoryImpl{}
+
+type GroceryReminderRepository interface {
+	Create(ctx context.Context, reminder *models.GroceryReminder) error
+	// UpdateRunTimes saves the reminder's NextRunAt & LastRunAt (and nothing else).
+	UpdateRunTimes(ctx context.Context, reminder *models.GroceryReminder) error
+	Delete(ctx context.Context, reminder *models.GroceryReminder) error
+	// FindByGuildID returns the guild's reminders for all of its grocery lists, oldest first.
+	FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryReminder, error)
+	// FindDue returns the reminders of every guild that are due at (or before) now.
+	FindDue(ctx context.Context, now time.Time) ([]models.GroceryReminder, error)
+	DeleteByGroceryListID(ctx context.Context, groceryListID uint) error
+}
+
+type GroceryReminderRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GroceryReminderRepositoryImpl) Create(ctx context.Context, reminder *models.GroceryReminder) error {
+	return r.DB.WithContext(ctx).Create(reminder).Error
+}
+
+func (r *GroceryReminderRepositoryImpl) UpdateRunTimes(ctx context.Context, reminder *models.GroceryReminder) error {
+	return r.DB.WithContext(ctx).Model(reminder).Select("next_run_at", "last_run_at").Updates(reminder).Error
+}
+
+func (r *GroceryReminderRepositoryImpl) Delete(ctx context.Context, reminder *models.GroceryReminder) error {
+	return r.DB.WithContext(ctx).Delete(reminder).Error
+}
+
+func (r *GroceryReminderRepositoryImpl) FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryReminder, error) {
+	reminders := make([]models.GroceryReminder, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ?", guildID).Order("id").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *GroceryReminderRepositoryImpl) FindDue(ctx context.Context, now time.Time) ([]models.GroceryReminder, error) {
+	reminders := make([]models.GroceryReminder, 0)
+	if err := r.DB.WithContext(ctx).Where("next_run_at <= ?", now).Order("next_run_at").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *GroceryReminderRepositoryImpl) DeleteByGroceryListID(ctx context.Context, groceryListID uint) error {
+	return r.DB.WithContext(ctx).Where("grocery_list_id = ?", groceryListID).Delete(&models.GroceryReminder{}).Error
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GroceryStapleRepository = &GroceryStapleRepositoryImpl{}
+
+type GroceryStapleRepository interface {
+	Create(ctx context.Context, staple *models.GroceryStaple) error
+	// UpdateRunTimes saves the staple's NextRunAt & LastRunAt (and nothing else).
+	UpdateRunTimes(ctx context.Context, staple *models.GroceryStaple) error
+	Delete(ctx context.Context, staple *models.GroceryStaple) error
+	// FindByGroceryList returns the staples of a grocery list (nil for the default list), oldest first.
+	FindByGroceryList(ctx context.Context, guildID string, groceryListID *uint) ([]models.GroceryStaple, error)
+	// FindDue returns the staples on a timer that are due at (or before) now.
+	FindDue(ctx context.Context, now time.Time) ([]models.GroceryStaple, error)
+	FindBySchedule(ctx context.Context, schedule string) ([]models.GroceryStaple, error)
+	UpdateLastRunAt(ctx context.Context, ids []uint, lastRunAt time.Time) error
+	DeleteByGroceryListID(ctx context.Context, groceryListID uint) error
+}
+
+type GroceryStapleRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GroceryStapleRepositoryImpl) Create(ctx context.Context, staple *models.GroceryStaple) error {
+	return r.DB.WithContext(ctx).Create(staple).Error
+}
+
+func (r *GroceryStapleRepositoryImpl) UpdateRunTimes(ctx context.Context, staple *models.GroceryStaple) error {
+	return r.DB.WithContext(ctx).Model(staple).Select("next_run_at", "last_run_at").Updates(staple).Error
+}
+
+func (r *GroceryStapleRepositoryImpl) Delete(ctx context.Context, staple *models.GroceryStaple) error {
+	return r.DB.WithContext(ctx).Delete(staple).Error
+}
+
+func (r *GroceryStapleRepositoryImpl) FindByGroceryList(ctx context.Context, guildID string, groceryListID *uint) ([]models.GroceryStaple, error) {
+	staples := make([]models.GroceryStaple, 0)
+	q := r.DB.WithContext(ctx).Where("guild_id = ?", guildID)
+	if groceryListID != nil {
+		q = q.Where("grocery_list_id = ?", *groceryListID)
+	} else {
+		q = q.Where(queryGroceryListIDIsNil)
+	}
+	if err := q.Order("id").Find(&staples).Error; err != nil {
+		return nil, err
+	}
+	return staples, nil
+}
+
+func (r *GroceryStapleRepositoryImpl) FindDue(ctx context.Context, now time.Time) ([]models.GroceryStaple, error) {
+	staples := make([]models.GroceryStaple, 0)
+	if err := r.DB.WithContext(ctx).Where("next_run_at <= ?", now).Order("id").Find(&staples).Error; err != nil {
+		return nil, err
+	}
+	return staples, nil
+}
+
+func (r *GroceryStapleRepositoryImpl) FindBySchedule(ctx context.Context, schedule string) ([]models.GroceryStaple, error) {
+	staples := make([]models.GroceryStaple, 0)
+	if err := r.DB.WithContext(ctx).Where("schedule = ?", schedule).Order("id").Find(&staples).Error; err != nil {
+		return nil, err
+	}
+	return staples, nil
+}
+
+func (r *GroceryStapleRepositoryImpl) UpdateLastRunAt(ctx context.Context, ids []uint, lastRunAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.WithContext(ctx).Model(&models.GroceryStaple{}).Where("id IN ?", ids).Update("last_run_at", lastRunAt).Error
+}
+
+func (r *GroceryStapleRepositoryImpl) DeleteByGroceryListID(ctx context.Context, groceryListID uint) error {
+	return r.DB.WithContext(ctx).Where("grocery_list_id = ?", groceryListID).Delete(&models.GroceryStaple{}).Error
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GroceryTemplateRepository = &GroceryTemplateRepositoryImpl{}
+
+type GroceryTemplateRepository interface {
+	Create(ctx context.Context, template *models.GroceryTemplate) error
+	// UpdateItems saves the template's items (and nothing else).
+	UpdateItems(ctx context.Context, template *models.GroceryTemplate) error
+	Delete(ctx context.Context, template *models.GroceryTemplate) error
+	// FindByGuildID returns the guild's templates by name.
+	FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryTemplate, error)
+	// GetByName returns nil if the guild doesn't have a template with that (normalised) name.
+	GetByName(ctx context.Context, guildID string, name string) (*models.GroceryTemplate, error)
+	// GetByID returns nil if the guild doesn't have a template with that ID.
+	GetByID(ctx context.Context, guildID string, id uint) (*models.GroceryTemplate, error)
+}
+
+type GroceryTemplateRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GroceryTemplateRepositoryImpl) Create(ctx context.Context, template *models.GroceryTemplate) error {
+	return r.DB.WithContext(ctx).Create(template).Error
+}
+
+func (r *GroceryTemplateRepositoryImpl) UpdateItems(ctx context.Context, template *models.GroceryTemplate) error {
+	return r.DB.WithContext(ctx).Model(template).Select("items", "updated_at").Updates(template).Error
+}
+
+func (r *GroceryTemplateRepositoryImpl) Delete(ctx context.Context, template *models.GroceryTemplate) error {
+	return r.DB.WithContext(ctx).Delete(template).Error
+}
+
+func (r *GroceryTemplateRepositoryImpl) FindByGuildID(ctx context.Context, guildID string) ([]models.GroceryTemplate, error) {
+	templates := make([]models.GroceryTemplate, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ?", guildID).Order("name").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *GroceryTemplateRepositoryImpl) GetByName(ctx context.Context, guildID string, name string) (*models.GroceryTemplate, error) {
+	return r.getByQuery(ctx, &models.GroceryTemplate{GuildID: guildID, Name: name})
+}
+
+func (r *GroceryTemplateRepositoryImpl) GetByID(ctx context.Context, guildID string, id uint) (*models.GroceryTemplate, error) {
+	return r.getByQuery(ctx, &models.GroceryTemplate{GuildID: guildID, ID: id})
+}
+
+func (r *GroceryTemplateRepositoryImpl) getByQuery(ctx context.Context, q *models.GroceryTemplate) (*models.GroceryTemplate, error) {
+	template := models.GroceryTemplate{}
+	if res := r.DB.WithContext(ctx).Where(q).Take(&template); res.Error != nil {
+		if res.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, res.Error
+	}
+	return &template, nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ GrolistReactionMessageRepository = &GrolistReactionMessageRepositoryImpl{}
+
+type GrolistReactionMessageRepository interface {
+	Put(ctx context.Context, m *models.GrolistReactionMessage) error
+	// GetByMessageID returns nil if the message isn't a !grolist reply with reactions.
+	GetByMessageID(ctx context.Context, messageID string) (*models.GrolistReactionMessage, error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error)
+}
+
+type GrolistReactionMessageRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *GrolistReactionMessageRepositoryImpl) Put(ctx context.Context, m *models.GrolistReactionMessage) error {
+	return r.DB.WithContext(ctx).Save(m).Error
+}
+
+func (r *GrolistReactionMessageRepositoryImpl) GetByMessageID(ctx context.Context, messageID string) (*models.GrolistReactionMessage, error) {
+	m := &models.GrolistReactionMessage{}
+	if err := r.DB.WithContext(ctx).Where("message_id = ?", messageID).Take(m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *GrolistReactionMessageRepositoryImpl) DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where("created_at <= ?", before).Delete(&models.GrolistReactionMessage{})
+	return res.RowsAffected, res.Error
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
 	"github.com/verzac/grocer-discord-bot/models"
@@ -15,6 +16,13 @@ type GuildConfigRepository interface {
 	WithTX(db *gorm.DB) GuildConfigRepository
 	Commit() error
 	Rollback() error
+	FindCategoryOverrides(ctx context.Context, guildID string) ([]models.GuildCategoryOverride, error)
+	PutCategoryOverride(ctx context.Context, o *models.GuildCategoryOverride) error
+	DeleteCategoryOverride(ctx context.Context, guildID string, keyword string) (rowsAffected int64, err error)
+	// FindRolePermissions returns the guild's role permissions, optionally only for a capability (if capability != "").
+	FindRolePermissions(ctx context.Context, guildID string, capability string) ([]models.GuildRolePermission, error)
+	PutRolePermission(ctx context.Context, p *models.GuildRolePermission) error
+	DeleteRolePermission(ctx context.Context, guildID string, roleID string, capability string) (rowsAffected int64, err error)
 }
 
 type GuildConfigRepositoryImpl struct {
@@ -53,3 +61,57 @@ func (r *GuildConfigRepositoryImpl) Put(g *models.GuildConfig) error {
 	}
 	return nil
 }
+
+func (r *GuildConfigRepositoryImpl) FindCategoryOverrides(ctx context.Context, guildID string) ([]models.GuildCategoryOverride, error) {
+	overrides := make([]models.GuildCategoryOverride, 0)
+	if err := r.DB.WithContext(ctx).Where(&models.GuildCategoryOverride{GuildID: guildID}).Order("keyword").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// PutCategoryOverride creates or replaces the override for o.Keyword in the guild.
+func (r *GuildConfigRepositoryImpl) PutCategoryOverride(ctx context.Context, o *models.GuildCategoryOverride) error {
+	existing := &models.GuildCategoryOverride{}
+	err := r.DB.WithContext(ctx).Where(&models.GuildCategoryOverride{GuildID: o.GuildID, Keyword: o.Keyword}).Take(existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		o.ID = existing.ID
+		o.CreatedAt = existing.CreatedAt
+	}
+	return r.DB.WithContext(ctx).Save(o).Error
+}
+
+func (r *GuildConfigRepositoryImpl) DeleteCategoryOverride(ctx context.Context, guildID string, keyword string) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where(&models.GuildCategoryOverride{GuildID: guildID, Keyword: keyword}).Delete(&models.GuildCategoryOverride{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *GuildConfigRepositoryImpl) FindRolePermissions(ctx context.Context, guildID string, capability string) ([]models.GuildRolePermission, error) {
+	permissions := make([]models.GuildRolePermission, 0)
+	if err := r.DB.WithContext(ctx).Where(&models.GuildRolePermission{GuildID: guildID, Capability: capability}).Order("id").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// PutRolePermission grants p.Capability to p.RoleID, doing nothing if it's already been granted.
+func (r *GuildConfigRepositoryImpl) PutRolePermission(ctx context.Context, p *models.GuildRolePermission) error {
+	existing := &models.GuildRolePermission{}
+	err := r.DB.WithContext(ctx).Where(&models.GuildRolePermission{GuildID: p.GuildID, RoleID: p.RoleID, Capability: p.Capability}).Take(existing).Error
+	if err == nil {
+		*p = *existing
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return r.DB.WithContext(ctx).Create(p).Error
+}
+
+func (r *GuildConfigRepositoryImpl) DeleteRolePermission(ctx context.Context, guildID string, roleID string, capability string) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where(&models.GuildRolePermission{GuildID: guildID, RoleID: roleID, Capability: capability}).Delete(&models.GuildRolePermission{})
+	return res.RowsAffected, res.Error
+}
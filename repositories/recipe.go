@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var _ RecipeRepository = &RecipeRepositoryImpl{}
+
+type RecipeRepository interface {
+	Create(ctx context.Context, recipe *models.Recipe) error
+	// Update saves everything about the recipe except for its guild, name and creator.
+	Update(ctx context.Context, recipe *models.Recipe) error
+	Delete(ctx context.Context, recipe *models.Recipe) error
+	// FindByGuildID returns the guild's recipes by name.
+	FindByGuildID(ctx context.Context, guildID string) ([]models.Recipe, error)
+	// GetByName returns nil if the guild doesn't have a recipe with that (normalised) name.
+	GetByName(ctx context.Context, guildID string, name string) (*models.Recipe, error)
+	// GetByID returns nil if the guild doesn't have a recipe with that ID.
+	GetByID(ctx context.Context, guildID string, id uint) (*models.Recipe, error)
+}
+
+type RecipeRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *RecipeRepositoryImpl) Create(ctx context.Context, recipe *models.Recipe) error {
+	return r.DB.WithContext(ctx).Create(recipe).Error
+}
+
+func (r *RecipeRepositoryImpl) Update(ctx context.Context, recipe *models.Recipe) error {
+	return r.DB.WithContext(ctx).Model(recipe).Select("title", "source_url", "ingredients", "servings", "updated_at").Updates(recipe).Error
+}
+
+func (r *RecipeRepositoryImpl) Delete(ctx context.Context, recipe *models.Recipe) error {
+	return r.DB.WithContext(ctx).Delete(recipe).Error
+}
+
+func (r *RecipeRepositoryImpl) FindByGuildID(ctx context.Context, guildID string) ([]models.Recipe, error) {
+	recipes := make([]models.Recipe, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ?", guildID).Order("name").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+func (r *RecipeRepositoryImpl) GetByName(ctx context.Context, guildID string, name string) (*models.Recipe, error) {
+	return r.getByQuery(ctx, &models.Recipe{GuildID: guildID, Name: name})
+}
+
+func (r *RecipeRepositoryImpl) GetByID(ctx context.Context, guildID string, id uint) (*models.Recipe, error) {
+	return r.getByQuery(ctx, &models.Recipe{GuildID: guildID, ID: id})
+}
+
+func (r *RecipeRepositoryImpl) getByQuery(ctx context.Context, q *models.Recipe) (*models.Recipe, error) {
+	recipe := models.Recipe{}
+	if res := r.DB.WithContext(ctx).Where(q).Take(&recipe); res.Error != nil {
+		if res.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, res.Error
+	}
+	return &recipe, nil
+}
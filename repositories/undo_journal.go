@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUndoJournalEntryNotFound = errors.New("Cannot find anything to undo.")
+)
+
+var _ UndoJournalRepository = &UndoJournalRepositoryImpl{}
+
+type UndoJournalRepository interface {
+	// Create records a journal entry, and only keeps the latest keepLatest entries for the guild.
+	Create(ctx context.Context, e *models.UndoJournalEntry, keepLatest int) error
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error)
+	GetLatest(ctx context.Context, guildID string, since time.Time) (*models.UndoJournalEntry, error)
+	GetByID(ctx context.Context, guildID string, id uint, since time.Time) (*models.UndoJournalEntry, error)
+	// Restore puts the snapshot of the journal entry back, then deletes the journal entry. Returns the restored entries.
+	Restore(ctx context.Context, e *models.UndoJournalEntry) ([]models.GroceryEntry, error)
+}
+
+type UndoJournalRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *UndoJournalRepositoryImpl) Create(ctx context.Context, e *models.UndoJournalEntry, keepLatest int) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+		// trim the journal down to the latest entries
+		keepIDs := make([]uint, 0, keepLatest)
+		if err := tx.Model(&models.UndoJournalEntry{}).Where("guild_id = ?", e.GuildID).Order("id DESC").Limit(keepLatest).Pluck("id", &keepIDs).Error; err != nil {
+			return err
+		}
+		return tx.Where("guild_id = ? AND id NOT IN ?", e.GuildID, keepIDs).Delete(&models.UndoJournalEntry{}).Error
+	})
+}
+
+func (r *UndoJournalRepositoryImpl) DeleteCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where("created_at <= ?", before).Delete(&models.UndoJournalEntry{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *UndoJournalRepositoryImpl) GetLatest(ctx context.Context, guildID string, since time.Time) (*models.UndoJournalEntry, error) {
+	e := &models.UndoJournalEntry{}
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND created_at > ?", guildID, since).Order("id DESC").Take(e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *UndoJournalRepositoryImpl) GetByID(ctx context.Context, guildID string, id uint, since time.Time) (*models.UndoJournalEntry, error) {
+	e := &models.UndoJournalEntry{}
+	if err := r.DB.WithContext(ctx).Where("id = ? AND guild_id = ? AND created_at > ?", id, guildID, since).Take(e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *UndoJournalRepositoryImpl) Restore(ctx context.Context, e *models.UndoJournalEntry) ([]models.GroceryEntry, error) {
+	snapshot, err := e.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	entries := snapshot.GroceryEntries
+	err = r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// claim the journal entry first so that the same operation can't be undone twice
+		res := tx.Where("id = ? AND guild_id = ?", e.ID, e.GuildID).Delete(&models.UndoJournalEntry{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUndoJournalEntryNotFound
+		}
+
+		// grocery lists are only snapshotted on resets; if a list with the same label has been created since, its entries go there
+		listIDMapping := make(map[uint]*uint, len(snapshot.GroceryLists))
+		for _, gl := range snapshot.GroceryLists {
+			oldID := gl.ID
+			existing := make([]models.GroceryList, 0, 1)
+			if err := tx.Where("guild_id = ? AND list_label = ?", e.GuildID, gl.ListLabel).Limit(1).Find(&existing).Error; err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				existingID := existing[0].ID
+				listIDMapping[oldID] = &existingID
+				continue
+			}
+			var takenCount int64
+			if err := tx.Model(&models.GroceryList{}).Where("id = ?", gl.ID).Count(&takenCount).Error; err != nil {
+				return err
+			}
+			if takenCount > 0 {
+				gl.ID = 0
+			}
+			gl.GuildID = e.GuildID
+			// bump updated_at so that GET /sync sends the list back to clients
+			gl.UpdatedAt = time.Time{}
+			if err := tx.Create(&gl).Error; err != nil {
+				return err
+			}
+			newID := gl.ID
+			listIDMapping[oldID] = &newID
+		}
+
+		if e.Operation == models.UndoOperationReplace {
+			// put the list back the way it was, rather than adding the old entries to the new ones
+			var err error
+			if e.GroceryListID != nil {
+				_, err = deleteGroceryEntries(tx, e.GuildID, "grocery_list_id = ?", *e.GroceryListID)
+			} else {
+				_, err = deleteGroceryEntries(tx, e.GuildID, queryGroceryListIDIsNil)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(entries))
+		for i := range entries {
+			ids = append(ids, entries[i].ID)
+		}
+		takenIDs := make([]uint, 0)
+		if err := tx.Model(&models.GroceryEntry{}).Where("id IN ?", ids).Pluck("id", &takenIDs).Error; err != nil {
+			return err
+		}
+		isTaken := make(map[uint]bool, len(takenIDs))
+		for _, id := range takenIDs {
+			isTaken[id] = true
+		}
+		for i := range entries {
+			// re-using the old ID (if it's still free) keeps the entry in the same position on the list
+			if isTaken[entries[i].ID] {
+				entries[i].ID = 0
+			}
+			entries[i].GuildID = e.GuildID
+			entries[i].GroceryList = nil
+			entries[i].UpdatedAt = time.Time{}
+			if entries[i].GroceryListID == nil {
+				continue
+			}
+			if newID, ok := listIDMapping[*entries[i].GroceryListID]; ok {
+				entries[i].GroceryListID = newID
+				continue
+			}
+			var listCount int64
+			if err := tx.Model(&models.GroceryList{}).Where("id = ? AND guild_id = ?", *entries[i].GroceryListID, e.GuildID).Count(&listCount).Error; err != nil {
+				return err
+			}
+			if listCount == 0 {
+				// the list has been deleted since, so the entry goes back into the default list instead
+				listIDMapping[*entries[i].GroceryListID] = nil
+				entries[i].GroceryListID = nil
+				continue
+			}
+			listIDMapping[*entries[i].GroceryListID] = entries[i].GroceryListID
+		}
+		return tx.Omit("GroceryList").Create(&entries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
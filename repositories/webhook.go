@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("Cannot find a webhook with that ID.")
+)
+
+var _ WebhookRepository = &WebhookRepositoryImpl{}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, s *models.WebhookSubscription) error
+	FindByGuildID(ctx context.Context, guildID string) ([]models.WebhookSubscription, error)
+	GetByID(ctx context.Context, guildID string, id uint) (*models.WebhookSubscription, error)
+	// Delete deletes the webhook subscription along with its delivery log. Returns ErrWebhookNotFound if there's nothing to delete.
+	Delete(ctx context.Context, guildID string, id uint) error
+	CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error
+	// FindDeliveries returns the latest delivery attempts for a webhook subscription, newest first.
+	FindDeliveries(ctx context.Context, guildID string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error)
+	DeleteDeliveriesCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error)
+}
+
+type WebhookRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func (r *WebhookRepositoryImpl) Create(ctx context.Context, s *models.WebhookSubscription) error {
+	return r.DB.WithContext(ctx).Create(s).Error
+}
+
+func (r *WebhookRepositoryImpl) FindByGuildID(ctx context.Context, guildID string) ([]models.WebhookSubscription, error) {
+	subscriptions := make([]models.WebhookSubscription, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ?", guildID).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookRepositoryImpl) GetByID(ctx context.Context, guildID string, id uint) (*models.WebhookSubscription, error) {
+	s := &models.WebhookSubscription{}
+	if err := r.DB.WithContext(ctx).Where("id = ? AND guild_id = ?", id, guildID).Take(s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *WebhookRepositoryImpl) Delete(ctx context.Context, guildID string, id uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND guild_id = ?", id, guildID).Delete(&models.WebhookSubscription{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("webhook_subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (r *WebhookRepositoryImpl) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	return r.DB.WithContext(ctx).Create(d).Error
+}
+
+func (r *WebhookRepositoryImpl) FindDeliveries(ctx context.Context, guildID string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := make([]models.WebhookDelivery, 0)
+	if err := r.DB.WithContext(ctx).Where("guild_id = ? AND webhook_subscription_id = ?", guildID, subscriptionID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepositoryImpl) DeleteDeliveriesCreatedBefore(ctx context.Context, before time.Time) (rowsAffected int64, err error) {
+	res := r.DB.WithContext(ctx).Where("created_at <= ?", before).Delete(&models.WebhookDelivery{})
+	return res.RowsAffected, res.Error
+}
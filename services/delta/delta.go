@@ -0,0 +1,258 @@
+package delta
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"go.uber.org/zap"
+)
+
+func (s *DeltaServiceImpl) GetChangesSince(ctx context.Context, m *dto.MemberContext, cursor string) (*dto.SyncResponse, error) {
+	out, err := s.getChangesSince(ctx, m.GuildID, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hideInvisibleGroceryLists(ctx, m, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// hideInvisibleGroceryLists leaves out the grocery lists that the member cannot see, along with their entries.
+// Lists that have just been hidden from the member (e.g. by being made private) are sent as deleted, so that clients drop them.
+func (s *DeltaServiceImpl) hideInvisibleGroceryLists(ctx context.Context, m *dto.MemberContext, out *dto.SyncResponse) error {
+	groceryLists, err := s.groceryListRepo.WithContext(ctx).FindByQuery(&models.GroceryList{GuildID: m.GuildID})
+	if err != nil {
+		return err
+	}
+	isHidden := make(map[uint]bool)
+	for i := range groceryLists {
+		canSee, err := grocery.Service.CanSeeGroceryList(ctx, m, &groceryLists[i])
+		if err != nil {
+			return err
+		}
+		if !canSee {
+			isHidden[groceryLists[i].ID] = true
+		}
+	}
+	if len(isHidden) == 0 {
+		return nil
+	}
+	visibleLists := make([]models.GroceryList, 0, len(out.GroceryLists))
+	for _, gl := range out.GroceryLists {
+		if !isHidden[gl.ID] {
+			visibleLists = append(visibleLists, gl)
+		} else if !out.FullSync {
+			out.DeletedGroceryListIDs = append(out.DeletedGroceryListIDs, gl.ID)
+		}
+	}
+	visibleEntries := make([]models.GroceryEntry, 0, len(out.GroceryEntries))
+	for _, g := range out.GroceryEntries {
+		if g.GroceryListID == nil || !isHidden[*g.GroceryListID] {
+			visibleEntries = append(visibleEntries, g)
+		} else if !out.FullSync {
+			// e.g. moved into a private list
+			out.DeletedGroceryEntryIDs = append(out.DeletedGroceryEntryIDs, g.ID)
+		}
+	}
+	out.GroceryLists = visibleLists
+	out.GroceryEntries = visibleEntries
+	return nil
+}
+
+func (s *DeltaServiceImpl) getChangesSince(ctx context.Context, guildID string, cursor string) (*dto.SyncResponse, error) {
+	// take the cursor before reading anything, so that changes made while we're reading are sent again next time
+	now := time.Now()
+	out := &dto.SyncResponse{
+		Cursor:                 strconv.FormatInt(now.Add(-cursorOverlap).UnixNano(), 10),
+		DeletedGroceryEntryIDs: []uint{},
+		DeletedGroceryListIDs:  []uint{},
+	}
+	fullSync := cursor == ""
+	var since time.Time
+	if !fullSync {
+		sinceNano, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || sinceNano <= 0 {
+			return nil, ErrInvalidCursor
+		}
+		since = time.Unix(0, sinceNano)
+		// tombstones older than this may have been pruned already
+		fullSync = since.Before(now.Add(-TombstoneRetention))
+	}
+	tombstones := make([]models.DeletionTombstone, 0)
+	if !fullSync {
+		var err error
+		tombstones, err = s.deletionTombstoneRepo.FindCreatedSince(ctx, guildID, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tombstones {
+			if t.EntityType == models.TombstoneEntityGuild {
+				// the guild has been reset since, so nothing that the client has is valid anymore
+				fullSync = true
+			}
+		}
+	}
+
+	if fullSync {
+		groceryEntries, err := s.groceryEntryRepo.WithContext(ctx).FindByQuery(&models.GroceryEntry{GuildID: guildID})
+		if err != nil {
+			return nil, err
+		}
+		groceryLists, err := s.groceryListRepo.WithContext(ctx).FindByQuery(&models.GroceryList{GuildID: guildID})
+		if err != nil {
+			return nil, err
+		}
+		out.FullSync = true
+		out.GroceryEntries = groceryEntries
+		out.GroceryLists = groceryLists
+		if _, err := s.addSharedGroceryLists(ctx, guildID, since, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	groceryEntries, err := s.groceryEntryRepo.FindUpdatedSince(ctx, guildID, since)
+	if err != nil {
+		return nil, err
+	}
+	groceryLists, err := s.groceryListRepo.FindUpdatedSince(ctx, guildID, since)
+	if err != nil {
+		return nil, err
+	}
+	out.GroceryEntries = groceryEntries
+	out.GroceryLists = groceryLists
+	sharedTombstones, err := s.addSharedGroceryLists(ctx, guildID, since, out)
+	if err != nil {
+		return nil, err
+	}
+	tombstones = append(tombstones, sharedTombstones...)
+
+	// IDs can come back after being deleted (e.g. through !groundo), in which case the client should keep them
+	isUpdated := make(map[string]map[uint]bool, 2)
+	isUpdated[models.TombstoneEntityGroceryEntry] = make(map[uint]bool, len(out.GroceryEntries))
+	for _, g := range out.GroceryEntries {
+		isUpdated[models.TombstoneEntityGroceryEntry][g.ID] = true
+	}
+	isUpdated[models.TombstoneEntityGroceryList] = make(map[uint]bool, len(out.GroceryLists))
+	for _, gl := range out.GroceryLists {
+		isUpdated[models.TombstoneEntityGroceryList][gl.ID] = true
+	}
+	for _, t := range tombstones {
+		updated, ok := isUpdated[t.EntityType]
+		if !ok || updated[t.EntityID] {
+			continue
+		}
+		// the same ID can be tombstoned more than once
+		updated[t.EntityID] = true
+		switch t.EntityType {
+		case models.TombstoneEntityGroceryEntry:
+			out.DeletedGroceryEntryIDs = append(out.DeletedGroceryEntryIDs, t.EntityID)
+		case models.TombstoneEntityGroceryList:
+			out.DeletedGroceryListIDs = append(out.DeletedGroceryListIDs, t.EntityID)
+		}
+	}
+	return out, nil
+}
+
+// addSharedGroceryLists adds the grocery lists that other guilds have shared with the guild (and their entries) to out.
+// Their deletions are recorded against the guilds that own them, so those are returned to be sent along with the guild's own tombstones.
+func (s *DeltaServiceImpl) addSharedGroceryLists(ctx context.Context, guildID string, since time.Time, out *dto.SyncResponse) ([]models.DeletionTombstone, error) {
+	shares, err := s.groceryListShareRepo.FindByGuildID(ctx, guildID)
+	if err != nil || len(shares) == 0 {
+		return nil, err
+	}
+	ids := make([]uint, len(shares))
+	joinedAt := make(map[uint]time.Time, len(shares))
+	for i, share := range shares {
+		ids[i] = share.GroceryListID
+		joinedAt[share.GroceryListID] = share.CreatedAt
+	}
+	groceryLists, err := s.groceryListRepo.WithContext(ctx).FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	tombstones := make([]models.DeletionTombstone, 0)
+	ownerGuildIDs := make([]string, 0)
+	isOwnerGuild := make(map[string]bool)
+	isSentInFull := make(map[uint]bool)
+	isShared := make(map[uint]bool)
+	for _, gl := range groceryLists {
+		if !isOwnerGuild[gl.GuildID] {
+			isOwnerGuild[gl.GuildID] = true
+			ownerGuildIDs = append(ownerGuildIDs, gl.GuildID)
+		}
+		if !gl.IsPublic() {
+			// lists are only shared while they're public (see FindSharedGroceryLists)
+			if !out.FullSync && gl.UpdatedAt.After(since) {
+				tombstones = append(tombstones, models.DeletionTombstone{EntityType: models.TombstoneEntityGroceryList, EntityID: gl.ID})
+			}
+			continue
+		}
+		if out.FullSync || joinedAt[gl.ID].After(since) {
+			// the client hasn't seen the list before, so it gets all of its entries
+			entries, err := s.groceryEntryRepo.WithContext(ctx).FindByQuery(&models.GroceryEntry{GuildID: gl.GuildID, GroceryListID: &gl.ID})
+			if err != nil {
+				return nil, err
+			}
+			out.GroceryLists = append(out.GroceryLists, gl)
+			out.GroceryEntries = append(out.GroceryEntries, entries...)
+			isSentInFull[gl.ID] = true
+			continue
+		}
+		isShared[gl.ID] = true
+		if gl.UpdatedAt.After(since) {
+			out.GroceryLists = append(out.GroceryLists, gl)
+		}
+	}
+	if out.FullSync {
+		return nil, nil
+	}
+	for _, ownerGuildID := range ownerGuildIDs {
+		entries, err := s.groceryEntryRepo.FindUpdatedSince(ctx, ownerGuildID, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range entries {
+			switch {
+			case g.GroceryListID != nil && isSentInFull[*g.GroceryListID]:
+			case g.GroceryListID != nil && isShared[*g.GroceryListID]:
+				out.GroceryEntries = append(out.GroceryEntries, g)
+			default:
+				// it may have been moved out of a shared list - IDs are unique across guilds, so clients ignore the ones they don't have
+				tombstones = append(tombstones, models.DeletionTombstone{EntityType: models.TombstoneEntityGroceryEntry, EntityID: g.ID})
+			}
+		}
+		ownerTombstones, err := s.deletionTombstoneRepo.FindCreatedSince(ctx, ownerGuildID, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ownerTombstones {
+			// a reset owner leaves tombstones for its shared lists in this guild instead (see ResetGuild)
+			if t.EntityType != models.TombstoneEntityGuild {
+				tombstones = append(tombstones, t)
+			}
+		}
+	}
+	return tombstones, nil
+}
+
+// pruneExpired deletes tombstones that are older than TombstoneRetention - clients that are that far behind get a full sync anyway
+func (s *DeltaServiceImpl) pruneExpired() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		rowsAffected, err := s.deletionTombstoneRepo.DeleteCreatedBefore(ctx, time.Now().Add(-TombstoneRetention))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to prune expired deletion tombstones.", zap.Error(err))
+			continue
+		}
+		s.logger.Debug("Pruned expired deletion tombstones.", zap.Int64("rowsAffected", rowsAffected))
+	}
+}
@@ -0,0 +1,130 @@
+package delta
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	dbUtils "github.com/verzac/grocer-discord-bot/db"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func setupDeltaTest(t *testing.T) (*DeltaServiceImpl, *gorm.DB) {
+	t.Helper()
+	t.Setenv("GROCER_BOT_DB_SOURCE_CHANGELOG", "file://../../db/changelog")
+	db := dbUtils.Setup(filepath.Join(t.TempDir(), "gorm.db"), zap.NewNop(), "test")
+	return &DeltaServiceImpl{
+		groceryEntryRepo:      &repositories.GroceryEntryRepositoryImpl{DB: db},
+		groceryListRepo:       &repositories.GroceryListRepositoryImpl{DB: db},
+		deletionTombstoneRepo: &repositories.DeletionTombstoneRepositoryImpl{DB: db},
+		groceryListShareRepo:  &repositories.GroceryListShareRepositoryImpl{DB: db},
+		logger:                zap.NewNop(),
+	}, db
+}
+
+func createEntry(t *testing.T, db *gorm.DB, guildID string, groceryListID *uint, itemDesc string) models.GroceryEntry {
+	t.Helper()
+	entry := models.GroceryEntry{GuildID: guildID, GroceryListID: groceryListID, ItemDesc: itemDesc}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+// cursorAt returns a cursor for t, with a pause on either side so that what's written before & after it doesn't share its timestamp
+func cursorAt(t *testing.T) string {
+	t.Helper()
+	time.Sleep(10 * time.Millisecond)
+	cursor := strconv.FormatInt(time.Now().UnixNano(), 10)
+	time.Sleep(10 * time.Millisecond)
+	return cursor
+}
+
+func entryDescs(out *dto.SyncResponse) []string {
+	descs := make([]string, 0, len(out.GroceryEntries))
+	for _, g := range out.GroceryEntries {
+		descs = append(descs, g.ItemDesc)
+	}
+	sort.Strings(descs)
+	return descs
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetChangesSince_SharedGroceryLists(t *testing.T) {
+	s, db := setupDeltaTest(t)
+	ctx := context.Background()
+	shared := &models.GroceryList{GuildID: "owner", ListLabel: "flat"}
+	other := &models.GroceryList{GuildID: "owner", ListLabel: "other"}
+	for _, gl := range []*models.GroceryList{shared, other} {
+		if err := db.Create(gl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	milk := createEntry(t, db, "owner", &shared.ID, "milk")
+	createEntry(t, db, "owner", &other.ID, "not shared")
+	createEntry(t, db, "joiner", nil, "eggs")
+	share := &models.GroceryListShare{GroceryListID: shared.ID, GuildID: "joiner"}
+	if err := s.groceryListShareRepo.Create(ctx, share); err != nil {
+		t.Fatal(err)
+	}
+
+	// everything, like GET /grocery-lists
+	out, err := s.getChangesSince(ctx, "joiner", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entryDescs(out); len(got) != 2 || got[0] != "eggs" || got[1] != "milk" {
+		t.Errorf("full sync sent entries %v, want [eggs milk]", got)
+	}
+	if len(out.GroceryLists) != 1 || out.GroceryLists[0].ID != shared.ID {
+		t.Errorf("full sync sent lists %+v, want the shared list", out.GroceryLists)
+	}
+
+	// the owner's changes to the shared list
+	cursor := cursorAt(t)
+	createEntry(t, db, "owner", &shared.ID, "butter")
+	if err := s.groceryEntryRepo.WithContext(ctx).Delete(ctx, &milk); err != nil {
+		t.Fatal(err)
+	}
+	out, err = s.getChangesSince(ctx, "joiner", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entryDescs(out); len(got) != 1 || got[0] != "butter" {
+		t.Errorf("sync sent entries %v, want [butter]", got)
+	}
+	if !containsID(out.DeletedGroceryEntryIDs, milk.ID) {
+		t.Errorf("sync sent deleted entries %v, want %d in there", out.DeletedGroceryEntryIDs, milk.ID)
+	}
+
+	// leaving the shared list
+	cursor = cursorAt(t)
+	if err := s.groceryListShareRepo.Delete(ctx, share); err != nil {
+		t.Fatal(err)
+	}
+	out, err = s.getChangesSince(ctx, "joiner", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(out.DeletedGroceryListIDs, shared.ID) {
+		t.Errorf("sync sent deleted lists %v, want %d in there", out.DeletedGroceryListIDs, shared.ID)
+	}
+	if len(out.GroceryEntries) != 0 || len(out.GroceryLists) != 0 {
+		t.Errorf("sync sent %+v after leaving the shared list", out)
+	}
+}
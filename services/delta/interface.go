@@ -0,0 +1,56 @@
+package delta
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// how long deletions are remembered for - clients that haven't synced for longer than this get a full sync instead
+	TombstoneRetention = 30 * 24 * time.Hour
+	// how often expired tombstones are deleted
+	pruneInterval = time.Hour
+	// a change that commits while a sync is running can have an updated_at from before the sync started,
+	// so the cursor goes back a little to pick those up on the next sync
+	cursorOverlap = 5 * time.Second
+)
+
+var (
+	Service          DeltaService
+	ErrInvalidCursor = errors.New("Invalid sync cursor - use the cursor from the previous GET /sync, or leave it out to get everything.")
+)
+
+type DeltaService interface {
+	// GetChangesSince returns the guild's grocery entries & lists that have changed since the cursor along with what has been deleted,
+	// or everything in the guild if cursor is empty. Like GET /grocery-lists, this includes the grocery lists that other guilds have shared
+	// with it. Grocery lists that the member cannot see are left out.
+	GetChangesSince(ctx context.Context, m *dto.MemberContext, cursor string) (*dto.SyncResponse, error)
+}
+
+type DeltaServiceImpl struct {
+	groceryEntryRepo      repositories.GroceryEntryRepository
+	groceryListRepo       repositories.GroceryListRepository
+	deletionTombstoneRepo repositories.DeletionTombstoneRepository
+	groceryListShareRepo  repositories.GroceryListShareRepository
+	logger                *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		s := &DeltaServiceImpl{
+			groceryEntryRepo:      &repositories.GroceryEntryRepositoryImpl{DB: db},
+			groceryListRepo:       &repositories.GroceryListRepositoryImpl{DB: db},
+			deletionTombstoneRepo: &repositories.DeletionTombstoneRepositoryImpl{DB: db},
+			groceryListShareRepo:  &repositories.GroceryListShareRepositoryImpl{DB: db},
+			logger:                logger.Named("delta"),
+		}
+		go s.pruneExpired()
+		Service = s
+	}
+}
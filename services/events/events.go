@@ -0,0 +1,148 @@
+package events
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"go.uber.org/zap"
+)
+
+type guildStream struct {
+	subscribers map[*Subscription]bool
+	// buffer holds the latest events, oldest first
+	buffer []Event
+	// everything published to the guild after bufferedSince is in buffer
+	bufferedSince uint64
+	lastActiveAt  time.Time
+}
+
+func (s *EventsServiceImpl) Publish(event dto.GroceryEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to marshal event.", zap.Error(err), zap.String("EventType", event.Type))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, ok := s.guilds[event.GuildID]
+	if !ok {
+		// nobody has been listening recently, so there's nobody to catch up either
+		return
+	}
+	s.lastSeq++
+	e := Event{
+		ID:        strconv.FormatUint(s.lastSeq, 10),
+		Type:      event.Type,
+		Data:      data,
+		seq:       s.lastSeq,
+		createdAt: time.Now(),
+	}
+	stream.buffer = append(stream.buffer, e)
+	s.trimBuffer(stream)
+	for sub := range stream.subscribers {
+		select {
+		case sub.c <- e:
+		default:
+			s.logger.Debug("Subscriber has fallen behind: disconnecting.", zap.String("GuildID", sub.guildID))
+			delete(stream.subscribers, sub)
+			close(sub.c)
+		}
+	}
+	if len(stream.subscribers) > 0 {
+		stream.lastActiveAt = time.Now()
+	}
+}
+
+func (s *EventsServiceImpl) HasSubscribers(guildID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, ok := s.guilds[guildID]
+	return ok && len(stream.subscribers) > 0
+}
+
+func (s *EventsServiceImpl) Subscribe(guildID string, lastEventID string) (*Subscription, []Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, ok := s.guilds[guildID]
+	if !ok {
+		stream = &guildStream{
+			subscribers:   make(map[*Subscription]bool),
+			bufferedSince: s.lastSeq,
+		}
+		s.guilds[guildID] = stream
+	}
+	if len(stream.subscribers) >= MaxSubscribersPerGuild {
+		return nil, nil, ErrTooManySubscribers
+	}
+	stream.lastActiveAt = time.Now()
+	c := make(chan Event, subscriberBufferSize)
+	sub := &Subscription{C: c, c: c, guildID: guildID}
+	stream.subscribers[sub] = true
+	return sub, s.getReplay(stream, lastEventID), nil
+}
+
+func (s *EventsServiceImpl) getReplay(stream *guildStream, lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+	lastSeq, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || lastSeq < stream.bufferedSince || lastSeq > s.lastSeq {
+		return []Event{{
+			ID:   strconv.FormatUint(s.lastSeq, 10),
+			Type: EventTypeResync,
+			Data: []byte("{}"),
+		}}
+	}
+	replay := make([]Event, 0)
+	for _, e := range stream.buffer {
+		if e.seq > lastSeq {
+			replay = append(replay, e)
+		}
+	}
+	return replay
+}
+
+func (s *EventsServiceImpl) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, ok := s.guilds[sub.guildID]
+	if !ok || !stream.subscribers[sub] {
+		return
+	}
+	delete(stream.subscribers, sub)
+	close(sub.c)
+	stream.lastActiveAt = time.Now()
+}
+
+// trimBuffer drops events that are too old or too many to be replayed. Needs s.mu to be held.
+func (s *EventsServiceImpl) trimBuffer(stream *guildStream) {
+	cutoff := time.Now().Add(-replayWindow)
+	drop := 0
+	for drop < len(stream.buffer) && (len(stream.buffer)-drop > replayBufferSize || stream.buffer[drop].createdAt.Before(cutoff)) {
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+	stream.bufferedSince = stream.buffer[drop-1].seq
+	stream.buffer = append([]Event(nil), stream.buffer[drop:]...)
+}
+
+// pruneInactive forgets about guilds that nobody has listened to for longer than the replay window
+func (s *EventsServiceImpl) pruneInactive() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mu.Lock()
+		for guildID, stream := range s.guilds {
+			if len(stream.subscribers) == 0 && time.Since(stream.lastActiveAt) > replayWindow {
+				delete(s.guilds, guildID)
+				continue
+			}
+			s.trimBuffer(stream)
+		}
+		s.mu.Unlock()
+	}
+}
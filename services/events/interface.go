@@ -0,0 +1,80 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"go.uber.org/zap"
+)
+
+const (
+	// how many events are kept per guild, so that clients can catch up through Last-Event-ID after reconnecting
+	replayBufferSize = 100
+	// how long events are kept for catching up
+	replayWindow = 10 * time.Minute
+	// how often guilds that nobody has been listening to for a while are cleaned up
+	pruneInterval = time.Minute
+	// subscribers that fall this far behind are disconnected, and catch up through Last-Event-ID when they reconnect
+	subscriberBufferSize   = 64
+	MaxSubscribersPerGuild = 20
+
+	// EventTypeResync tells the client that it has missed events that can't be replayed, so it should fetch everything again
+	EventTypeResync = "resync"
+)
+
+var (
+	Service EventsService
+
+	ErrTooManySubscribers = errors.New("Too many live connections for this server - please close some of them and try again.")
+)
+
+// Event is a dto.GroceryEvent as it is sent through GET /events. ID is the event's position in the stream (which is what
+// clients send back through Last-Event-ID), not the ID of the dto.GroceryEvent itself.
+type Event struct {
+	ID   string
+	Type string
+	Data []byte
+
+	seq       uint64
+	createdAt time.Time
+}
+
+type Subscription struct {
+	// C is closed when the subscriber has fallen too far behind
+	C <-chan Event
+
+	c       chan Event
+	guildID string
+}
+
+type EventsService interface {
+	// Publish fans the event out to everyone listening to the guild's events.
+	Publish(event dto.GroceryEvent)
+	HasSubscribers(guildID string) bool
+	// Subscribe starts listening to the guild's events. Events after lastEventID are returned in replay if they're still buffered;
+	// if they're not, replay starts with a resync event instead.
+	Subscribe(guildID string, lastEventID string) (sub *Subscription, replay []Event, err error)
+	Unsubscribe(sub *Subscription)
+}
+
+type EventsServiceImpl struct {
+	mu sync.Mutex
+	// lastSeq is shared by all guilds, and starts from the current time so that IDs from before a restart are never reused
+	lastSeq uint64
+	guilds  map[string]*guildStream
+	logger  *zap.Logger
+}
+
+func Init(logger *zap.Logger) {
+	if Service == nil {
+		s := &EventsServiceImpl{
+			lastSeq: uint64(time.Now().UnixNano()),
+			guilds:  make(map[string]*guildStream),
+			logger:  logger.Named("events"),
+		}
+		go s.pruneInactive()
+		Service = s
+	}
+}
@@ -0,0 +1,26 @@
+package grocery
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+// AssignCategories fills in the category of entries that don't have one yet, using the guild's overrides and the built-in keywords.
+// Entries that can't be categorised are left alone. Nothing is persisted.
+func (s *GroceryServiceImpl) AssignCategories(ctx context.Context, guildID string, entries []models.GroceryEntry) error {
+	overrides, err := s.guildConfigRepo.FindCategoryOverrides(ctx, guildID)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].Category != nil {
+			continue
+		}
+		if category := groceryutils.Categorise(entries[i].ItemDesc, overrides); category != "" {
+			entries[i].Category = &category
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package grocery
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+// SetCategoryOverride makes the guild's entries matching keyword go into category from now on, and moves the existing matching entries into it.
+func (s *GroceryServiceImpl) SetCategoryOverride(ctx context.Context, guildID string, keyword string, category string, changedByID string) (updatedCount int, err error) {
+	override := &models.GuildCategoryOverride{
+		GuildID:  guildID,
+		Keyword:  groceryutils.NormalizeItemDesc(keyword),
+		Category: groceryutils.CanonicalCategory(category),
+	}
+	if err := s.guildConfigRepo.PutCategoryOverride(ctx, override); err != nil {
+		return 0, err
+	}
+	return s.recategoriseMatchingEntries(ctx, guildID, override.Keyword, changedByID)
+}
+
+// RemoveCategoryOverride removes the override for keyword, and re-categorises the existing entries that matched it.
+func (s *GroceryServiceImpl) RemoveCategoryOverride(ctx context.Context, guildID string, keyword string, changedByID string) (removed bool, err error) {
+	keyword = groceryutils.NormalizeItemDesc(keyword)
+	rowsAffected, err := s.guildConfigRepo.DeleteCategoryOverride(ctx, guildID, keyword)
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+	if _, err := s.recategoriseMatchingEntries(ctx, guildID, keyword, changedByID); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+func (s *GroceryServiceImpl) recategoriseMatchingEntries(ctx context.Context, guildID string, keyword string, changedByID string) (updatedCount int, err error) {
+	overrides, err := s.guildConfigRepo.FindCategoryOverrides(ctx, guildID)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := s.groceryEntryRepo.WithContext(ctx).FindByQuery(&models.GroceryEntry{GuildID: guildID})
+	if err != nil {
+		return 0, err
+	}
+	keywordOnly := []models.GuildCategoryOverride{{Keyword: keyword, Category: keyword}}
+	toUpdate := make([]models.GroceryEntry, 0)
+	for _, entry := range entries {
+		if groceryutils.Categorise(entry.ItemDesc, keywordOnly) == "" {
+			continue
+		}
+		newCategory := groceryutils.Categorise(entry.ItemDesc, overrides)
+		if newCategory == entry.GetCategory() {
+			continue
+		}
+		entry.Category = nil
+		if newCategory != "" {
+			entry.Category = &newCategory
+		}
+		toUpdate = append(toUpdate, entry)
+	}
+	if len(toUpdate) == 0 {
+		return 0, nil
+	}
+	if rErr := s.groceryEntryRepo.UpdateAndAddToGroceryList(ctx, nil, toUpdate, nil, guildID); rErr != nil {
+		return 0, rErr
+	}
+	s.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeCategorise, toUpdate, changedByID))
+	return len(toUpdate), s.OnGroceryEntriesEdit(ctx, guildID, toUpdate)
+}
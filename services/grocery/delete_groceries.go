@@ -19,7 +19,7 @@ func (e *GroceryEntriesNotFoundError) Error() string {
 
 // DeleteGroceriesByIDs removes all entries for the given IDs in guildID. IDs may contain duplicates.
 // If any ID is missing in the guild, it returns *GroceryEntriesNotFoundError and deletes nothing.
-func (s *GroceryServiceImpl) DeleteGroceriesByIDs(ctx context.Context, guildID string, ids []uint) error {
+func (s *GroceryServiceImpl) DeleteGroceriesByIDs(ctx context.Context, guildID string, ids []uint, changedByID string) error {
 	// process and dedupe IDs
 	seen := make(map[uint]struct{}, len(ids))
 	uniqueIDs := make([]uint, 0, len(ids))
@@ -56,6 +56,7 @@ func (s *GroceryServiceImpl) DeleteGroceriesByIDs(ctx context.Context, guildID s
 	if _, err := repo.DeleteByGuildAndIDs(ctx, guildID, uniqueIDs); err != nil {
 		return err
 	}
+	s.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeRemove, entries, changedByID))
 
 	// process grohere
 	changedListIDSet := make(map[uint]struct{})
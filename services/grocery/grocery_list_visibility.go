@@ -0,0 +1,46 @@
+package grocery
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
+	"github.com/verzac/grocer-discord-bot/utils"
+)
+
+// CanSeeGroceryList checks whether the member can see the grocery list. The member's roles are only looked up if the list is role-restricted.
+func (s *GroceryServiceImpl) CanSeeGroceryList(ctx context.Context, m *dto.MemberContext, groceryList *models.GroceryList) (bool, error) {
+	if models.IsPersonalGuildID(groceryList.GuildID) {
+		// only its owner can get to a personal list in the first place
+		return true, nil
+	}
+	if groceryList.IsVisibleTo(m.UserID, nil) {
+		return true, nil
+	}
+	if groceryList.Visibility != models.GroceryListVisibilityRoles || m.UserID == "" {
+		return false, nil
+	}
+	roleIDs, err := guildconfig.Service.GetMemberRoleIDs(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	return groceryList.IsVisibleTo(m.UserID, roleIDs), nil
+}
+
+// FilterVisibleGroceryLists drops the grocery lists that the member cannot see, along with their groceries.
+func (s *GroceryServiceImpl) FilterVisibleGroceryLists(ctx context.Context, m *dto.MemberContext, groceryLists []models.GroceryList, groceries []models.GroceryEntry) ([]models.GroceryList, []models.GroceryEntry, error) {
+	var err error
+	visibleLists, visibleGroceries := utils.FilterGroceryLists(groceryLists, groceries, func(gl *models.GroceryList) bool {
+		if err != nil {
+			return false
+		}
+		var ok bool
+		ok, err = s.CanSeeGroceryList(ctx, m, gl)
+		return ok
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return visibleLists, visibleGroceries, nil
+}
@@ -0,0 +1,143 @@
+package grocery
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/monitoring/groprometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// edits made within this window of each other are rendered together, e.g. every line of a /grobulk
+	grohereUpdateWindow      = 2 * time.Second
+	grohereUpdateWorkerCount = 4
+	// per worker
+	grohereUpdateQueueSize = 250
+	// used when Discord rate limits us without saying for how long
+	defaultGrohereRetryAfter = 5 * time.Second
+)
+
+// grohereUpdateKey identifies a !grohere message: either a grocery list's (groceryListID is 0 for the default list) or the guild's !grohere all.
+type grohereUpdateKey struct {
+	guildID       string
+	groceryListID uint
+	isGuildWide   bool
+}
+
+type grohereUpdate struct {
+	key         grohereUpdateKey
+	groceryList *models.GroceryList
+}
+
+// scheduleGrohereUpdate renders the !grohere message after delay. If the message is already waiting to be rendered, the update is coalesced into it.
+func (s *GroceryServiceImpl) scheduleGrohereUpdate(key grohereUpdateKey, groceryList *models.GroceryList, delay time.Duration) {
+	s.grohereUpdatesMutex.Lock()
+	defer s.grohereUpdatesMutex.Unlock()
+	_, isPending := s.pendingGrohereUpdates[key]
+	// keep the latest copy of the list, in case it's been renamed in the meantime
+	s.pendingGrohereUpdates[key] = groceryList
+	if isPending {
+		return
+	}
+	s.reportGrohereUpdateQueueDepth()
+	time.AfterFunc(delay, func() {
+		s.enqueueGrohereUpdate(key)
+	})
+}
+
+func (s *GroceryServiceImpl) enqueueGrohereUpdate(key grohereUpdateKey) {
+	s.grohereUpdatesMutex.Lock()
+	defer s.grohereUpdatesMutex.Unlock()
+	update := grohereUpdate{key: key, groceryList: s.pendingGrohereUpdates[key]}
+	delete(s.pendingGrohereUpdates, key)
+	select {
+	case s.grohereUpdateQueues[grohereUpdateWorkerIndex(key.guildID)] <- update:
+	default:
+		// put it back (so that later edits are still coalesced into it) and try again once the worker has caught up a little,
+		// rather than leaving the message stale until the next edit
+		s.logger.Warn("!grohere update queue is full, retrying later.", zap.String("GuildID", key.guildID))
+		s.pendingGrohereUpdates[key] = update.groceryList
+		time.AfterFunc(grohereUpdateWindow, func() {
+			s.enqueueGrohereUpdate(key)
+		})
+	}
+	s.reportGrohereUpdateQueueDepth()
+}
+
+// grohereUpdateWorkerIndex makes sure that a guild's messages are always rendered by the same worker,
+// so that an older render can't overwrite a newer one.
+func grohereUpdateWorkerIndex(guildID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(guildID))
+	return int(h.Sum32() % grohereUpdateWorkerCount)
+}
+
+// reportGrohereUpdateQueueDepth must be called while holding grohereUpdatesMutex.
+func (s *GroceryServiceImpl) reportGrohereUpdateQueueDepth() {
+	depth := len(s.pendingGrohereUpdates)
+	for _, queue := range s.grohereUpdateQueues {
+		depth += len(queue)
+	}
+	groprometheus.UpdateGrohereUpdateQueueDepth(depth)
+}
+
+func (s *GroceryServiceImpl) runGrohereUpdateWorker(queue <-chan grohereUpdate) {
+	for update := range queue {
+		s.grohereUpdatesMutex.Lock()
+		s.reportGrohereUpdateQueueDepth()
+		s.grohereUpdatesMutex.Unlock()
+		s.processGrohereUpdate(update)
+	}
+}
+
+func (s *GroceryServiceImpl) processGrohereUpdate(update grohereUpdate) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Panicked while updating !grohere.", zap.Any("recover", r))
+		}
+	}()
+	// the request that triggered the update is long gone by now
+	ctx := context.Background()
+	guildID := update.key.guildID
+	var err error
+	if update.key.isGuildWide {
+		err = s.editGuildGrohere(ctx, guildID)
+	} else {
+		err = s.editGroceryListGrohere(ctx, update.groceryList, guildID)
+	}
+	if err == nil {
+		return
+	}
+	if retryAfter, isRateLimited := grohereRetryAfter(err); isRateLimited {
+		s.logger.Warn("Rate limited while updating !grohere, retrying later.", zap.String("GuildID", guildID), zap.Duration("RetryAfter", retryAfter))
+		s.scheduleGrohereUpdate(update.key, update.groceryList, retryAfter)
+		return
+	}
+	s.logger.Error("Failed to update !grohere.", zap.String("GuildID", guildID), zap.Error(err))
+}
+
+// grohereRetryAfter returns how long to wait before retrying if err is caused by Discord's rate limits.
+func grohereRetryAfter(err error) (retryAfter time.Duration, isRateLimited bool) {
+	var rateLimitErr *discordgo.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		if rateLimitErr.RateLimit != nil && rateLimitErr.TooManyRequests != nil && rateLimitErr.RetryAfter > 0 {
+			return rateLimitErr.RetryAfter, true
+		}
+		return defaultGrohereRetryAfter, true
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.ParseFloat(restErr.Response.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		return defaultGrohereRetryAfter, true
+	}
+	return 0, false
+}
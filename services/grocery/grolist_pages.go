@@ -0,0 +1,26 @@
+package grocery
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const grolistPagesTTL = 1 * time.Hour
+
+// StoreGrolistPages keeps the pages of a !grolist that's too long for one message, so that its Prev/Next buttons can flick through them.
+// The returned key is prefixed with the guild ID, e.g. "guildID:uuid".
+func (s *GroceryServiceImpl) StoreGrolistPages(guildID string, pages []string) string {
+	key := guildID + ":" + uuid.NewString()
+	s.grolistPages.Set(key, pages, grolistPagesTTL)
+	return key
+}
+
+func (s *GroceryServiceImpl) GetGrolistPages(key string) (pages []string, ok bool) {
+	v, ok := s.grolistPages.Get(key)
+	if !ok {
+		return nil, false
+	}
+	pages, ok = v.([]string)
+	return pages, ok
+}
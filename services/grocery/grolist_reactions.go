@@ -0,0 +1,151 @@
+package grocery
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/guildconfig"
+	"go.uber.org/zap"
+)
+
+const (
+	grolistReactionMessageTTL    = 7 * 24 * time.Hour
+	grolistReactionPruneInterval = time.Hour
+)
+
+// one reaction per line - Discord's number emojis stop at 10, so only the first 10 entries of a list get one
+var grolistReactionEmojis = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣", "9️⃣", "🔟"}
+
+func grolistReactionIndex(emojiName string) int {
+	for i, e := range grolistReactionEmojis {
+		if e == emojiName {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddGrolistReactions remembers which entries a !grolist reply rendered, then reacts to the reply with a number emoji for each of them.
+// The reactions are added in the background since Discord only lets us add them one at a time.
+func (s *GroceryServiceImpl) AddGrolistReactions(ctx context.Context, guildID string, message *discordgo.Message, entries []models.GroceryEntry) error {
+	if len(entries) > len(grolistReactionEmojis) {
+		entries = entries[:len(grolistReactionEmojis)]
+	}
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	m := &models.GrolistReactionMessage{
+		MessageID: message.ID,
+		GuildID:   guildID,
+		ChannelID: message.ChannelID,
+	}
+	m.SetGroceryEntryIDs(ids)
+	if err := s.grolistReactionMessageRepo.Put(ctx, m); err != nil {
+		return err
+	}
+	go func() {
+		for i := range ids {
+			if err := s.sess.MessageReactionAdd(message.ChannelID, message.ID, grolistReactionEmojis[i]); err != nil {
+				// most likely missing the "Add Reactions" permission, so there's no point in trying the rest
+				s.logger.Warn("Cannot add reaction to !grolist reply.", zap.String("GuildID", guildID), zap.Error(err))
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// OnGrolistReaction checks off (or unchecks, if the reaction was removed) the entry that a !grolist reply's reaction points to.
+// Reactions to other messages are ignored. member is nil if Discord hasn't sent it along (e.g. for removed reactions).
+func (s *GroceryServiceImpl) OnGrolistReaction(ctx context.Context, r *discordgo.MessageReaction, member *discordgo.Member, checked bool) error {
+	if r.GuildID == "" {
+		return nil
+	}
+	index := grolistReactionIndex(r.Emoji.Name)
+	if index < 0 {
+		return nil
+	}
+	m, err := s.grolistReactionMessageRepo.GetByMessageID(ctx, r.MessageID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.GuildID != r.GuildID {
+		return nil
+	}
+	ids := m.GetGroceryEntryIDs()
+	if index >= len(ids) {
+		return nil
+	}
+	config, err := s.guildConfigRepo.Get(r.GuildID)
+	if err != nil {
+		return err
+	}
+	if config == nil || !config.UseGrolistReactions {
+		// turned off since the reply was sent
+		return nil
+	}
+	memberContext := &dto.MemberContext{
+		GuildID:   r.GuildID,
+		ChannelID: r.ChannelID,
+		UserID:    r.UserID,
+	}
+	if member != nil {
+		// saves looking the member up through Discord
+		memberContext.RoleIDs = member.Roles
+	}
+	hasCapability, err := guildconfig.Service.HasCapability(ctx, memberContext, models.CapabilityAdd)
+	if err != nil {
+		return err
+	}
+	if !hasCapability {
+		return nil
+	}
+	entries, err := s.FindGuildGroceryEntries(ctx, r.GuildID, []uint{ids[index]})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		// removed since the reply was sent
+		return nil
+	}
+	g := &entries[0]
+	if g.IsChecked() == checked {
+		return nil
+	}
+	action := models.GroceryEntryChangeUncheck
+	if checked {
+		now := time.Now()
+		g.CheckedAt = &now
+		g.CheckedByID = &r.UserID
+		action = models.GroceryEntryChangeCheck
+	} else {
+		g.CheckedAt = nil
+		g.CheckedByID = nil
+	}
+	g.UpdatedByID = &r.UserID
+	if err := s.groceryEntryRepo.Put(g); err != nil {
+		return err
+	}
+	s.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(action, entries, r.UserID))
+	return s.OnGroceryEntriesEdit(ctx, r.GuildID, entries)
+}
+
+// pruneGrolistReactionMessages forgets about !grolist replies that are old enough to have scrolled out of sight
+func (s *GroceryServiceImpl) pruneGrolistReactionMessages() {
+	ticker := time.NewTicker(grolistReactionPruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		rowsAffected, err := s.grolistReactionMessageRepo.DeleteCreatedBefore(ctx, time.Now().Add(-grolistReactionMessageTTL))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to prune !grolist reaction messages.", zap.Error(err))
+			continue
+		}
+		s.logger.Debug("Pruned !grolist reaction messages.", zap.Int64("rowsAffected", rowsAffected))
+	}
+}
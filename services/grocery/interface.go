@@ -3,8 +3,10 @@ package grocery
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/patrickmn/go-cache"
 	"github.com/verzac/grocer-discord-bot/dto"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
@@ -21,9 +23,35 @@ type GroceryService interface {
 	ValidateGroceryEntryLimitUsingTotalCount(ctx context.Context, registrationContext *dto.RegistrationContext, guildID string, totalItemCount int) (limitOk bool, limit int, err error)
 	ValidateGroceryListLimit(ctx context.Context, registrationContext *dto.RegistrationContext, guildID string) (limitOk bool, limit int, err error)
 	OnGroceryListEdit(ctx context.Context, groceryList *models.GroceryList, guildID string) error
-	DeleteGroceriesByIDs(ctx context.Context, guildID string, ids []uint) error
+	OnGroceryEntriesEdit(ctx context.Context, guildID string, entries []models.GroceryEntry) error
+	DeleteGroceriesByIDs(ctx context.Context, guildID string, ids []uint, changedByID string) error
 	UpdateGuildGrohere(ctx context.Context, guildID string) error
 	ProcessListlessGroceries(ctx context.Context, groceries []models.GroceryEntry) error
+	MergeDuplicates(ctx context.Context, groceryList *models.GroceryList, guildID string, incoming []models.GroceryEntry) (merged []models.GroceryEntry, toAdd []models.GroceryEntry, unmergeable []models.GroceryEntry, err error)
+	StorePendingAdd(guildID string, entry models.GroceryEntry) string
+	PendingAddAuthorID(key string) (authorID string, ok bool)
+	ConfirmPendingAdd(ctx context.Context, key string, authorID string, registrationContext *dto.RegistrationContext) (*models.GroceryEntry, *models.GroceryList, error)
+	CancelPendingAdd(key string)
+	StoreGrolistPages(guildID string, pages []string) string
+	GetGrolistPages(key string) (pages []string, ok bool)
+	AddGrolistReactions(ctx context.Context, guildID string, message *discordgo.Message, entries []models.GroceryEntry) error
+	OnGrolistReaction(ctx context.Context, r *discordgo.MessageReaction, member *discordgo.Member, checked bool) error
+	AssignCategories(ctx context.Context, guildID string, entries []models.GroceryEntry) error
+	SetCategoryOverride(ctx context.Context, guildID string, keyword string, category string, changedByID string) (updatedCount int, err error)
+	RemoveCategoryOverride(ctx context.Context, guildID string, keyword string, changedByID string) (removed bool, err error)
+	// OnGroceryEntriesChanged should be called after every change made to grocery entries. Errors are only logged.
+	OnGroceryEntriesChanged(ctx context.Context, changes []models.GroceryEntryChange)
+	OnGroceryListCreated(ctx context.Context, groceryList *models.GroceryList)
+	OnGroceryListDeleted(ctx context.Context, groceryList *models.GroceryList)
+	CanSeeGroceryList(ctx context.Context, m *dto.MemberContext, groceryList *models.GroceryList) (bool, error)
+	FilterVisibleGroceryLists(ctx context.Context, m *dto.MemberContext, groceryLists []models.GroceryList, groceries []models.GroceryEntry) ([]models.GroceryList, []models.GroceryEntry, error)
+	ShareGroceryList(ctx context.Context, groceryList *models.GroceryList, createdByID string) (*models.GroceryListInvite, error)
+	JoinGroceryList(ctx context.Context, guildID string, code string, joinedByID string) (*models.GroceryList, error)
+	LeaveGroceryList(ctx context.Context, guildID string, groceryList *models.GroceryList) error
+	GetGuildGroceryList(ctx context.Context, guildID string, q *models.GroceryList) (*models.GroceryList, error)
+	FindGuildGroceryLists(ctx context.Context, guildID string) ([]models.GroceryList, []models.GroceryEntry, error)
+	FindGuildGroceryEntries(ctx context.Context, guildID string, ids []uint) ([]models.GroceryEntry, error)
+	FindSharedGroceryLists(ctx context.Context, guildID string) ([]models.GroceryList, error)
 }
 
 type GroceryServiceImpl struct {
@@ -34,20 +62,45 @@ type GroceryServiceImpl struct {
 	groceryListRepo  repositories.GroceryListRepository
 	sess             *discordgo.Session
 
+	grolistReactionMessageRepo repositories.GrolistReactionMessageRepository
+	groceryListShareRepo       repositories.GroceryListShareRepository
+	groceryStapleRepo          repositories.GroceryStapleRepository
+	groceryReminderRepo        repositories.GroceryReminderRepository
+
 	listlessGroceriesChannel chan models.GroceryEntry
 	workerMutex              sync.Mutex
+	pendingAdds              *cache.Cache
+	grolistPages             *cache.Cache
+
+	grohereUpdatesMutex   sync.Mutex
+	pendingGrohereUpdates map[grohereUpdateKey]*models.GroceryList
+	grohereUpdateQueues   []chan grohereUpdate
 }
 
 func Init(db *gorm.DB, logger *zap.Logger, sess *discordgo.Session) {
 	if Service == nil {
-		Service = &GroceryServiceImpl{
-			grohereRepo:              &repositories.GrohereRecordRepositoryImpl{DB: db},
-			groceryEntryRepo:         &repositories.GroceryEntryRepositoryImpl{DB: db},
-			guildConfigRepo:          &repositories.GuildConfigRepositoryImpl{DB: db},
-			groceryListRepo:          &repositories.GroceryListRepositoryImpl{DB: db},
-			logger:                   logger.Named("grocery"),
-			sess:                     sess,
-			listlessGroceriesChannel: make(chan models.GroceryEntry, 1000),
+		s := &GroceryServiceImpl{
+			grohereRepo:                &repositories.GrohereRecordRepositoryImpl{DB: db},
+			groceryEntryRepo:           &repositories.GroceryEntryRepositoryImpl{DB: db},
+			guildConfigRepo:            &repositories.GuildConfigRepositoryImpl{DB: db},
+			groceryListRepo:            &repositories.GroceryListRepositoryImpl{DB: db},
+			grolistReactionMessageRepo: &repositories.GrolistReactionMessageRepositoryImpl{DB: db},
+			groceryListShareRepo:       &repositories.GroceryListShareRepositoryImpl{DB: db},
+			groceryStapleRepo:          &repositories.GroceryStapleRepositoryImpl{DB: db},
+			groceryReminderRepo:        &repositories.GroceryReminderRepositoryImpl{DB: db},
+			logger:                     logger.Named("grocery"),
+			sess:                       sess,
+			listlessGroceriesChannel:   make(chan models.GroceryEntry, 1000),
+			pendingAdds:                cache.New(pendingAddTTL, 10*time.Minute),
+			grolistPages:               cache.New(grolistPagesTTL, 10*time.Minute),
+			pendingGrohereUpdates:      make(map[grohereUpdateKey]*models.GroceryList),
+			grohereUpdateQueues:        make([]chan grohereUpdate, grohereUpdateWorkerCount),
+		}
+		for i := range s.grohereUpdateQueues {
+			s.grohereUpdateQueues[i] = make(chan grohereUpdate, grohereUpdateQueueSize)
+			go s.runGrohereUpdateWorker(s.grohereUpdateQueues[i])
 		}
+		go s.pruneGrolistReactionMessages()
+		Service = s
 	}
 }
@@ -0,0 +1,28 @@
+package grocery
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+// MergeDuplicates lines up incoming entries against what's already on the grocery list - see groceryutils.MergeDuplicates.
+// Nothing is persisted; save the results through GroceryEntryRepository.UpdateAndAddToGroceryList.
+func (s *GroceryServiceImpl) MergeDuplicates(ctx context.Context, groceryList *models.GroceryList, guildID string, incoming []models.GroceryEntry) (merged []models.GroceryEntry, toAdd []models.GroceryEntry, unmergeable []models.GroceryEntry, err error) {
+	existing, err := s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(
+		&models.GroceryEntry{
+			GuildID:       guildID,
+			GroceryListID: groceryList.GetID(),
+		},
+		repositories.GroceryEntryQueryOpts{
+			IsStrongNilForGroceryListID: true,
+		},
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	merged, toAdd, unmergeable = groceryutils.MergeDuplicates(existing, incoming)
+	return merged, toAdd, unmergeable, nil
+}
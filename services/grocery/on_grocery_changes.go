@@ -0,0 +1,144 @@
+package grocery
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/events"
+	"github.com/verzac/grocer-discord-bot/services/history"
+	"github.com/verzac/grocer-discord-bot/services/webhook"
+	"go.uber.org/zap"
+)
+
+// OnGroceryEntriesChanged records the changes into the change-log (see !grohistory) and sends them to the guild's webhooks & GET /events.
+func (s *GroceryServiceImpl) OnGroceryEntriesChanged(ctx context.Context, changes []models.GroceryEntryChange) {
+	if len(changes) == 0 {
+		return
+	}
+	history.Service.Record(ctx, changes)
+	changes = s.dropPrivateGroceryListChanges(ctx, changes)
+	if len(changes) == 0 {
+		return
+	}
+
+	// one event per type, so that e.g. a /grobulk only results in one grocery_entry.added event
+	eventTypes := make([]string, 0, 1)
+	changesByEventType := make(map[string][]models.GroceryEntryChange)
+	for _, c := range changes {
+		eventType := groceryEventTypeForAction(c.Action)
+		if _, ok := changesByEventType[eventType]; !ok {
+			eventTypes = append(eventTypes, eventType)
+		}
+		changesByEventType[eventType] = append(changesByEventType[eventType], c)
+	}
+	for _, eventType := range eventTypes {
+		s.publish(ctx, dto.GroceryEvent{
+			Type:    eventType,
+			GuildID: changes[0].GuildID,
+			Changes: changesByEventType[eventType],
+		})
+	}
+}
+
+func (s *GroceryServiceImpl) OnGroceryListCreated(ctx context.Context, groceryList *models.GroceryList) {
+	s.publish(ctx, dto.GroceryEvent{
+		Type:        dto.GroceryEventListCreated,
+		GuildID:     groceryList.GuildID,
+		GroceryList: groceryList,
+	})
+}
+
+func (s *GroceryServiceImpl) OnGroceryListDeleted(ctx context.Context, groceryList *models.GroceryList) {
+	if err := s.removeGroceryListShares(ctx, groceryList); err != nil {
+		s.logger.Error("Failed to remove shares of deleted grocery list.", zap.Error(err))
+	}
+	if err := s.groceryStapleRepo.DeleteByGroceryListID(ctx, groceryList.ID); err != nil {
+		s.logger.Error("Failed to remove staples of deleted grocery list.", zap.Error(err))
+	}
+	if err := s.groceryReminderRepo.DeleteByGroceryListID(ctx, groceryList.ID); err != nil {
+		s.logger.Error("Failed to remove reminders of deleted grocery list.", zap.Error(err))
+	}
+	if !groceryList.IsPublic() {
+		return
+	}
+	s.publish(ctx, dto.GroceryEvent{
+		Type:        dto.GroceryEventListDeleted,
+		GuildID:     groceryList.GuildID,
+		GroceryList: groceryList,
+	})
+}
+
+// publishGroceryListUpdated sends what the grocery list (nil for the default list) looks like now to GET /events, if anyone's listening.
+func (s *GroceryServiceImpl) publishGroceryListUpdated(ctx context.Context, groceryList *models.GroceryList, guildID string) {
+	if !groceryList.IsPublic() || !events.Service.HasSubscribers(guildID) {
+		return
+	}
+	groceries, err := s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(&models.GroceryEntry{
+		GuildID:       groceryList.GetGuildID(guildID),
+		GroceryListID: groceryList.GetID(),
+	}, repositories.GroceryEntryQueryOpts{
+		IsStrongNilForGroceryListID: true,
+	})
+	if err != nil {
+		s.logger.Error("Failed to look up groceries for grocery_list.updated event.", zap.Error(err))
+		return
+	}
+	events.Service.Publish(dto.GroceryEvent{
+		ID:             uuid.NewString(),
+		Type:           dto.GroceryEventListUpdated,
+		GuildID:        guildID,
+		CreatedAt:      time.Now(),
+		GroceryList:    groceryList,
+		GroceryEntries: groceries,
+	})
+}
+
+// publish sends the event to both the guild's webhooks & GET /events, with the same ID so that it can be de-duplicated
+func (s *GroceryServiceImpl) publish(ctx context.Context, event dto.GroceryEvent) {
+	event.ID = uuid.NewString()
+	event.CreatedAt = time.Now()
+	webhook.Service.Publish(ctx, event)
+	events.Service.Publish(event)
+}
+
+// dropPrivateGroceryListChanges leaves out the changes made to non-public grocery lists, since webhooks & API clients can only see public ones.
+func (s *GroceryServiceImpl) dropPrivateGroceryListChanges(ctx context.Context, changes []models.GroceryEntryChange) []models.GroceryEntryChange {
+	isPublic := make(map[uint]bool)
+	kept := make([]models.GroceryEntryChange, 0, len(changes))
+	for _, c := range changes {
+		if c.GroceryListID == nil {
+			kept = append(kept, c)
+			continue
+		}
+		public, ok := isPublic[*c.GroceryListID]
+		if !ok {
+			groceryList, err := s.groceryListRepo.GetByQuery(&models.GroceryList{ID: *c.GroceryListID, GuildID: c.GuildID})
+			if err != nil {
+				s.logger.Error("Failed to look up grocery list for grocery entry event.", zap.Error(err))
+			}
+			// err on the side of not leaking anything
+			public = err == nil && groceryList != nil && groceryList.IsPublic()
+			isPublic[*c.GroceryListID] = public
+		}
+		if public {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func groceryEventTypeForAction(action string) string {
+	switch action {
+	case models.GroceryEntryChangeAdd, models.GroceryEntryChangeRestore:
+		return dto.GroceryEventEntryAdded
+	case models.GroceryEntryChangeRemove:
+		return dto.GroceryEventEntryRemoved
+	default:
+		// merges, check-offs and re-categorisations all change an existing entry
+		return dto.GroceryEventEntryEdited
+	}
+}
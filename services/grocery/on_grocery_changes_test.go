@@ -0,0 +1,48 @@
+package grocery
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	dbUtils "github.com/verzac/grocer-discord-bot/db"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+)
+
+func TestDropPrivateGroceryListChanges(t *testing.T) {
+	t.Setenv("GROCER_BOT_DB_SOURCE_CHANGELOG", "file://../../db/changelog")
+	db := dbUtils.Setup(filepath.Join(t.TempDir(), "gorm.db"), zap.NewNop(), "test")
+	s := &GroceryServiceImpl{
+		groceryListRepo: &repositories.GroceryListRepositoryImpl{DB: db},
+		logger:          zap.NewNop(),
+	}
+	public := &models.GroceryList{GuildID: "guild", ListLabel: "public"}
+	private := &models.GroceryList{GuildID: "guild", ListLabel: "private", Visibility: models.GroceryListVisibilityUsers}
+	deleted := &models.GroceryList{GuildID: "guild", ListLabel: "deleted"}
+	for _, gl := range []*models.GroceryList{public, private, deleted} {
+		if err := db.Create(gl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the list is gone by the time its changes are published
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []models.GroceryEntryChange{
+		{GuildID: "guild", GroceryEntryID: 1},
+		{GuildID: "guild", GroceryEntryID: 2, GroceryListID: &public.ID},
+		{GuildID: "guild", GroceryEntryID: 3, GroceryListID: &private.ID},
+		{GuildID: "guild", GroceryEntryID: 4, GroceryListID: &deleted.ID},
+	}
+	kept := s.dropPrivateGroceryListChanges(context.Background(), changes)
+	keptIDs := make([]uint, 0, len(kept))
+	for _, c := range kept {
+		keptIDs = append(keptIDs, c.GroceryEntryID)
+	}
+	if len(keptIDs) != 2 || keptIDs[0] != 1 || keptIDs[1] != 2 {
+		t.Errorf("kept the changes to entries %v, want [1 2]", keptIDs)
+	}
+}
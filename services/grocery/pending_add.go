@@ -0,0 +1,100 @@
+package grocery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+)
+
+const pendingAddTTL = 5 * time.Minute
+
+var (
+	ErrPendingAddNotFound    = errors.New("pending grocery entry not found or expired")
+	ErrPendingAddWrongAuthor = errors.New("this pending grocery entry belongs to another user")
+)
+
+// StorePendingAdd keeps a duplicate entry around so that the user can still choose to add it anyway (see ConfirmPendingAdd).
+// The returned key is prefixed with the ID of the guild that it's being added from, e.g. "guildID:uuid" - which isn't the entry's
+// guild if it's being added to a grocery list that has been shared with the guild.
+func (s *GroceryServiceImpl) StorePendingAdd(guildID string, entry models.GroceryEntry) string {
+	key := guildID + ":" + uuid.NewString()
+	s.pendingAdds.Set(key, entry, pendingAddTTL)
+	return key
+}
+
+func (s *GroceryServiceImpl) CancelPendingAdd(key string) {
+	s.pendingAdds.Delete(key)
+}
+
+func (s *GroceryServiceImpl) PendingAddAuthorID(key string) (authorID string, ok bool) {
+	v, ok := s.pendingAdds.Get(key)
+	if !ok {
+		return "", false
+	}
+	entry, ok := v.(models.GroceryEntry)
+	if !ok || entry.UpdatedByID == nil {
+		return "", false
+	}
+	return *entry.UpdatedByID, true
+}
+
+// ConfirmPendingAdd adds an entry stored by StorePendingAdd to its grocery list, regardless of any duplicates.
+func (s *GroceryServiceImpl) ConfirmPendingAdd(ctx context.Context, key string, authorID string, registrationContext *dto.RegistrationContext) (*models.GroceryEntry, *models.GroceryList, error) {
+	v, ok := s.pendingAdds.Get(key)
+	if !ok {
+		return nil, nil, ErrPendingAddNotFound
+	}
+	entry, ok := v.(models.GroceryEntry)
+	keyGuildID, keyHasGuild := models.GuildIDFromKey(key)
+	if !ok || !keyHasGuild || (entry.GroceryListID == nil && keyGuildID != entry.GuildID) {
+		return nil, nil, ErrPendingAddNotFound
+	}
+	if entry.UpdatedByID == nil || *entry.UpdatedByID != authorID {
+		return nil, nil, ErrPendingAddWrongAuthor
+	}
+	var groceryList *models.GroceryList
+	if entry.GroceryListID != nil {
+		// also makes sure that a shared list is still shared with the guild
+		gl, err := s.GetGuildGroceryList(ctx, keyGuildID, &models.GroceryList{ID: *entry.GroceryListID})
+		if err != nil {
+			return nil, nil, err
+		}
+		if gl == nil || gl.GuildID != entry.GuildID {
+			s.pendingAdds.Delete(key)
+			return nil, nil, errors.New("Whoops, the grocery list for this entry no longer exists.")
+		}
+		groceryList = gl
+	}
+	if entry.GuildID != keyGuildID {
+		// a shared list's entries count against the limits of the guild that owns it
+		ownerRegistrationContext, err := registration.Service.GetRegistrationContext(entry.GuildID)
+		if err != nil {
+			return nil, nil, err
+		}
+		registrationContext = ownerRegistrationContext
+	}
+	limitOk, groceryEntryLimit, err := s.ValidateGroceryEntryLimit(ctx, registrationContext, entry.GuildID, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !limitOk {
+		return nil, nil, fmt.Errorf("Whoops, you've gone over the limit allowed by the bot (max %d grocery entries per server). Please log an issue through GitHub (look at `!grohelp`) to request an increase! Thank you for being a power user! :tada:", groceryEntryLimit)
+	}
+	// take it out of the cache only once we know that we can add it, so that the user can retry
+	s.pendingAdds.Delete(key)
+	toAdd := []models.GroceryEntry{entry}
+	if rErr := s.groceryEntryRepo.WithContext(ctx).AddToGroceryList(groceryList, toAdd, entry.GuildID); rErr != nil {
+		return nil, nil, rErr
+	}
+	s.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, toAdd, authorID))
+	if err := s.OnGroceryListEdit(ctx, groceryList, entry.GuildID); err != nil {
+		return &toAdd[0], groceryList, err
+	}
+	return &toAdd[0], groceryList, nil
+}
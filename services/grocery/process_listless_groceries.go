@@ -104,6 +104,7 @@ func (s *GroceryServiceImpl) processListlessEntry(ctx context.Context, entry mod
 				zap.Uint("groceryListID", *entry.GroceryListID),
 				zap.Error(err))
 		} else {
+			s.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeRemove, []models.GroceryEntry{entry}, ""))
 			s.logger.Debug("Deleted orphaned grocery entry",
 				zap.Uint("entryID", entry.ID),
 				zap.String("itemDesc", entry.ItemDesc),
@@ -0,0 +1,280 @@
+package grocery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/verzac/grocer-discord-bot/auth"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+)
+
+const groceryListInviteTTL = 24 * time.Hour
+
+const msgGrohereGroceryListUnshared = ":shopping_cart: %s\n*:wave: This grocery list is no longer shared with this server.*"
+
+var (
+	ErrGroceryListInviteNotFound  = errors.New("That invite code doesn't exist or has expired.")
+	ErrGroceryListNotShareable    = errors.New("Only public grocery lists can be shared with other servers.")
+	ErrGroceryListAlreadyInGuild  = errors.New("That grocery list is already in this server.")
+	ErrGroceryListLabelTaken      = errors.New("This server already has a grocery list with that label.")
+	ErrPersonalGroceryListSharing = errors.New("Personal grocery lists cannot be shared.")
+)
+
+// ShareGroceryList creates a single-use invite code that lets another guild join the grocery list (see JoinGroceryList).
+func (s *GroceryServiceImpl) ShareGroceryList(ctx context.Context, groceryList *models.GroceryList, createdByID string) (*models.GroceryListInvite, error) {
+	if models.IsPersonalGuildID(groceryList.GuildID) {
+		return nil, ErrPersonalGroceryListSharing
+	}
+	if !groceryList.IsPublic() {
+		// the other guild's members can't be checked against the list's roles/users
+		return nil, ErrGroceryListNotShareable
+	}
+	now := time.Now()
+	if _, err := s.groceryListShareRepo.DeleteInvitesExpiredBefore(ctx, now); err != nil {
+		s.logger.Error("Failed to prune expired grocery list invites.", zap.Error(err))
+	}
+	code, err := auth.GenerateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	invite := &models.GroceryListInvite{
+		Code:          code,
+		GroceryListID: groceryList.ID,
+		GuildID:       groceryList.GuildID,
+		CreatedByID:   &createdByID,
+		ExpiresAt:     now.Add(groceryListInviteTTL),
+	}
+	if err := s.groceryListShareRepo.CreateInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// JoinGroceryList uses up the invite code to make its grocery list show up in the guild.
+// The list keeps its label, so it's refused if the guild already has a list with the same one. The code is only used up once the list has been joined.
+func (s *GroceryServiceImpl) JoinGroceryList(ctx context.Context, guildID string, code string, joinedByID string) (*models.GroceryList, error) {
+	if models.IsPersonalGuildID(guildID) {
+		return nil, ErrPersonalGroceryListSharing
+	}
+	code = models.NormaliseInviteCode(code)
+	invite, err := s.groceryListShareRepo.GetInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.IsExpired(time.Now()) {
+		return nil, ErrGroceryListInviteNotFound
+	}
+	if invite.GuildID == guildID {
+		return nil, ErrGroceryListAlreadyInGuild
+	}
+	groceryList, err := s.groceryListRepo.WithContext(ctx).GetByQuery(&models.GroceryList{ID: invite.GroceryListID, GuildID: invite.GuildID})
+	if err != nil {
+		return nil, err
+	}
+	if groceryList == nil {
+		// deleted since the invite was created
+		return nil, ErrGroceryListInviteNotFound
+	}
+	if !groceryList.IsPublic() {
+		return nil, ErrGroceryListNotShareable
+	}
+	existingShare, err := s.groceryListShareRepo.GetByGroceryListAndGuild(ctx, groceryList.ID, guildID)
+	if err != nil {
+		return nil, err
+	}
+	if existingShare != nil {
+		return nil, ErrGroceryListAlreadyInGuild
+	}
+	existingList, err := s.GetGuildGroceryList(ctx, guildID, &models.GroceryList{ListLabel: groceryList.ListLabel})
+	if err != nil {
+		return nil, err
+	}
+	if existingList != nil {
+		return nil, ErrGroceryListLabelTaken
+	}
+	invite, err = s.groceryListShareRepo.TakeInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		// someone else has used it in the meantime
+		return nil, ErrGroceryListInviteNotFound
+	}
+	if err := s.groceryListShareRepo.Create(ctx, &models.GroceryListShare{
+		GroceryListID: groceryList.ID,
+		GuildID:       guildID,
+		CreatedByID:   &joinedByID,
+	}); err != nil {
+		return nil, err
+	}
+	return groceryList, nil
+}
+
+// LeaveGroceryList stops sharing another guild's grocery list with the guild. Its !grohere message in the guild is detached.
+func (s *GroceryServiceImpl) LeaveGroceryList(ctx context.Context, guildID string, groceryList *models.GroceryList) error {
+	share, err := s.groceryListShareRepo.GetByGroceryListAndGuild(ctx, groceryList.ID, guildID)
+	if err != nil {
+		return err
+	}
+	if share == nil {
+		return repositories.ErrGroceryListNotFound
+	}
+	return s.removeGroceryListShare(ctx, groceryList, share)
+}
+
+// removeGroceryListShares removes the grocery list from every guild that it has been shared into, e.g. when it gets deleted.
+func (s *GroceryServiceImpl) removeGroceryListShares(ctx context.Context, groceryList *models.GroceryList) error {
+	shares, err := s.groceryListShareRepo.FindByGroceryListID(ctx, groceryList.ID)
+	if err != nil {
+		return err
+	}
+	for i := range shares {
+		if err := s.removeGroceryListShare(ctx, groceryList, &shares[i]); err != nil {
+			return err
+		}
+	}
+	// also gets rid of any invites that are still around
+	return s.groceryListShareRepo.DeleteByGroceryListID(ctx, groceryList.ID)
+}
+
+func (s *GroceryServiceImpl) removeGroceryListShare(ctx context.Context, groceryList *models.GroceryList, share *models.GroceryListShare) error {
+	grohereRecords, err := s.grohereRepo.FindByQuery(&models.GrohereRecord{GuildID: share.GuildID, GroceryListID: &groceryList.ID})
+	if err != nil {
+		return err
+	}
+	for i := range grohereRecords {
+		record := &grohereRecords[i]
+		if _, err := s.sess.ChannelMessageEdit(record.GrohereChannelID, record.GrohereMessageID, fmt.Sprintf(msgGrohereGroceryListUnshared, groceryList.GetName())); err != nil {
+			s.logger.Warn("Cannot edit !grohere message of unshared grocery list.", zap.String("GuildID", share.GuildID), zap.Error(err))
+		}
+		if err := s.grohereRepo.Delete(record); err != nil {
+			return err
+		}
+	}
+	if err := s.groceryListShareRepo.Delete(ctx, share); err != nil {
+		return err
+	}
+	return s.UpdateGuildGrohere(ctx, share.GuildID)
+}
+
+// GetGuildGroceryList looks up one of the guild's grocery lists by ID or label, including the ones that other guilds have shared with it.
+// The guild's own lists take precedence. Returns nil if there's no such list.
+func (s *GroceryServiceImpl) GetGuildGroceryList(ctx context.Context, guildID string, q *models.GroceryList) (*models.GroceryList, error) {
+	if q.ID == 0 && q.ListLabel == "" {
+		// GetByQuery would otherwise match any list
+		return nil, nil
+	}
+	groceryList, err := s.groceryListRepo.WithContext(ctx).GetByQuery(&models.GroceryList{ID: q.ID, ListLabel: q.ListLabel, GuildID: guildID})
+	if err != nil || groceryList != nil {
+		return groceryList, err
+	}
+	sharedLists, err := s.FindSharedGroceryLists(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sharedLists {
+		gl := &sharedLists[i]
+		if (q.ID == 0 || gl.ID == q.ID) && (q.ListLabel == "" || gl.ListLabel == q.ListLabel) {
+			return gl, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindGuildGroceryLists returns the guild's grocery lists & groceries, followed by the lists (and their groceries) that other guilds have shared with it.
+func (s *GroceryServiceImpl) FindGuildGroceryLists(ctx context.Context, guildID string) ([]models.GroceryList, []models.GroceryEntry, error) {
+	groceryLists, err := s.groceryListRepo.WithContext(ctx).FindByQuery(&models.GroceryList{GuildID: guildID})
+	if err != nil {
+		return nil, nil, err
+	}
+	groceries, err := s.groceryEntryRepo.WithContext(ctx).FindByQuery(&models.GroceryEntry{GuildID: guildID})
+	if err != nil {
+		return nil, nil, err
+	}
+	sharedLists, err := s.FindSharedGroceryLists(ctx, guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range sharedLists {
+		gl := &sharedLists[i]
+		sharedGroceries, err := s.groceryEntryRepo.WithContext(ctx).FindByQuery(&models.GroceryEntry{GuildID: gl.GuildID, GroceryListID: &gl.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+		groceryLists = append(groceryLists, *gl)
+		groceries = append(groceries, sharedGroceries...)
+	}
+	return groceryLists, groceries, nil
+}
+
+// FindGuildGroceryEntries looks up the guild's grocery entries by their IDs, including the ones in grocery lists that other guilds have shared with it.
+func (s *GroceryServiceImpl) FindGuildGroceryEntries(ctx context.Context, guildID string, ids []uint) ([]models.GroceryEntry, error) {
+	entries, err := s.groceryEntryRepo.FindByGuildAndIDs(ctx, guildID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == len(ids) {
+		return entries, nil
+	}
+	sharedLists, err := s.FindSharedGroceryLists(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, gl := range sharedLists {
+		sharedEntries, err := s.groceryEntryRepo.FindByGuildAndIDs(ctx, gl.GuildID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range sharedEntries {
+			// the owning guild's other lists aren't shared
+			if entry.GroceryListID != nil && *entry.GroceryListID == gl.ID {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// FindSharedGroceryLists returns the other guilds' grocery lists that the guild has joined.
+func (s *GroceryServiceImpl) FindSharedGroceryLists(ctx context.Context, guildID string) ([]models.GroceryList, error) {
+	shares, err := s.groceryListShareRepo.FindByGuildID(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(shares))
+	for i, share := range shares {
+		ids[i] = share.GroceryListID
+	}
+	groceryLists, err := s.groceryListRepo.WithContext(ctx).FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	// lists can only be shared while they're public
+	sharedLists := make([]models.GroceryList, 0, len(groceryLists))
+	for _, gl := range groceryLists {
+		if gl.IsPublic() {
+			sharedLists = append(sharedLists, gl)
+		}
+	}
+	return sharedLists, nil
+}
+
+// getGroceryListGuildIDs returns the guild that owns the grocery list, followed by the guilds that it has been shared into.
+func (s *GroceryServiceImpl) getGroceryListGuildIDs(ctx context.Context, groceryList *models.GroceryList) ([]string, error) {
+	guildIDs := []string{groceryList.GuildID}
+	if !groceryList.IsPublic() {
+		return guildIDs, nil
+	}
+	shares, err := s.groceryListShareRepo.FindByGroceryListID(ctx, groceryList.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shares {
+		guildIDs = append(guildIDs, share.GuildID)
+	}
+	return guildIDs, nil
+}
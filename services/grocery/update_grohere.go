@@ -2,20 +2,45 @@ package grocery
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/pkg/errors"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/utils"
 	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 	"go.uber.org/zap"
 )
 
+const msgPrivateGroceryListGrohere = ":shopping_cart: %s\n:lock: *This grocery list is private, so it can't be shown here. Use `!grolist:%s` to see it.*"
+
 var (
 	ErrCannotUpdateGrohere = errors.New("cannot edit attached message/channel: deleting !grohere entry")
 )
 
+// OnGroceryListEdit queues updates for the grocery list's !grohere message and the guild's !grohere all - in every guild that the list has been shared into, too.
+// They're rendered in the background shortly after, so that a burst of edits only results in one message edit.
 func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList *models.GroceryList, guildID string) error {
+	if groceryList == nil {
+		s.scheduleGrohereUpdate(grohereUpdateKey{guildID: guildID}, nil, grohereUpdateWindow)
+		return s.UpdateGuildGrohere(ctx, guildID)
+	}
+	guildIDs, err := s.getGroceryListGuildIDs(ctx, groceryList)
+	if err != nil {
+		return err
+	}
+	for _, id := range guildIDs {
+		s.scheduleGrohereUpdate(grohereUpdateKey{guildID: id, groceryListID: groceryList.ID}, groceryList, grohereUpdateWindow)
+		if err := s.UpdateGuildGrohere(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GroceryServiceImpl) editGroceryListGrohere(ctx context.Context, groceryList *models.GroceryList, guildID string) error {
+	s.publishGroceryListUpdated(ctx, groceryList, guildID)
 	groceryListID := groceryList.GetID()
 	grohereRecords, err := s.grohereRepo.FindByQueryWithConfig(&models.GrohereRecord{GuildID: guildID, GroceryListID: groceryListID}, repositories.GrohereRecordQueryOpts{
 		IsStrongNilForGroceryListID: true,
@@ -24,7 +49,7 @@ func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList
 		return err
 	}
 	if len(grohereRecords) == 0 {
-		return s.UpdateGuildGrohere(ctx, guildID)
+		return nil
 	}
 	// marshal text
 	groceryLists := make([]models.GroceryList, 0, 1)
@@ -32,7 +57,7 @@ func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList
 		groceryLists = append(groceryLists, *groceryList)
 	}
 	groceries, err := s.groceryEntryRepo.FindByQueryWithConfig(&models.GroceryEntry{
-		GuildID:       guildID,
+		GuildID:       groceryList.GetGuildID(guildID),
 		GroceryListID: groceryListID,
 	}, repositories.GroceryEntryQueryOpts{
 		IsStrongNilForGroceryListID: true,
@@ -46,6 +71,12 @@ func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList
 			s.logger.Error("Failed to process listless groceries", zap.Error(err))
 		}
 	}
+	components := groceryutils.GetGrohereComponents(groceryutils.GetGrohereScope(groceryList), groceryLists, groceries)
+	if !groceryList.IsPublic() {
+		// everyone in the channel can see a !grohere message
+		grohereText = fmt.Sprintf(msgPrivateGroceryListGrohere, groceryList.GetName(), groceryList.ListLabel)
+		components = []discordgo.MessageComponent{}
+	}
 	// if (groceryList == nil && count > 0) || (groceryList != nil && count > 1) {
 	// 	grohereText += fmt.Sprintf("\nand %d other grocery lists (use `!grohere all` to get a self-updating list for all groceries, or use `!grolist all` to display them).", count)
 	// }
@@ -53,8 +84,12 @@ func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList
 	record := grohereRecords[0]
 	grohereChannelID := record.GrohereChannelID
 	grohereMsgID := record.GrohereMessageID
-	_, err = s.sess.ChannelMessageEdit(grohereChannelID, grohereMsgID, grohereText)
+	pages := groceryutils.PaginateText(grohereText, utils.DiscordMessageMaxLength)
+	err = s.editGrohereMessage(grohereChannelID, grohereMsgID, pages[0], components)
 	if err != nil {
+		if _, isRateLimited := grohereRetryAfter(err); isRateLimited {
+			return err
+		}
 		if discordErr, ok := err.(*discordgo.RESTError); ok {
 			s.logger.Error(
 				"Cannot edit attached message/channel: deleting !grohere entry",
@@ -67,10 +102,51 @@ func (s *GroceryServiceImpl) OnGroceryListEdit(ctx context.Context, groceryList
 		}
 		return ErrCannotUpdateGrohere
 	}
-	return s.UpdateGuildGrohere(ctx, guildID)
+	extraMessageIDs, err := s.syncGrohereExtraMessages(grohereChannelID, models.SplitMessageIDs(record.ExtraMessageIDs), pages[1:])
+	if joined := models.JoinMessageIDs(extraMessageIDs); joined != record.ExtraMessageIDs {
+		record.ExtraMessageIDs = joined
+		if err := s.grohereRepo.Put(&record); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// OnGroceryEntriesEdit runs OnGroceryListEdit for every grocery list that the entries belong to.
+func (s *GroceryServiceImpl) OnGroceryEntriesEdit(ctx context.Context, guildID string, entries []models.GroceryEntry) error {
+	editedListIDs := make(map[uint]bool)
+	for _, entry := range entries {
+		var groceryListID uint
+		if entry.GroceryListID != nil {
+			groceryListID = *entry.GroceryListID
+		}
+		if editedListIDs[groceryListID] {
+			continue
+		}
+		editedListIDs[groceryListID] = true
+		var groceryList *models.GroceryList
+		if groceryListID != 0 {
+			// entries of a shared list belong to the guild that owns it
+			gl, err := s.groceryListRepo.GetByQuery(&models.GroceryList{ID: groceryListID, GuildID: entry.GuildID})
+			if err != nil {
+				return err
+			}
+			groceryList = gl
+		}
+		if err := s.OnGroceryListEdit(ctx, groceryList, guildID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// UpdateGuildGrohere queues an update for the guild's !grohere all.
 func (s *GroceryServiceImpl) UpdateGuildGrohere(ctx context.Context, guildID string) error {
+	s.scheduleGrohereUpdate(grohereUpdateKey{guildID: guildID, isGuildWide: true}, nil, grohereUpdateWindow)
+	return nil
+}
+
+func (s *GroceryServiceImpl) editGuildGrohere(ctx context.Context, guildID string) error {
 	gConfig, err := s.guildConfigRepo.Get(guildID)
 	if err != nil {
 		return err
@@ -78,32 +154,112 @@ func (s *GroceryServiceImpl) UpdateGuildGrohere(ctx context.Context, guildID str
 	if gConfig == nil || gConfig.GrohereChannelID == nil || gConfig.GrohereMessageID == nil {
 		return nil
 	}
-	groceryLists, err := s.groceryListRepo.FindByQuery(&models.GroceryList{GuildID: guildID})
-	if err != nil {
-		return err
-	}
-	groceries, err := s.groceryEntryRepo.FindByQuery(&models.GroceryEntry{GuildID: guildID})
+	groceryLists, groceries, err := s.FindGuildGroceryLists(ctx, guildID)
 	if err != nil {
 		return err
 	}
+	groceryLists, groceries = utils.FilterGroceryLists(groceryLists, groceries, func(gl *models.GroceryList) bool {
+		return gl.IsPublic()
+	})
 	grohereText, listlessGroceries := groceryutils.GetGrohereText(groceryLists, groceries, false)
 	if len(listlessGroceries) > 0 {
 		if err := s.ProcessListlessGroceries(ctx, listlessGroceries); err != nil {
 			s.logger.Error("Failed to process listless groceries", zap.Error(err))
 		}
 	}
-	_, err = s.sess.ChannelMessageEdit(*gConfig.GrohereChannelID, *gConfig.GrohereMessageID, grohereText)
+	pages := groceryutils.PaginateText(grohereText, utils.DiscordMessageMaxLength)
+	components := groceryutils.GetGrohereComponents(groceryutils.GrohereScopeAll, groceryLists, groceries)
+	err = s.editGrohereMessage(*gConfig.GrohereChannelID, *gConfig.GrohereMessageID, pages[0], components)
 	if err != nil {
+		if _, isRateLimited := grohereRetryAfter(err); isRateLimited {
+			return err
+		}
 		if discordErr, ok := err.(*discordgo.RESTError); ok {
 			s.logger.Error("Cannot edit attached message/channel: deleting !grohere entry", zap.Any("discordErr", discordErr))
 			// clear grohere entry as it refers to an unknown channel
 			gConfig.GrohereChannelID = nil
 			gConfig.GrohereMessageID = nil
+			gConfig.GrohereExtraMessageIDs = ""
 			if err := s.guildConfigRepo.Put(gConfig); err != nil {
 				return err
 			}
 		}
 		return ErrCannotUpdateGrohere
 	}
-	return nil
+	extraMessageIDs, err := s.syncGrohereExtraMessages(*gConfig.GrohereChannelID, models.SplitMessageIDs(gConfig.GrohereExtraMessageIDs), pages[1:])
+	if joined := models.JoinMessageIDs(extraMessageIDs); joined != gConfig.GrohereExtraMessageIDs {
+		gConfig.GrohereExtraMessageIDs = joined
+		if err := s.guildConfigRepo.Put(gConfig); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// editGrohereMessage edits the first message of a !grohere, which is where its components go.
+func (s *GroceryServiceImpl) editGrohereMessage(channelID string, messageID string, content string, components []discordgo.MessageComponent) error {
+	_, err := s.sess.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         messageID,
+		Channel:    channelID,
+		Content:    &content,
+		Components: &components,
+	}, discordgo.WithRetryOnRatelimit(false))
+	return err
+}
+
+// syncGrohereExtraMessages makes the messages that follow a !grohere message show the rest of its pages,
+// sending new messages when the list has grown and deleting them once it has shrunk.
+// The extra messages' IDs are returned even if it fails partway through, so that they can be saved.
+func (s *GroceryServiceImpl) syncGrohereExtraMessages(channelID string, extraMessageIDs []string, pages []string) ([]string, error) {
+	synced := make([]string, 0, len(pages))
+	for i, page := range pages {
+		existingID := ""
+		if i < len(extraMessageIDs) {
+			existingID = extraMessageIDs[i]
+		}
+		messageID, err := s.sendOrEditGrohereMessage(channelID, existingID, page)
+		if err != nil {
+			// hold on to the messages that we haven't got to, so that they can be reused (or cleaned up) next time
+			if i < len(extraMessageIDs) {
+				synced = append(synced, extraMessageIDs[i:]...)
+			}
+			return synced, err
+		}
+		synced = append(synced, messageID)
+	}
+	for i := len(pages); i < len(extraMessageIDs); i++ {
+		err := s.sess.ChannelMessageDelete(channelID, extraMessageIDs[i], discordgo.WithRetryOnRatelimit(false))
+		if _, isRateLimited := grohereRetryAfter(err); isRateLimited {
+			return append(synced, extraMessageIDs[i:]...), err
+		}
+		if err != nil {
+			// most likely deleted by someone already
+			s.logger.Warn("Cannot delete extra !grohere message.", zap.String("MessageID", extraMessageIDs[i]), zap.Error(err))
+		}
+	}
+	return synced, nil
+}
+
+func (s *GroceryServiceImpl) sendOrEditGrohereMessage(channelID string, messageID string, content string) (string, error) {
+	if messageID != "" {
+		_, err := s.sess.ChannelMessageEdit(channelID, messageID, content, discordgo.WithRetryOnRatelimit(false))
+		if err == nil {
+			return messageID, nil
+		}
+		if _, isRateLimited := grohereRetryAfter(err); isRateLimited {
+			return "", err
+		}
+		s.logger.Warn("Cannot edit extra !grohere message, sending a new one instead.", zap.String("MessageID", messageID), zap.Error(err))
+	}
+	msg, err := s.sess.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			// don't ping whoever last updated the list
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	}, discordgo.WithRetryOnRatelimit(false))
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
 }
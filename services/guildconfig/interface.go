@@ -1,7 +1,10 @@
 package guildconfig
 
 import (
+	"context"
+
 	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -12,18 +15,22 @@ var (
 
 type GuildConfigService interface {
 	InitialiseGuildConfig(s *discordgo.Session)
+	HasCapability(ctx context.Context, m *dto.MemberContext, capability string) (bool, error)
+	GetMemberRoleIDs(ctx context.Context, m *dto.MemberContext) ([]string, error)
 }
 
 type GuildConfigServiceImpl struct {
 	db     *gorm.DB
 	logger *zap.Logger
+	sess   *discordgo.Session
 }
 
-func Init(db *gorm.DB, logger *zap.Logger) {
+func Init(db *gorm.DB, logger *zap.Logger, sess *discordgo.Session) {
 	if Service == nil {
 		Service = &GuildConfigServiceImpl{
 			db:     db,
 			logger: logger.Named("guildconfig"),
+			sess:   sess,
 		}
 	}
 }
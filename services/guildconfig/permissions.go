@@ -0,0 +1,90 @@
+package guildconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+)
+
+var errNoDiscordSession = errors.New("cannot look up guild members without a Discord session")
+
+// HasCapability checks whether the member can do what the capability covers (see models.Capabilities).
+// Administrators can always do everything, so that they can't lock themselves out.
+func (s *GuildConfigServiceImpl) HasCapability(ctx context.Context, m *dto.MemberContext, capability string) (bool, error) {
+	if m.GuildID == "" || models.IsPersonalGuildID(m.GuildID) {
+		// not in a server, so there's nobody to restrict it to
+		return true, nil
+	}
+	guildConfigRepo := &repositories.GuildConfigRepositoryImpl{DB: s.db}
+	permissions, err := guildConfigRepo.FindRolePermissions(ctx, m.GuildID, capability)
+	if err != nil {
+		return false, err
+	}
+	if len(permissions) == 0 && !models.IsAdministratorOnlyByDefault(capability) {
+		return true, nil
+	}
+	if len(permissions) > 0 {
+		roleIDs, err := s.GetMemberRoleIDs(ctx, m)
+		if err != nil {
+			return false, err
+		}
+		hasRole := make(map[string]bool, len(roleIDs))
+		for _, roleID := range roleIDs {
+			hasRole[roleID] = true
+		}
+		for _, p := range permissions {
+			if hasRole[p.RoleID] {
+				return true, nil
+			}
+		}
+	}
+	return s.isAdministrator(m)
+}
+
+// GetMemberRoleIDs returns m.RoleIDs, looking them up through Discord if they're unknown.
+func (s *GuildConfigServiceImpl) GetMemberRoleIDs(ctx context.Context, m *dto.MemberContext) ([]string, error) {
+	if m.RoleIDs != nil {
+		return m.RoleIDs, nil
+	}
+	if s.sess == nil {
+		return nil, errNoDiscordSession
+	}
+	if member, err := s.sess.State.Member(m.GuildID, m.UserID); err == nil {
+		return member.Roles, nil
+	}
+	member, err := s.sess.GuildMember(m.GuildID, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return member.Roles, nil
+}
+
+func (s *GuildConfigServiceImpl) isAdministrator(m *dto.MemberContext) (bool, error) {
+	permissions := int64(0)
+	if m.Permissions != nil {
+		permissions = *m.Permissions
+	} else {
+		if s.sess == nil {
+			return false, errNoDiscordSession
+		}
+		p, err := s.sess.UserChannelPermissions(m.UserID, m.ChannelID)
+		if err != nil {
+			return false, err
+		}
+		permissions = p
+	}
+	return permissions&discordgo.PermissionAdministrator == discordgo.PermissionAdministrator, nil
+}
+
+// FmtCapabilityDeniedMessage is the reply for people who don't have the capability.
+func FmtCapabilityDeniedMessage(capability string) string {
+	return fmt.Sprintf(
+		":no_entry: Sorry, you don't have a role that's allowed to %s in this server. An administrator can change that through `/config permissions`.",
+		models.CapabilityDescriptions[capability],
+	)
+}
@@ -9,24 +9,112 @@ import (
 
 func (s *GuildsServiceImpl) ResetGuild(ctx context.Context, guildID string) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the guild's lists disappear from the guilds that they've been shared with, and lists shared with the guild stop being shared
+		var groceryListIDs []uint
+		if r := tx.Model(&models.GroceryList{}).Where("guild_id = ?", guildID).Pluck("id", &groceryListIDs); r.Error != nil {
+			return r.Error
+		}
+		if len(groceryListIDs) > 0 {
+			if err := createSharedGroceryListTombstones(tx, groceryListIDs); err != nil {
+				return err
+			}
+		}
 		if r := tx.Delete(&models.GroceryEntry{}, "guild_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
 		if r := tx.Delete(&models.GuildConfig{}, "guild_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
+		if len(groceryListIDs) > 0 {
+			if r := tx.Delete(&models.GrohereRecord{}, "grocery_list_id IN ?", groceryListIDs); r.Error != nil {
+				return r.Error
+			}
+			if r := tx.Delete(&models.GroceryListShare{}, "grocery_list_id IN ?", groceryListIDs); r.Error != nil {
+				return r.Error
+			}
+		}
+		if r := tx.Delete(&models.GroceryListShare{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GroceryListInvite{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GroceryStaple{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GroceryReminder{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GroceryTemplate{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.Recipe{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
 		if r := tx.Delete(&models.GroceryList{}, "guild_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
 		if r := tx.Delete(&models.GrohereRecord{}, "guild_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
+		// they map each reaction to the ID of one of the guild's entries, which are all gone now
+		if r := tx.Delete(&models.GrolistReactionMessage{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
 		if r := tx.Delete(&models.GuildRegistration{}, "guild_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
 		if r := tx.Delete(&models.ApiClient{}, "client_id = ?", guildID); r.Error != nil {
 			return r.Error
 		}
+		if r := tx.Delete(&models.GuildCategoryOverride{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GuildRolePermission{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.UndoJournalEntry{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.GroceryEntryChange{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.DeletionTombstone{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.WebhookSubscription{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		if r := tx.Delete(&models.WebhookDelivery{}, "guild_id = ?", guildID); r.Error != nil {
+			return r.Error
+		}
+		// one tombstone (without any IDs) for the whole guild, so that GET /sync clients know to throw away everything they have
+		if r := tx.Create(&models.DeletionTombstone{GuildID: guildID, EntityType: models.TombstoneEntityGuild}); r.Error != nil {
+			return r.Error
+		}
 		return nil
 	})
 }
+
+// createSharedGroceryListTombstones leaves tombstones for the grocery lists (and their entries) in the guilds that they've been shared with,
+// so that those guilds' GET /sync clients drop them too. Must be called before the lists' entries are deleted.
+func createSharedGroceryListTombstones(tx *gorm.DB, groceryListIDs []uint) error {
+	var shares []models.GroceryListShare
+	if r := tx.Where("grocery_list_id IN ?", groceryListIDs).Find(&shares); r.Error != nil {
+		return r.Error
+	}
+	for _, share := range shares {
+		var entryIDs []uint
+		if r := tx.Model(&models.GroceryEntry{}).Where("grocery_list_id = ?", share.GroceryListID).Pluck("id", &entryIDs); r.Error != nil {
+			return r.Error
+		}
+		tombstones := []models.DeletionTombstone{{GuildID: share.GuildID, EntityType: models.TombstoneEntityGroceryList, EntityID: share.GroceryListID}}
+		for _, id := range entryIDs {
+			tombstones = append(tombstones, models.DeletionTombstone{GuildID: share.GuildID, EntityType: models.TombstoneEntityGroceryEntry, EntityID: id})
+		}
+		if r := tx.Create(&tombstones); r.Error != nil {
+			return r.Error
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package guilds
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	dbUtils "github.com/verzac/grocer-discord-bot/db"
+	"github.com/verzac/grocer-discord-bot/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func setupResetGuildTest(t *testing.T) (*GuildsServiceImpl, *gorm.DB) {
+	t.Helper()
+	t.Setenv("GROCER_BOT_DB_SOURCE_CHANGELOG", "file://../../db/changelog")
+	db := dbUtils.Setup(filepath.Join(t.TempDir(), "gorm.db"), zap.NewNop(), "test")
+	return &GuildsServiceImpl{db: db}, db
+}
+
+func countByGuild(t *testing.T, db *gorm.DB, model interface{}, guildID string) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(model).Where("guild_id = ?", guildID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestResetGuild(t *testing.T) {
+	s, db := setupResetGuildTest(t)
+	for _, guildID := range []string{"reset", "other"} {
+		if err := db.Create(&models.GuildRolePermission{GuildID: guildID, RoleID: "role", Capability: models.CapabilityManageLists}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&models.GrolistReactionMessage{MessageID: "message-" + guildID, GuildID: guildID, ChannelID: "channel", GroceryEntryIDs: "1,2"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.ResetGuild(context.Background(), "reset"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []interface{}{&models.GuildRolePermission{}, &models.GrolistReactionMessage{}} {
+		if count := countByGuild(t, db, model, "reset"); count != 0 {
+			t.Errorf("%T: expected the guild's rows to be deleted, %d left", model, count)
+		}
+		if count := countByGuild(t, db, model, "other"); count != 1 {
+			t.Errorf("%T: expected other guilds' rows to be kept, got %d", model, count)
+		}
+	}
+}
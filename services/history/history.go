@@ -0,0 +1,29 @@
+package history
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"go.uber.org/zap"
+)
+
+func (s *HistoryServiceImpl) Record(ctx context.Context, changes []models.GroceryEntryChange) {
+	if err := s.groceryEntryChangeRepo.Create(ctx, changes); err != nil {
+		s.logger.Error("Failed to record grocery entry changes.", zap.Error(err), zap.Int("count", len(changes)))
+	}
+}
+
+// GetGroceryListHistory returns the latest changes made to a grocery list (nil for the default list), newest first.
+func (s *HistoryServiceImpl) GetGroceryListHistory(ctx context.Context, guildID string, groceryList *models.GroceryList, limit int) ([]models.GroceryEntryChange, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+	return s.groceryEntryChangeRepo.FindByGroceryList(ctx, guildID, groceryList.GetID(), limit)
+}
+
+func (s *HistoryServiceImpl) GetGroceryEntryHistory(ctx context.Context, guildID string, groceryEntryID uint) ([]models.GroceryEntryChange, error) {
+	return s.groceryEntryChangeRepo.FindByGroceryEntry(ctx, guildID, groceryEntryID)
+}
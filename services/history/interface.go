@@ -0,0 +1,40 @@
+package history
+
+import (
+	"context"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultHistoryLimit = 10
+	MaxHistoryLimit     = 100
+)
+
+var (
+	Service HistoryService
+)
+
+type HistoryService interface {
+	// Record appends changes to the change-log. Failing to record shouldn't fail the change itself, so errors are only logged.
+	Record(ctx context.Context, changes []models.GroceryEntryChange)
+	GetGroceryListHistory(ctx context.Context, guildID string, groceryList *models.GroceryList, limit int) ([]models.GroceryEntryChange, error)
+	GetGroceryEntryHistory(ctx context.Context, guildID string, groceryEntryID uint) ([]models.GroceryEntryChange, error)
+}
+
+type HistoryServiceImpl struct {
+	groceryEntryChangeRepo repositories.GroceryEntryChangeRepository
+	logger                 *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		Service = &HistoryServiceImpl{
+			groceryEntryChangeRepo: &repositories.GroceryEntryChangeRepositoryImpl{DB: db},
+			logger:                 logger.Named("history"),
+		}
+	}
+}
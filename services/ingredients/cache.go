@@ -14,6 +14,10 @@ type pendingIngredients struct {
 	GuildID     string
 	AuthorID    string
 	ListLabel   string
+	// kept for when the recipe gets saved to the recipe book
+	Title     string
+	SourceURL string
+	Servings  int
 }
 
 type pendingCache struct {
@@ -32,6 +36,11 @@ func (p *pendingCache) set(data *pendingIngredients) string {
 	return key
 }
 
+// replace swaps out the pending ingredients stored under key, which also gives the author another pendingDefaultTTL to confirm them.
+func (p *pendingCache) replace(key string, data *pendingIngredients) {
+	p.c.Set(key, data, pendingDefaultTTL)
+}
+
 func (p *pendingCache) peek(key string) (*pendingIngredients, bool) {
 	v, ok := p.c.Get(key)
 	if !ok {
@@ -2,22 +2,27 @@ package ingredients
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/verzac/grocer-discord-bot/dto"
 	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
 	"github.com/verzac/grocer-discord-bot/services/registration"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
 	"go.uber.org/zap"
 )
 
-func (s *IngredientsServiceImpl) StorePending(ingredients []string, guildID, authorID, listLabel string) string {
+func (s *IngredientsServiceImpl) StorePending(recipe *FetchedRecipe, guildID, authorID, listLabel string) string {
 	return s.memCache.set(&pendingIngredients{
-		Ingredients: ingredients,
+		Ingredients: recipe.Ingredients,
 		GuildID:     guildID,
 		AuthorID:    authorID,
 		ListLabel:   strings.TrimSpace(listLabel),
+		Title:       recipe.Title,
+		SourceURL:   recipe.URL,
+		Servings:    recipe.Servings,
 	})
 }
 
@@ -33,23 +38,107 @@ func (s *IngredientsServiceImpl) PendingAuthorID(cacheKey string) (authorID stri
 	return p.AuthorID, true
 }
 
-func (s *IngredientsServiceImpl) ConfirmAndAdd(ctx context.Context, cacheKey, authorID string) (addedCount int, err error) {
+func (s *IngredientsServiceImpl) PendingRecipe(cacheKey string) (recipe *FetchedRecipe, authorID string, listLabel string, ok bool) {
+	p, ok := s.memCache.peek(cacheKey)
+	if !ok || p == nil {
+		return nil, "", "", false
+	}
+	return &FetchedRecipe{
+		Title:       p.Title,
+		URL:         p.SourceURL,
+		Ingredients: p.Ingredients,
+		Servings:    p.Servings,
+	}, p.AuthorID, p.ListLabel, true
+}
+
+func (s *IngredientsServiceImpl) UpdatePending(ctx context.Context, cacheKey string, m *dto.MemberContext, ingredients []string, listLabel string) error {
+	p, ok := s.memCache.peek(cacheKey)
+	if !ok {
+		return ErrPendingNotFound
+	}
+	if keyGuild, keyHasGuild := models.GuildIDFromKey(cacheKey); !keyHasGuild || keyGuild != p.GuildID {
+		return ErrPendingNotFound
+	}
+	if p.AuthorID != m.UserID {
+		return ErrWrongAuthor
+	}
+	lines := make([]string, 0, len(ingredients))
+	for _, line := range ingredients {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return ErrNoIngredients
+	}
+	listLabel = strings.TrimSpace(listLabel)
+	// checked now rather than when they're confirmed, so that the author can fix it straight away
+	if _, err := s.ResolveGroceryList(ctx, m, listLabel); err != nil {
+		return err
+	}
+	// copied rather than changed in place, since the buttons can be pressed concurrently
+	updated := *p
+	updated.Ingredients = lines
+	updated.ListLabel = listLabel
+	s.memCache.replace(cacheKey, &updated)
+	return nil
+}
+
+func (s *IngredientsServiceImpl) SaveRecipe(ctx context.Context, cacheKey, authorID, name string, servings int) (recipe *models.Recipe, replaced bool, err error) {
+	p, ok := s.memCache.peek(cacheKey)
+	if !ok {
+		return nil, false, ErrPendingNotFound
+	}
+	if keyGuild, keyHasGuild := models.GuildIDFromKey(cacheKey); !keyHasGuild || keyGuild != p.GuildID {
+		return nil, false, ErrPendingNotFound
+	}
+	if p.AuthorID != authorID {
+		return nil, false, ErrWrongAuthor
+	}
+	recipe = &models.Recipe{
+		GuildID:     p.GuildID,
+		Name:        name,
+		CreatedByID: &authorID,
+	}
+	if p.Title != "" {
+		recipe.Title = &p.Title
+	}
+	if p.SourceURL != "" {
+		recipe.SourceURL = &p.SourceURL
+	}
+	if servings > 0 {
+		recipe.Servings = &servings
+	}
+	if err := recipe.SetIngredients(p.Ingredients); err != nil {
+		return nil, false, err
+	}
+	// the pending ingredients are kept around, so that they can still be added to the grocery list
+	replaced, err = recipes.Service.SaveRecipe(ctx, recipe)
+	if err != nil {
+		return nil, false, err
+	}
+	return recipe, replaced, nil
+}
+
+func (s *IngredientsServiceImpl) ConfirmAndAdd(ctx context.Context, cacheKey string, m *dto.MemberContext) (addedCount int, err error) {
 	p0, ok := s.memCache.peek(cacheKey)
 	if !ok {
 		return 0, ErrPendingNotFound
 	}
-	if p0.AuthorID != authorID {
+	if p0.AuthorID != m.UserID {
 		return 0, ErrWrongAuthor
 	}
-	keyGuild, _, keyHasGuild := strings.Cut(cacheKey, ":")
+	keyGuild, keyHasGuild := models.GuildIDFromKey(cacheKey)
 	if !keyHasGuild || keyGuild != p0.GuildID {
 		return 0, ErrPendingNotFound
 	}
 
-	groceryList, err := s.resolveGroceryList(ctx, p0.GuildID, p0.ListLabel)
+	groceryList, err := s.ResolveGroceryList(ctx, m, p0.ListLabel)
 	if err != nil {
 		return 0, err
 	}
+	// shared lists (and their entries) belong to the guild that shared them
+	guildID := groceryList.GetGuildID(p0.GuildID)
 
 	toInsert := make([]models.GroceryEntry, 0, len(p0.Ingredients))
 	for _, item := range p0.Ingredients {
@@ -58,22 +147,36 @@ func (s *IngredientsServiceImpl) ConfirmAndAdd(ctx context.Context, cacheKey, au
 			continue
 		}
 		aID := p0.AuthorID
+		itemDesc, quantity, unit := groceryutils.ParseQuantity(item)
 		toInsert = append(toInsert, models.GroceryEntry{
-			ItemDesc:    item,
-			GuildID:     p0.GuildID,
+			ItemDesc:    itemDesc,
+			Quantity:    quantity,
+			Unit:        unit,
+			GuildID:     guildID,
 			UpdatedByID: &aID,
 		})
 	}
 	if len(toInsert) == 0 {
-		return 0, errors.New("no ingredients to add")
+		return 0, ErrNoIngredients
+	}
+
+	if err := grocery.Service.AssignCategories(ctx, guildID, toInsert); err != nil {
+		return 0, err
 	}
 
-	registrationContext, regErr := registration.Service.GetRegistrationContext(p0.GuildID)
+	registrationContext, regErr := registration.Service.GetRegistrationContext(guildID)
 	if regErr != nil {
 		s.logger.Error("registration lookup failed", zap.Error(regErr))
 	}
 
-	limitOk, groceryEntryLimit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, p0.GuildID, len(toInsert))
+	// merge ingredients into what's already on the list (e.g. 2 eggs + 3 eggs), but still add the ones we can't merge
+	merged, toAdd, unmergeable, err := grocery.Service.MergeDuplicates(ctx, groceryList, guildID, toInsert)
+	if err != nil {
+		return 0, err
+	}
+	toAdd = append(toAdd, unmergeable...)
+
+	limitOk, groceryEntryLimit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, guildID, len(toAdd))
 	if err != nil {
 		return 0, err
 	}
@@ -86,27 +189,37 @@ func (s *IngredientsServiceImpl) ConfirmAndAdd(ctx context.Context, cacheKey, au
 		return 0, ErrPendingNotFound
 	}
 
-	rErr := s.groceryEntryRepo.WithContext(ctx).AddToGroceryList(groceryList, toInsert, pending.GuildID)
+	rErr := s.groceryEntryRepo.UpdateAndAddToGroceryList(ctx, groceryList, merged, toAdd, guildID)
 	if rErr != nil {
 		return 0, fmt.Errorf("%s", rErr.Message)
 	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeMerge, merged, pending.AuthorID))
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, toAdd, pending.AuthorID))
 
-	if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, pending.GuildID); err != nil {
+	if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, guildID); err != nil {
 		return len(toInsert), err
 	}
 	return len(toInsert), nil
 }
 
-func (s *IngredientsServiceImpl) resolveGroceryList(ctx context.Context, guildID, listLabel string) (*models.GroceryList, error) {
+func (s *IngredientsServiceImpl) ResolveGroceryList(ctx context.Context, m *dto.MemberContext, listLabel string) (*models.GroceryList, error) {
 	if listLabel == "" {
 		return nil, nil
 	}
-	gl, err := s.groceryListRepo.WithContext(ctx).GetByQuery(&models.GroceryList{ListLabel: listLabel, GuildID: guildID})
+	gl, err := grocery.Service.GetGuildGroceryList(ctx, m.GuildID, &models.GroceryList{ListLabel: listLabel})
 	if err != nil {
 		return nil, err
 	}
 	if gl == nil {
-		return nil, fmt.Errorf("Whoops, I can't seem to find the grocery list labeled as *%s*.", listLabel)
+		return nil, &GroceryListNotFoundError{ListLabel: listLabel}
+	}
+	// lists that the author cannot see might as well not exist
+	canSee, err := grocery.Service.CanSeeGroceryList(ctx, m, gl)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee {
+		return nil, &GroceryListNotFoundError{ListLabel: listLabel}
 	}
 	return gl, nil
 }
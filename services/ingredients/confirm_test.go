@@ -0,0 +1,95 @@
+package ingredients
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dbUtils "github.com/verzac/grocer-discord-bot/db"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"go.uber.org/zap"
+)
+
+func TestUpdatePending(t *testing.T) {
+	t.Setenv("GROCER_BOT_DB_SOURCE_CHANGELOG", "file://../../db/changelog")
+	db := dbUtils.Setup(filepath.Join(t.TempDir(), "gorm.db"), zap.NewNop(), "test")
+	grocery.Init(db, zap.NewNop(), nil)
+	for _, gl := range []*models.GroceryList{
+		{GuildID: "guild", ListLabel: "pharmacy"},
+		{GuildID: "guild", ListLabel: "hidden", Visibility: models.GroceryListVisibilityUsers, AllowedIDs: "someone-else"},
+		{GuildID: "owner", ListLabel: "flat"},
+	} {
+		if err := db.Create(gl).Error; err != nil {
+			t.Fatal(err)
+		}
+		if gl.GuildID == "owner" {
+			if err := db.Create(&models.GroceryListShare{GroceryListID: gl.ID, GuildID: "guild"}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name string
+		// the guild in the cache key, if it isn't the pending ingredients' guild
+		keyGuildID       string
+		authorID         string
+		lines            []string
+		listLabel        string
+		wantErr          error
+		wantListNotFound bool
+		wantLines        []string
+	}{
+		{name: "trims lines and drops blank ones", authorID: "author", lines: []string{"  2 eggs ", "", "   ", "milk"}, wantLines: []string{"2 eggs", "milk"}},
+		{name: "everything removed", authorID: "author", lines: []string{"", "  "}, wantErr: ErrNoIngredients},
+		{name: "someone else", authorID: "someone-else", lines: []string{"milk"}, wantErr: ErrWrongAuthor},
+		{name: "key from another guild", keyGuildID: "other", authorID: "author", lines: []string{"milk"}, wantErr: ErrPendingNotFound},
+		{name: "grocery list", authorID: "author", lines: []string{"plasters"}, listLabel: " pharmacy ", wantLines: []string{"plasters"}},
+		{name: "shared grocery list", authorID: "author", lines: []string{"milk"}, listLabel: "flat", wantLines: []string{"milk"}},
+		{name: "missing grocery list", authorID: "author", lines: []string{"milk"}, listLabel: "nope", wantListNotFound: true},
+		{name: "hidden grocery list", authorID: "author", lines: []string{"milk"}, listLabel: "hidden", wantListNotFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &IngredientsServiceImpl{logger: zap.NewNop(), memCache: newPendingCache()}
+			p := &pendingIngredients{Ingredients: []string{"1 egg"}, GuildID: "guild", AuthorID: "author"}
+			key := s.memCache.set(p)
+			if tt.keyGuildID != "" {
+				_, id, _ := strings.Cut(key, ":")
+				key = tt.keyGuildID + ":" + id
+				s.memCache.replace(key, p)
+			}
+
+			err := s.UpdatePending(context.Background(), key, &dto.MemberContext{GuildID: "guild", UserID: tt.authorID}, tt.lines, tt.listLabel)
+			var listErr *GroceryListNotFoundError
+			switch {
+			case tt.wantListNotFound:
+				if !errors.As(err, &listErr) {
+					t.Fatalf("expected a GroceryListNotFoundError, got %v", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			recipe, _, listLabel, ok := s.PendingRecipe(key)
+			if !ok {
+				t.Fatal("the pending ingredients are gone")
+			}
+			wantLines, wantLabel := []string{"1 egg"}, ""
+			if tt.wantLines != nil {
+				wantLines, wantLabel = tt.wantLines, strings.TrimSpace(tt.listLabel)
+			}
+			if strings.Join(recipe.Ingredients, "|") != strings.Join(wantLines, "|") || listLabel != wantLabel {
+				t.Errorf("pending ingredients are %q on %q, want %q on %q", recipe.Ingredients, listLabel, wantLines, wantLabel)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package ingredients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/utils"
+	"github.com/verzac/grocer-discord-bot/utils/recipeparser"
+)
+
+const (
+	pageFetchTimeout = 15 * time.Second
+	// recipe pages are big, but not this big
+	maxPageSize = 5 * 1024 * 1024
+	// some recipe sites turn away Go's default user agent
+	pageUserAgent = "Mozilla/5.0 (compatible; GroceryBot; +https://github.com/verzac/grocer-discord-bot)"
+)
+
+var (
+	errPrivateNetwork  = errors.New("recipes cannot be fetched from private network addresses")
+	errInvalidURL      = errors.New("invalid recipe URL")
+	errPageUnavailable = errors.New("recipe page is unavailable")
+)
+
+// pageExtractor downloads the page and reads the schema.org Recipe (JSON-LD or microdata) that most recipe sites have in them.
+// It doesn't need anything set up, but it won't find anything for links to videos.
+type pageExtractor struct {
+	client *http.Client
+}
+
+func newPageExtractor() *pageExtractor {
+	dialer := &net.Dialer{Timeout: pageFetchTimeout}
+	dialer.Control = utils.DenyPrivateNetwork(errPrivateNetwork)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &pageExtractor{
+		// redirects are followed, since every connection goes through the private network check
+		client: &http.Client{
+			Timeout:   pageFetchTimeout,
+			Transport: transport,
+		},
+	}
+}
+
+func (e *pageExtractor) Name() string {
+	return "page"
+}
+
+func (e *pageExtractor) Extract(ctx context.Context, rawURL string) (*FetchedRecipe, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", errInvalidURL, rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", pageUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: it returned %s", errPageUnavailable, resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, recipeparser.ErrRecipeNotFound
+	}
+	recipe, err := recipeparser.Parse(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &FetchedRecipe{
+		Title:       recipe.Title,
+		URL:         rawURL,
+		Ingredients: recipe.Ingredients,
+		Servings:    recipe.Servings,
+	}, nil
+}
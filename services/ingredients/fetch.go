@@ -1,29 +1,36 @@
 package ingredients
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"net/http"
+	neturl "net/url"
 	"strings"
-	"time"
 
-	"github.com/verzac/grocer-discord-bot/config"
+	"github.com/verzac/grocer-discord-bot/utils/recipeparser"
+	"go.uber.org/zap"
 )
 
-type n8nRequest struct {
-	URL string `json:"url"`
+// FetchedRecipe is what we got out of a recipe URL. Title and Servings are left empty if the recipe doesn't have them.
+type FetchedRecipe struct {
+	Title       string
+	URL         string
+	Ingredients []string
+	Servings    int
 }
 
-type n8nResponse struct {
-	List  []string `json:"list,omitempty"`
-	Error string   `json:"error,omitempty"`
+// RecipeExtractor gets a recipe out of a URL. FetchIngredients tries each extractor in turn until one of them finds a recipe
+// (see canTryNextExtractor).
+type RecipeExtractor interface {
+	// Name is used for logging
+	Name() string
+	// Extract returns recipeparser.ErrRecipeNotFound (or an error that IsErrFetchRecipeNotFound recognises) if the URL doesn't have a recipe.
+	Extract(ctx context.Context, url string) (*FetchedRecipe, error)
 }
 
 func IsErrFetchRecipeNotFound(err error) bool {
+	if errors.Is(err, recipeparser.ErrRecipeNotFound) {
+		return true
+	}
 	errMsg := err.Error()
 	if strings.Contains(errMsg, "No recipe URL found in video description.") {
 		return true
@@ -31,60 +38,39 @@ func IsErrFetchRecipeNotFound(err error) bool {
 	return false
 }
 
-func (s *IngredientsServiceImpl) FetchIngredients(ctx context.Context, url string) ([]string, error) {
+func (s *IngredientsServiceImpl) FetchIngredients(ctx context.Context, url string) (*FetchedRecipe, error) {
 	url = strings.TrimSpace(url)
 	if url == "" {
 		return nil, errors.New("url is required")
 	}
-	webhookURL := strings.TrimSpace(config.GetN8NWebhookIngredients())
-	if webhookURL == "" {
-		return nil, errors.New("N8N_WEBHOOK_INGREDIENTS is not configured")
-	}
-
-	body, err := json.Marshal(n8nRequest{URL: url})
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.GetN8NApiJWT()))
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var errObj struct {
-			Error string `json:"error"`
+	var err error
+	for _, extractor := range s.extractors {
+		var recipe *FetchedRecipe
+		recipe, err = extractor.Extract(ctx, url)
+		if err == nil {
+			s.logger.Debug("Fetched ingredients.", zap.String("Extractor", extractor.Name()), zap.String("URL", url), zap.Int("Count", len(recipe.Ingredients)))
+			return recipe, nil
 		}
-		_ = json.Unmarshal(respBody, &errObj)
-		if errObj.Error != "" {
-			return nil, fmt.Errorf("%s", errObj.Error)
+		if ctx.Err() != nil || !canTryNextExtractor(err) {
+			return nil, err
 		}
-		return nil, fmt.Errorf("n8n webhook returned status %d: %s", resp.StatusCode, string(respBody))
+		s.logger.Debug("Extractor could not fetch ingredients, trying the next one.", zap.String("Extractor", extractor.Name()), zap.String("URL", url), zap.Error(err))
 	}
-	var parsed n8nResponse
-	if err := json.Unmarshal(respBody, &parsed); err != nil {
-		return nil, fmt.Errorf("invalid n8n response: %w", err)
+	if err == nil {
+		err = errors.New("no recipe extractors are configured")
 	}
-	if parsed.Error != "" {
-		return nil, fmt.Errorf("%s", parsed.Error)
+	return nil, err
+}
+
+// canTryNextExtractor is true if the extractor couldn't find a recipe, or couldn't get to the page at all. URLs that have been refused
+// (e.g. ones pointing at our own network) aren't handed on, since the next extractor may not be as careful about where it fetches from.
+func canTryNextExtractor(err error) bool {
+	if errors.Is(err, errPrivateNetwork) || errors.Is(err, errInvalidURL) {
+		return false
 	}
-	out := make([]string, 0, len(parsed.List))
-	for _, line := range parsed.List {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			out = append(out, line)
-		}
+	if errors.Is(err, recipeparser.ErrRecipeNotFound) || errors.Is(err, errPageUnavailable) {
+		return true
 	}
-	return out, nil
+	var urlErr *neturl.Error
+	return errors.As(err, &urlErr)
 }
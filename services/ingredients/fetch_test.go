@@ -0,0 +1,61 @@
+package ingredients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/verzac/grocer-discord-bot/utils/recipeparser"
+	"go.uber.org/zap"
+)
+
+type fakeExtractor struct {
+	recipe *FetchedRecipe
+	err    error
+	calls  int
+}
+
+func (e *fakeExtractor) Name() string {
+	return "fake"
+}
+
+func (e *fakeExtractor) Extract(ctx context.Context, rawURL string) (*FetchedRecipe, error) {
+	e.calls++
+	return e.recipe, e.err
+}
+
+func TestFetchIngredients(t *testing.T) {
+	fallbackRecipe := &FetchedRecipe{URL: "https://example.com", Ingredients: []string{"1 egg"}}
+	tests := []struct {
+		name         string
+		err          error
+		wantFallback bool
+	}{
+		{"no recipe on the page", recipeparser.ErrRecipeNotFound, true},
+		{"page unavailable", fmt.Errorf("%w: it returned 403 Forbidden", errPageUnavailable), true},
+		{"transport failure", &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("connection reset by peer")}, true},
+		{"private network", &url.Error{Op: "Get", URL: "http://127.0.0.1", Err: fmt.Errorf("dial tcp 127.0.0.1:80: %w", errPrivateNetwork)}, false},
+		{"invalid URL", fmt.Errorf("%w: %q", errInvalidURL, "ftp://example.com"), false},
+		{"anything else", errors.New("something broke"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &fakeExtractor{err: tt.err}
+			fallback := &fakeExtractor{recipe: fallbackRecipe}
+			s := &IngredientsServiceImpl{logger: zap.NewNop(), extractors: []RecipeExtractor{first, fallback}}
+
+			recipe, err := s.FetchIngredients(context.Background(), "https://example.com")
+			if tt.wantFallback {
+				if err != nil || recipe != fallbackRecipe || fallback.calls != 1 {
+					t.Errorf("expected the fallback's recipe, got %+v, %v (%d call(s))", recipe, err, fallback.calls)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) || fallback.calls != 0 {
+				t.Errorf("expected %v without calling the fallback, got %v (%d call(s))", tt.err, err, fallback.calls)
+			}
+		})
+	}
+}
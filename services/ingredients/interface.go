@@ -3,7 +3,11 @@ package ingredients
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/verzac/grocer-discord-bot/config"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
 	"github.com/verzac/grocer-discord-bot/repositories"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -14,13 +18,36 @@ var (
 
 	ErrPendingNotFound = errors.New("pending ingredients not found or expired")
 	ErrWrongAuthor     = errors.New("this confirmation belongs to another user")
+	ErrNoIngredients   = errors.New("there aren't any ingredients left to add")
 )
 
+// GroceryListNotFoundError is returned when the pending ingredients are meant for a grocery list that the guild doesn't have,
+// or that the author can't see.
+type GroceryListNotFoundError struct {
+	ListLabel string
+}
+
+func (e *GroceryListNotFoundError) Error() string {
+	return fmt.Sprintf("Whoops, I can't seem to find the grocery list labeled as *%s*.", e.ListLabel)
+}
+
 type IngredientsService interface {
-	FetchIngredients(ctx context.Context, url string) ([]string, error)
-	StorePending(ingredients []string, guildID, authorID, listLabel string) string
+	FetchIngredients(ctx context.Context, url string) (*FetchedRecipe, error)
+	StorePending(recipe *FetchedRecipe, guildID, authorID, listLabel string) string
 	PendingAuthorID(cacheKey string) (authorID string, ok bool)
-	ConfirmAndAdd(ctx context.Context, cacheKey, authorID string) (addedCount int, err error)
+	// PendingRecipe returns the pending ingredients along with who they belong to and the label of the grocery list that they'll go on
+	// (empty for the default list), all from the same lookup.
+	PendingRecipe(cacheKey string) (recipe *FetchedRecipe, authorID string, listLabel string, ok bool)
+	// ResolveGroceryList returns the grocery list labeled listLabel (which may have been shared with the member's guild),
+	// or nil for the default list. Returns a GroceryListNotFoundError if there isn't one that the member can see.
+	ResolveGroceryList(ctx context.Context, m *dto.MemberContext, listLabel string) (*models.GroceryList, error)
+	// UpdatePending replaces the pending ingredients (e.g. after the author has edited them) and the label of the grocery list that
+	// they'll be added to, which is empty for the default list.
+	UpdatePending(ctx context.Context, cacheKey string, m *dto.MemberContext, ingredients []string, listLabel string) error
+	ConfirmAndAdd(ctx context.Context, cacheKey string, m *dto.MemberContext) (addedCount int, err error)
+	// SaveRecipe saves the pending ingredients into the guild's recipe book (see recipes.Service.SaveRecipe).
+	// servings can be 0 if the recipe doesn't say how many people it's for.
+	SaveRecipe(ctx context.Context, cacheKey, authorID, name string, servings int) (recipe *models.Recipe, replaced bool, err error)
 	Cancel(cacheKey string)
 }
 
@@ -28,22 +55,25 @@ type IngredientsServiceImpl struct {
 	db               *gorm.DB
 	logger           *zap.Logger
 	memCache         *pendingCache
-	groceryListRepo  repositories.GroceryListRepository
 	groceryEntryRepo repositories.GroceryEntryRepository
+	extractors       []RecipeExtractor
 }
 
 func Init(db *gorm.DB, logger *zap.Logger) {
 	if Service == nil {
+		// the recipe on the page itself is the quickest (and cheapest) to get to, so n8n is only asked if that didn't work out
+		extractors := []RecipeExtractor{newPageExtractor()}
+		if config.IsN8NEnabled() {
+			extractors = append(extractors, &n8nExtractor{})
+		}
 		Service = &IngredientsServiceImpl{
 			db:       db,
 			logger:   logger.Named("ingredients"),
 			memCache: newPendingCache(),
-			groceryListRepo: &repositories.GroceryListRepositoryImpl{
-				DB: db,
-			},
 			groceryEntryRepo: &repositories.GroceryEntryRepositoryImpl{
 				DB: db,
 			},
+			extractors: extractors,
 		}
 	}
 }
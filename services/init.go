@@ -3,20 +3,38 @@ package services
 import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/verzac/grocer-discord-bot/services/announcement"
+	"github.com/verzac/grocer-discord-bot/services/delta"
+	"github.com/verzac/grocer-discord-bot/services/events"
 	"github.com/verzac/grocer-discord-bot/services/grocery"
 	"github.com/verzac/grocer-discord-bot/services/ingredients"
 	"github.com/verzac/grocer-discord-bot/services/guildconfig"
 	"github.com/verzac/grocer-discord-bot/services/guilds"
+	"github.com/verzac/grocer-discord-bot/services/history"
+	"github.com/verzac/grocer-discord-bot/services/recipes"
 	"github.com/verzac/grocer-discord-bot/services/registration"
+	"github.com/verzac/grocer-discord-bot/services/reminders"
+	"github.com/verzac/grocer-discord-bot/services/staples"
+	"github.com/verzac/grocer-discord-bot/services/templates"
+	"github.com/verzac/grocer-discord-bot/services/undo"
+	"github.com/verzac/grocer-discord-bot/services/webhook"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 func InitServices(db *gorm.DB, logger *zap.Logger, sess *discordgo.Session) {
 	registration.Init(db, logger)
+	history.Init(db, logger)
 	grocery.Init(db, logger, sess)
 	ingredients.Init(db, logger)
-	guildconfig.Init(db, logger)
+	guildconfig.Init(db, logger, sess)
 	announcement.Init(db, logger)
 	guilds.Init(db)
+	undo.Init(db, logger)
+	delta.Init(db, logger)
+	webhook.Init(db, logger)
+	events.Init(logger)
+	staples.Init(db, logger)
+	reminders.Init(db, logger, sess)
+	templates.Init(db, logger)
+	recipes.Init(db, logger)
 }
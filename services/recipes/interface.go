@@ -0,0 +1,88 @@
+package recipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxRecipesPerGuild     = 50
+	MaxRecipeIngredients   = 100
+	MaxRecipeServings      = 100
+	MaxRecipeNameLength    = 50
+	maxRecipeTitleLength   = 200
+	maxRecipeIngredientLen = 200
+)
+
+var (
+	Service RecipesService
+
+	ErrRecipeLimitReached = fmt.Errorf("Your recipe book can only have up to %d recipes - delete one first with `/recipe-delete`.", MaxRecipesPerGuild)
+	ErrInvalidRecipeName  = fmt.Errorf("Recipes need a name of up to %d characters, e.g. `lasagne`.", MaxRecipeNameLength)
+	ErrEmptyRecipe        = errors.New("Recipes need at least one ingredient.")
+	ErrRecipeTooLarge     = fmt.Errorf("Recipes can only have up to %d ingredients of up to %d characters each.", MaxRecipeIngredients, maxRecipeIngredientLen)
+	ErrInvalidServings    = fmt.Errorf("Servings need to be a number from 1 to %d.", MaxRecipeServings)
+	ErrRecipeNotScalable  = errors.New("That recipe doesn't say how many people it serves, so I can't scale it - leave out the servings to add it as it is.")
+)
+
+// GroceryEntryLimitError is returned when adding a recipe would take its guild over its grocery entry limit.
+type GroceryEntryLimitError struct {
+	Limit int
+}
+
+func (e *GroceryEntryLimitError) Error() string {
+	return fmt.Sprintf("adding the recipe would go over the limit of %d grocery entries", e.Limit)
+}
+
+// IsUserError checks whether the error is about what the user asked for (and can be shown to them as it is).
+func IsUserError(err error) bool {
+	for _, target := range []error{
+		ErrRecipeLimitReached,
+		ErrInvalidRecipeName,
+		ErrEmptyRecipe,
+		ErrRecipeTooLarge,
+		ErrInvalidServings,
+		ErrRecipeNotScalable,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type RecipesService interface {
+	// SaveRecipe saves the recipe into its guild's recipe book, replacing the recipe that already has its name (if any).
+	SaveRecipe(ctx context.Context, recipe *models.Recipe) (replaced bool, err error)
+	// AddRecipe adds the recipe's ingredients onto a grocery list (nil for the default list), merging them into the entries that are already on there.
+	// Quantities are scaled to the number of servings, unless it's 0.
+	AddRecipe(ctx context.Context, guildID string, recipe *models.Recipe, servings int, groceryList *models.GroceryList, addedByID string) (added []models.GroceryEntry, merged []models.GroceryEntry, err error)
+	GetRecipes(ctx context.Context, guildID string) ([]models.Recipe, error)
+	// GetRecipe returns nil if the guild doesn't have a recipe with that name.
+	GetRecipe(ctx context.Context, guildID string, name string) (*models.Recipe, error)
+	// GetRecipeByID returns nil if the guild doesn't have a recipe with that ID.
+	GetRecipeByID(ctx context.Context, guildID string, id uint) (*models.Recipe, error)
+	DeleteRecipe(ctx context.Context, recipe *models.Recipe) error
+}
+
+type RecipesServiceImpl struct {
+	recipeRepo       repositories.RecipeRepository
+	groceryEntryRepo repositories.GroceryEntryRepository
+	logger           *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		Service = &RecipesServiceImpl{
+			recipeRepo:       &repositories.RecipeRepositoryImpl{DB: db},
+			groceryEntryRepo: &repositories.GroceryEntryRepositoryImpl{DB: db},
+			logger:           logger.Named("recipes"),
+		}
+	}
+}
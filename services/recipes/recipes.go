@@ -0,0 +1,169 @@
+package recipes
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+func (s *RecipesServiceImpl) SaveRecipe(ctx context.Context, recipe *models.Recipe) (bool, error) {
+	recipe.Name = models.NormaliseRecipeName(recipe.Name)
+	if recipe.Name == "" || len(recipe.Name) > MaxRecipeNameLength {
+		return false, ErrInvalidRecipeName
+	}
+	if recipe.Servings != nil && (*recipe.Servings < 1 || *recipe.Servings > MaxRecipeServings) {
+		return false, ErrInvalidServings
+	}
+	if recipe.Title != nil {
+		title := strings.TrimSpace(*recipe.Title)
+		if len(title) > maxRecipeTitleLength {
+			title = title[:maxRecipeTitleLength]
+		}
+		recipe.Title = &title
+	}
+	ingredients, err := recipe.GetIngredients()
+	if err != nil {
+		return false, err
+	}
+	lines := make([]string, 0, len(ingredients))
+	for _, line := range ingredients {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxRecipeIngredientLen {
+			return false, ErrRecipeTooLarge
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return false, ErrEmptyRecipe
+	}
+	if len(lines) > MaxRecipeIngredients {
+		return false, ErrRecipeTooLarge
+	}
+	if err := recipe.SetIngredients(lines); err != nil {
+		return false, err
+	}
+
+	existing, err := s.recipeRepo.GetByName(ctx, recipe.GuildID, recipe.Name)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		recipe.ID = existing.ID
+		recipe.CreatedByID = existing.CreatedByID
+		recipe.CreatedAt = existing.CreatedAt
+		recipe.UpdatedAt = time.Now()
+		if err := s.recipeRepo.Update(ctx, recipe); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	recipes, err := s.recipeRepo.FindByGuildID(ctx, recipe.GuildID)
+	if err != nil {
+		return false, err
+	}
+	if len(recipes) >= MaxRecipesPerGuild {
+		return false, ErrRecipeLimitReached
+	}
+	if err := s.recipeRepo.Create(ctx, recipe); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (s *RecipesServiceImpl) AddRecipe(ctx context.Context, guildID string, recipe *models.Recipe, servings int, groceryList *models.GroceryList, addedByID string) ([]models.GroceryEntry, []models.GroceryEntry, error) {
+	if servings < 0 || servings > MaxRecipeServings {
+		return nil, nil, ErrInvalidServings
+	}
+	factor := 1.0
+	if servings > 0 {
+		if recipe.Servings == nil {
+			return nil, nil, ErrRecipeNotScalable
+		}
+		factor = float64(servings) / float64(*recipe.Servings)
+	}
+	ingredients, err := recipe.GetIngredients()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// entries go into the guild that owns the grocery list, which is also whose limit they count against
+	ownerGuildID := groceryList.GetGuildID(guildID)
+	toInsert := make([]models.GroceryEntry, 0, len(ingredients))
+	for _, line := range ingredients {
+		itemDesc, quantity, unit := groceryutils.ParseQuantity(line)
+		if quantity != nil {
+			// also tidies up quantities like 1/3 when they aren't scaled
+			scaled := groceryutils.ScaleQuantity(*quantity, factor)
+			if unit == nil {
+				// nobody buys half an egg
+				scaled = math.Ceil(scaled)
+			}
+			quantity = &scaled
+		}
+		entry := models.GroceryEntry{
+			ItemDesc: itemDesc,
+			Quantity: quantity,
+			Unit:     unit,
+			GuildID:  ownerGuildID,
+		}
+		if addedByID != "" {
+			entry.UpdatedByID = &addedByID
+		}
+		toInsert = append(toInsert, entry)
+	}
+	if err := grocery.Service.AssignCategories(ctx, ownerGuildID, toInsert); err != nil {
+		return nil, nil, err
+	}
+
+	// merge ingredients into what's already on the list (e.g. 2 eggs + 3 eggs), but still add the ones we can't merge
+	merged, toAdd, unmergeable, err := grocery.Service.MergeDuplicates(ctx, groceryList, ownerGuildID, toInsert)
+	if err != nil {
+		return nil, nil, err
+	}
+	toAdd = append(toAdd, unmergeable...)
+	registrationContext, err := registration.Service.GetRegistrationContext(ownerGuildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	limitOk, limit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, ownerGuildID, len(toAdd))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !limitOk {
+		return nil, nil, &GroceryEntryLimitError{Limit: limit}
+	}
+	if rErr := s.groceryEntryRepo.UpdateAndAddToGroceryList(ctx, groceryList, merged, toAdd, ownerGuildID); rErr != nil {
+		return nil, nil, rErr
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeMerge, merged, addedByID))
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, toAdd, addedByID))
+	if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, ownerGuildID); err != nil {
+		return nil, nil, err
+	}
+	return toAdd, merged, nil
+}
+
+func (s *RecipesServiceImpl) GetRecipes(ctx context.Context, guildID string) ([]models.Recipe, error) {
+	return s.recipeRepo.FindByGuildID(ctx, guildID)
+}
+
+func (s *RecipesServiceImpl) GetRecipe(ctx context.Context, guildID string, name string) (*models.Recipe, error) {
+	return s.recipeRepo.GetByName(ctx, guildID, models.NormaliseRecipeName(name))
+}
+
+func (s *RecipesServiceImpl) GetRecipeByID(ctx context.Context, guildID string, id uint) (*models.Recipe, error) {
+	return s.recipeRepo.GetByID(ctx, guildID, id)
+}
+
+func (s *RecipesServiceImpl) DeleteRecipe(ctx context.Context, recipe *models.Recipe) error {
+	return s.recipeRepo.Delete(ctx, recipe)
+}
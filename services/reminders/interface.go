@@ -0,0 +1,66 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxRemindersPerGuild = 10
+	schedulerInterval    = time.Minute
+	// reminders that run later than this (e.g. because GroceryBot was down) say that they're late
+	lateReminderThreshold = 5 * time.Minute
+)
+
+var (
+	Service RemindersService
+
+	ErrReminderLimitReached    = fmt.Errorf("You can only have up to %d reminders per server - remove one first with `/groremind-remove`.", MaxRemindersPerGuild)
+	ErrInvalidReminderSchedule = errors.New("Hmm, reminders need to go off at most once an hour (and at least once!) - try something like `every saturday 9am`.")
+	ErrGroceryListNotPublic    = errors.New("Reminders can only be set up for public grocery lists, since anyone in the channel can see them.")
+)
+
+type RemindersService interface {
+	// AddReminder works out when the reminder is first due (in the guild's timezone) & saves it.
+	// Returns ErrInvalidReminderSchedule if the reminder never goes off, or goes off more than once an hour.
+	AddReminder(ctx context.Context, reminder *models.GroceryReminder, groceryList *models.GroceryList) error
+	RemoveReminder(ctx context.Context, reminder *models.GroceryReminder) error
+	GetReminders(ctx context.Context, guildID string) ([]models.GroceryReminder, error)
+	// RescheduleReminders works out when the guild's reminders are next due again, e.g. after its timezone has changed.
+	RescheduleReminders(ctx context.Context, guildID string) error
+	// GetLocation returns the guild's timezone (see /config set).
+	GetLocation(guildID string) (*time.Location, error)
+	// RunReminders sends the reminders that are due at now. The scheduler calls this every minute - reminders that were
+	// missed while GroceryBot was down are sent (once) when it's back up.
+	RunReminders(ctx context.Context, now time.Time)
+}
+
+type RemindersServiceImpl struct {
+	reminderRepo     repositories.GroceryReminderRepository
+	guildConfigRepo  repositories.GuildConfigRepository
+	groceryEntryRepo repositories.GroceryEntryRepository
+	sess             *discordgo.Session
+	logger           *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger, sess *discordgo.Session) {
+	if Service == nil {
+		s := &RemindersServiceImpl{
+			reminderRepo:     &repositories.GroceryReminderRepositoryImpl{DB: db},
+			guildConfigRepo:  &repositories.GuildConfigRepositoryImpl{DB: db},
+			groceryEntryRepo: &repositories.GroceryEntryRepositoryImpl{DB: db},
+			sess:             sess,
+			logger:           logger.Named("reminders"),
+		}
+		go s.runScheduler()
+		Service = s
+	}
+}
@@ -0,0 +1,206 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/utils"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+	"go.uber.org/zap"
+)
+
+var errReminderGroceryListDeleted = errors.New("the reminder's grocery list has been deleted")
+
+func (s *RemindersServiceImpl) AddReminder(ctx context.Context, reminder *models.GroceryReminder, groceryList *models.GroceryList) error {
+	if !groceryList.IsPublic() {
+		return ErrGroceryListNotPublic
+	}
+	// anything more often than that is spam, especially for the users that get DMed
+	if schedule, err := utils.ParseCron(reminder.Cron); err != nil || !schedule.RunsAtMostHourly() {
+		return ErrInvalidReminderSchedule
+	}
+	nextRunAt, err := s.getNextRunAt(reminder, time.Now())
+	if err != nil {
+		return err
+	}
+	reminder.NextRunAt = nextRunAt
+	reminder.GroceryListID = groceryList.GetID()
+	existing, err := s.reminderRepo.FindByGuildID(ctx, reminder.GuildID)
+	if err != nil {
+		return err
+	}
+	if len(existing) >= MaxRemindersPerGuild {
+		return ErrReminderLimitReached
+	}
+	return s.reminderRepo.Create(ctx, reminder)
+}
+
+func (s *RemindersServiceImpl) RemoveReminder(ctx context.Context, reminder *models.GroceryReminder) error {
+	return s.reminderRepo.Delete(ctx, reminder)
+}
+
+func (s *RemindersServiceImpl) GetReminders(ctx context.Context, guildID string) ([]models.GroceryReminder, error) {
+	return s.reminderRepo.FindByGuildID(ctx, guildID)
+}
+
+func (s *RemindersServiceImpl) RescheduleReminders(ctx context.Context, guildID string) error {
+	reminders, err := s.reminderRepo.FindByGuildID(ctx, guildID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range reminders {
+		reminder := &reminders[i]
+		nextRunAt, err := s.getNextRunAt(reminder, now)
+		if err != nil {
+			return err
+		}
+		reminder.NextRunAt = nextRunAt
+		if err := s.reminderRepo.UpdateRunTimes(ctx, reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RemindersServiceImpl) GetLocation(guildID string) (*time.Location, error) {
+	config, err := s.guildConfigRepo.Get(guildID)
+	if err != nil {
+		return nil, err
+	}
+	return config.GetLocation(), nil
+}
+
+// getNextRunAt works out when the reminder is due after t in the guild's timezone.
+func (s *RemindersServiceImpl) getNextRunAt(reminder *models.GroceryReminder, t time.Time) (time.Time, error) {
+	schedule, err := utils.ParseCron(reminder.Cron)
+	if err != nil {
+		return time.Time{}, ErrInvalidReminderSchedule
+	}
+	loc, err := s.GetLocation(reminder.GuildID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	nextRunAt := schedule.Next(t.In(loc))
+	if nextRunAt.IsZero() {
+		return time.Time{}, ErrInvalidReminderSchedule
+	}
+	// the database compares times as they're written, so they're all kept in the same timezone as time.Now()
+	return nextRunAt.In(time.Local), nil
+}
+
+func (s *RemindersServiceImpl) runScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		s.RunReminders(ctx, time.Now())
+		cancel()
+	}
+}
+
+func (s *RemindersServiceImpl) RunReminders(ctx context.Context, now time.Time) {
+	due, err := s.reminderRepo.FindDue(ctx, now)
+	if err != nil {
+		s.logger.Error("Failed to find reminders that are due.", zap.Error(err))
+		return
+	}
+	for i := range due {
+		reminder := &due[i]
+		err := s.sendReminder(ctx, reminder, now)
+		if errors.Is(err, errReminderGroceryListDeleted) {
+			if err := s.reminderRepo.Delete(ctx, reminder); err != nil {
+				s.logger.Error("Failed to delete reminder of deleted grocery list.", zap.Uint("ReminderID", reminder.ID), zap.Error(err))
+			}
+			continue
+		}
+		if err != nil {
+			s.logger.Error("Failed to send reminder.", zap.Uint("ReminderID", reminder.ID), zap.String("GuildID", reminder.GuildID), zap.Error(err))
+		}
+		// however many runs were missed, the reminder is only sent once
+		nextRunAt, err := s.getNextRunAt(reminder, now)
+		if err != nil {
+			s.logger.Error("Cannot work out when the reminder is next due - removing it.", zap.Uint("ReminderID", reminder.ID), zap.String("Cron", reminder.Cron), zap.Error(err))
+			if err := s.reminderRepo.Delete(ctx, reminder); err != nil {
+				s.logger.Error("Failed to delete reminder.", zap.Uint("ReminderID", reminder.ID), zap.Error(err))
+			}
+			continue
+		}
+		reminder.NextRunAt = nextRunAt
+		reminder.LastRunAt = &now
+		if err := s.reminderRepo.UpdateRunTimes(ctx, reminder); err != nil {
+			s.logger.Error("Failed to update reminder.", zap.Uint("ReminderID", reminder.ID), zap.Error(err))
+		}
+	}
+}
+
+// sendReminder posts what's on the reminder's grocery list to its channel, and DMs it to its users.
+func (s *RemindersServiceImpl) sendReminder(ctx context.Context, reminder *models.GroceryReminder, now time.Time) error {
+	var groceryList *models.GroceryList
+	if reminder.GroceryListID != nil {
+		gl, err := grocery.Service.GetGuildGroceryList(ctx, reminder.GuildID, &models.GroceryList{ID: *reminder.GroceryListID})
+		if err != nil {
+			return err
+		}
+		if gl == nil {
+			return errReminderGroceryListDeleted
+		}
+		groceryList = gl
+	}
+	if !groceryList.IsPublic() {
+		s.logger.Info("Skipping reminder as its grocery list is no longer public.", zap.Uint("ReminderID", reminder.ID), zap.String("GuildID", reminder.GuildID))
+		return nil
+	}
+	groceries, err := s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(&models.GroceryEntry{
+		GuildID:       groceryList.GetGuildID(reminder.GuildID),
+		GroceryListID: groceryList.GetID(),
+	}, repositories.GroceryEntryQueryOpts{
+		IsStrongNilForGroceryListID: true,
+	})
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf(":alarm_clock: Shopping reminder! Here's what's on %s:\n%s", groceryList.GetName(), groceryutils.GetGroceryListText(groceries, groceryList))
+	if len(groceries) == 0 {
+		msg = fmt.Sprintf(":alarm_clock: Shopping reminder! There's nothing on %s, so there's nothing to buy :tada:", groceryList.GetName())
+	}
+	if now.Sub(reminder.NextRunAt) > lateReminderThreshold {
+		msg = "*(Sorry, this one's late - I was offline when it was due.)*\n" + msg
+	}
+	pages := groceryutils.PaginateText(msg, utils.DiscordMessageMaxLength)
+	var sendErr error
+	if reminder.ChannelID != nil {
+		sendErr = s.sendPages(*reminder.ChannelID, pages)
+	}
+	for _, userID := range reminder.GetUserIDs() {
+		channel, err := s.sess.UserChannelCreate(userID)
+		if err != nil {
+			sendErr = err
+			continue
+		}
+		if err := s.sendPages(channel.ID, pages); err != nil {
+			sendErr = err
+		}
+	}
+	return sendErr
+}
+
+func (s *RemindersServiceImpl) sendPages(channelID string, pages []string) error {
+	for _, page := range pages {
+		if _, err := s.sess.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content: page,
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
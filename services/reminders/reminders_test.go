@@ -0,0 +1,51 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	dbUtils "github.com/verzac/grocer-discord-bot/db"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+)
+
+func TestAddReminder_Schedule(t *testing.T) {
+	t.Setenv("GROCER_BOT_DB_SOURCE_CHANGELOG", "file://../../db/changelog")
+	db := dbUtils.Setup(filepath.Join(t.TempDir(), "gorm.db"), zap.NewNop(), "test")
+	s := &RemindersServiceImpl{
+		reminderRepo:    &repositories.GroceryReminderRepositoryImpl{DB: db},
+		guildConfigRepo: &repositories.GuildConfigRepositoryImpl{DB: db},
+		logger:          zap.NewNop(),
+	}
+	tests := []struct {
+		cron    string
+		wantErr error
+	}{
+		{cron: "0 9 * * 6"},
+		{cron: "0 * * * *"},
+		{cron: "* * * * *", wantErr: ErrInvalidReminderSchedule},
+		{cron: "*/30 * * * *", wantErr: ErrInvalidReminderSchedule},
+		{cron: "0,1 9 * * *", wantErr: ErrInvalidReminderSchedule},
+		{cron: "0 0 31 2 *", wantErr: ErrInvalidReminderSchedule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			guildID := "guild " + tt.cron
+			channelID := "channel"
+			err := s.AddReminder(context.Background(), &models.GroceryReminder{GuildID: guildID, ChannelID: &channelID, Cron: tt.cron}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddReminder(%q) = %v, want %v", tt.cron, err, tt.wantErr)
+			}
+			saved, err := s.GetReminders(context.Background(), guildID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantSaved := tt.wantErr == nil; (len(saved) == 1) != wantSaved {
+				t.Errorf("saved %d reminders, want saved = %v", len(saved), wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package staples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxStaplesPerGroceryList = 25
+	schedulerInterval        = time.Minute
+)
+
+var (
+	Service StaplesService
+
+	ErrStapleLimitReached = fmt.Errorf("You can only have up to %d staples per grocery list - remove one first with `/grostaple-remove`.", MaxStaplesPerGroceryList)
+	ErrInvalidSchedule    = errors.New("Hmm, I don't know when to re-add that staple.")
+)
+
+type StaplesService interface {
+	// AddStaple saves the staple & works out when it's next due. The staple's GuildID is the guild that owns its grocery list.
+	AddStaple(ctx context.Context, staple *models.GroceryStaple) error
+	RemoveStaple(ctx context.Context, staple *models.GroceryStaple) error
+	// GetStaples returns the staples of a grocery list (nil for the default list) in guildID, oldest first.
+	GetStaples(ctx context.Context, guildID string, groceryList *models.GroceryList) ([]models.GroceryStaple, error)
+	// RunStaples puts the staples that are due at now back on their grocery lists. The scheduler calls this every minute.
+	RunStaples(ctx context.Context, now time.Time)
+}
+
+type StaplesServiceImpl struct {
+	stapleRepo             repositories.GroceryStapleRepository
+	groceryEntryRepo       repositories.GroceryEntryRepository
+	groceryListRepo        repositories.GroceryListRepository
+	groceryEntryChangeRepo repositories.GroceryEntryChangeRepository
+	logger                 *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		s := &StaplesServiceImpl{
+			stapleRepo:             &repositories.GroceryStapleRepositoryImpl{DB: db},
+			groceryEntryRepo:       &repositories.GroceryEntryRepositoryImpl{DB: db},
+			groceryListRepo:        &repositories.GroceryListRepositoryImpl{DB: db},
+			groceryEntryChangeRepo: &repositories.GroceryEntryChangeRepositoryImpl{DB: db},
+			logger:                 logger.Named("staples"),
+		}
+		go s.runScheduler()
+		Service = s
+	}
+}
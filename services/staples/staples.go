@@ -0,0 +1,202 @@
+package staples
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+	"go.uber.org/zap"
+)
+
+var errStapleGroceryListDeleted = errors.New("the staple's grocery list has been deleted")
+
+func (s *StaplesServiceImpl) AddStaple(ctx context.Context, staple *models.GroceryStaple) error {
+	now := time.Now()
+	if staple.Schedule != models.GroceryStapleScheduleChecked {
+		nextRunAt := staple.GetNextRunAfter(now)
+		if nextRunAt.IsZero() {
+			return ErrInvalidSchedule
+		}
+		staple.NextRunAt = &nextRunAt
+	}
+	staples, err := s.stapleRepo.FindByGroceryList(ctx, staple.GuildID, staple.GroceryListID)
+	if err != nil {
+		return err
+	}
+	if len(staples) >= MaxStaplesPerGroceryList {
+		return ErrStapleLimitReached
+	}
+	// only check-offs from now on count
+	staple.LastRunAt = now
+	return s.stapleRepo.Create(ctx, staple)
+}
+
+func (s *StaplesServiceImpl) RemoveStaple(ctx context.Context, staple *models.GroceryStaple) error {
+	return s.stapleRepo.Delete(ctx, staple)
+}
+
+func (s *StaplesServiceImpl) GetStaples(ctx context.Context, guildID string, groceryList *models.GroceryList) ([]models.GroceryStaple, error) {
+	return s.stapleRepo.FindByGroceryList(ctx, guildID, groceryList.GetID())
+}
+
+func (s *StaplesServiceImpl) runScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		s.RunStaples(ctx, time.Now())
+		cancel()
+	}
+}
+
+func (s *StaplesServiceImpl) RunStaples(ctx context.Context, now time.Time) {
+	// staples that fell due while GroceryBot was down are only run once
+	due, err := s.stapleRepo.FindDue(ctx, now)
+	if err != nil {
+		s.logger.Error("Failed to find staples that are due.", zap.Error(err))
+	}
+	for i := range due {
+		staple := &due[i]
+		err := s.runStaple(ctx, staple)
+		if errors.Is(err, errStapleGroceryListDeleted) {
+			continue
+		}
+		if err != nil {
+			// it's still due, so it's tried again on the next tick instead of skipping a whole period
+			s.logger.Error("Failed to run staple.", zap.Uint("StapleID", staple.ID), zap.String("GuildID", staple.GuildID), zap.Error(err))
+			continue
+		}
+		nextRunAt := staple.GetNextRunAfter(now)
+		staple.NextRunAt = &nextRunAt
+		staple.LastRunAt = now
+		if err := s.stapleRepo.UpdateRunTimes(ctx, staple); err != nil {
+			s.logger.Error("Failed to update staple.", zap.Uint("StapleID", staple.ID), zap.Error(err))
+		}
+	}
+	if err := s.runCheckedStaples(ctx, now); err != nil {
+		s.logger.Error("Failed to run staples for checked-off entries.", zap.Error(err))
+	}
+}
+
+// runCheckedStaples puts back the staples that have been checked off since they were last run.
+func (s *StaplesServiceImpl) runCheckedStaples(ctx context.Context, now time.Time) error {
+	staples, err := s.stapleRepo.FindBySchedule(ctx, models.GroceryStapleScheduleChecked)
+	if err != nil || len(staples) == 0 {
+		return err
+	}
+	since := staples[0].LastRunAt
+	for _, staple := range staples {
+		if staple.LastRunAt.Before(since) {
+			since = staple.LastRunAt
+		}
+	}
+	changes, err := s.groceryEntryChangeRepo.FindByActionBetween(ctx, models.GroceryEntryChangeCheck, since, now)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(staples))
+	for i := range staples {
+		staple := &staples[i]
+		if !isCheckedOff(staple, changes) {
+			ids = append(ids, staple.ID)
+			continue
+		}
+		if err := s.runStaple(ctx, staple); err != nil && !errors.Is(err, errStapleGroceryListDeleted) {
+			// LastRunAt stays put, so that the check-off is picked up again on the next tick
+			s.logger.Error("Failed to run staple.", zap.Uint("StapleID", staple.ID), zap.String("GuildID", staple.GuildID), zap.Error(err))
+			continue
+		}
+		ids = append(ids, staple.ID)
+	}
+	return s.stapleRepo.UpdateLastRunAt(ctx, ids, now)
+}
+
+func isCheckedOff(staple *models.GroceryStaple, changes []models.GroceryEntryChange) bool {
+	key := groceryutils.NormalizeItemDesc(staple.ItemDesc)
+	for _, c := range changes {
+		if c.GuildID != staple.GuildID || c.Before == nil || !c.CreatedAt.After(staple.LastRunAt) {
+			continue
+		}
+		if (c.GroceryListID == nil) != (staple.GroceryListID == nil) || (c.GroceryListID != nil && *c.GroceryListID != *staple.GroceryListID) {
+			continue
+		}
+		// changes only keep the display text, i.e. "Milk — 2 L"
+		itemDesc, _, _ := strings.Cut(*c.Before, " — ")
+		if groceryutils.NormalizeItemDesc(itemDesc) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// runStaple puts the staple back on its grocery list, unless it's still on there (and hasn't been checked off).
+// Staples of grocery lists that have been deleted are deleted along with them, in which case errStapleGroceryListDeleted is returned.
+func (s *StaplesServiceImpl) runStaple(ctx context.Context, staple *models.GroceryStaple) error {
+	groceryList, err := s.getGroceryList(ctx, staple)
+	if errors.Is(err, errStapleGroceryListDeleted) {
+		if dErr := s.stapleRepo.Delete(ctx, staple); dErr != nil {
+			return dErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	entries, err := s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(&models.GroceryEntry{
+		GuildID:       staple.GuildID,
+		GroceryListID: staple.GroceryListID,
+	}, repositories.GroceryEntryQueryOpts{
+		IsStrongNilForGroceryListID: true,
+	})
+	if err != nil {
+		return err
+	}
+	key := groceryutils.NormalizeItemDesc(staple.ItemDesc)
+	for _, entry := range entries {
+		if entry.CheckedAt == nil && groceryutils.NormalizeItemDesc(entry.ItemDesc) == key {
+			return nil
+		}
+	}
+	registrationContext, err := registration.Service.GetRegistrationContext(staple.GuildID)
+	if err != nil {
+		return err
+	}
+	limitOk, limit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, staple.GuildID, 1)
+	if err != nil {
+		return err
+	}
+	if !limitOk {
+		s.logger.Info("Skipping staple as its guild has reached its grocery entry limit.", zap.Uint("StapleID", staple.ID), zap.String("GuildID", staple.GuildID), zap.Int("Limit", limit))
+		return nil
+	}
+	toAdd := []models.GroceryEntry{staple.ToGroceryEntry()}
+	if err := grocery.Service.AssignCategories(ctx, staple.GuildID, toAdd); err != nil {
+		return err
+	}
+	if rErr := s.groceryEntryRepo.WithContext(ctx).AddToGroceryList(groceryList, toAdd, staple.GuildID); rErr != nil {
+		return rErr
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, toAdd, ""))
+	return grocery.Service.OnGroceryListEdit(ctx, groceryList, staple.GuildID)
+}
+
+// getGroceryList returns the staple's grocery list (nil for the default list).
+func (s *StaplesServiceImpl) getGroceryList(ctx context.Context, staple *models.GroceryStaple) (*models.GroceryList, error) {
+	if staple.GroceryListID == nil {
+		return nil, nil
+	}
+	groceryList, err := s.groceryListRepo.WithContext(ctx).GetByQuery(&models.GroceryList{ID: *staple.GroceryListID, GuildID: staple.GuildID})
+	if err != nil {
+		return nil, err
+	}
+	if groceryList == nil {
+		return nil, errStapleGroceryListDeleted
+	}
+	return groceryList, nil
+}
@@ -0,0 +1,68 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxTemplatesPerGuild  = 25
+	MaxTemplateItems      = 100
+	maxTemplateNameLength = 50
+)
+
+var (
+	Service TemplatesService
+
+	ErrTemplateLimitReached = fmt.Errorf("You can only have up to %d templates per server - delete one first with `/grotemplate delete`.", MaxTemplatesPerGuild)
+	ErrInvalidTemplateName  = fmt.Errorf("Templates need a name of up to %d characters, e.g. `camping trip`.", maxTemplateNameLength)
+	ErrEmptyTemplate        = errors.New("There's nothing on that grocery list to save as a template.")
+	ErrTemplateTooLarge     = fmt.Errorf("Templates can only have up to %d items.", MaxTemplateItems)
+	ErrGroceryListNotPublic = errors.New("Only public grocery lists can be saved as templates, since everyone in the server can use them.")
+)
+
+// GroceryEntryLimitError is returned when applying a template would take its guild over its grocery entry limit.
+type GroceryEntryLimitError struct {
+	Limit int
+}
+
+func (e *GroceryEntryLimitError) Error() string {
+	return fmt.Sprintf("applying the template would go over the limit of %d grocery entries", e.Limit)
+}
+
+type TemplatesService interface {
+	// SaveTemplate saves what's on a grocery list (nil for the default list) as guildID's template called name,
+	// replacing the items of any template that already has that name.
+	SaveTemplate(ctx context.Context, guildID string, name string, groceryList *models.GroceryList, createdByID string) (template *models.GroceryTemplate, replaced bool, err error)
+	// ApplyTemplate adds the template's items onto a grocery list (nil for the default list), skipping the ones that are already on there
+	// (and haven't been checked off).
+	ApplyTemplate(ctx context.Context, guildID string, template *models.GroceryTemplate, groceryList *models.GroceryList, appliedByID string) (added []models.GroceryEntry, skipped []models.GroceryTemplateItem, err error)
+	GetTemplates(ctx context.Context, guildID string) ([]models.GroceryTemplate, error)
+	// GetTemplate returns nil if the guild doesn't have a template with that name.
+	GetTemplate(ctx context.Context, guildID string, name string) (*models.GroceryTemplate, error)
+	// GetTemplateByID returns nil if the guild doesn't have a template with that ID.
+	GetTemplateByID(ctx context.Context, guildID string, id uint) (*models.GroceryTemplate, error)
+	DeleteTemplate(ctx context.Context, template *models.GroceryTemplate) error
+}
+
+type TemplatesServiceImpl struct {
+	templateRepo     repositories.GroceryTemplateRepository
+	groceryEntryRepo repositories.GroceryEntryRepository
+	logger           *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		Service = &TemplatesServiceImpl{
+			templateRepo:     &repositories.GroceryTemplateRepositoryImpl{DB: db},
+			groceryEntryRepo: &repositories.GroceryEntryRepositoryImpl{DB: db},
+			logger:           logger.Named("templates"),
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package templates
+
+import (
+	"context"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+	groceryutils "github.com/verzac/grocer-discord-bot/utils/grocery"
+)
+
+func (s *TemplatesServiceImpl) SaveTemplate(ctx context.Context, guildID string, name string, groceryList *models.GroceryList, createdByID string) (*models.GroceryTemplate, bool, error) {
+	name = models.NormaliseTemplateName(name)
+	if name == "" || len(name) > maxTemplateNameLength {
+		return nil, false, ErrInvalidTemplateName
+	}
+	if !groceryList.IsPublic() {
+		return nil, false, ErrGroceryListNotPublic
+	}
+	entries, err := s.findGroceryEntries(ctx, guildID, groceryList)
+	if err != nil {
+		return nil, false, err
+	}
+	// the same item twice on the list is saved once, with its quantities added up
+	_, toAdd, unmergeable := groceryutils.MergeDuplicates(nil, entries)
+	entries = append(toAdd, unmergeable...)
+	if len(entries) == 0 {
+		return nil, false, ErrEmptyTemplate
+	}
+	if len(entries) > MaxTemplateItems {
+		return nil, false, ErrTemplateTooLarge
+	}
+	items := make([]models.GroceryTemplateItem, len(entries))
+	for i := range entries {
+		items[i] = models.NewGroceryTemplateItem(&entries[i])
+	}
+
+	template, err := s.templateRepo.GetByName(ctx, guildID, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if template != nil {
+		if err := template.SetItems(items); err != nil {
+			return nil, false, err
+		}
+		template.UpdatedAt = time.Now()
+		if err := s.templateRepo.UpdateItems(ctx, template); err != nil {
+			return nil, false, err
+		}
+		return template, true, nil
+	}
+	existing, err := s.templateRepo.FindByGuildID(ctx, guildID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(existing) >= MaxTemplatesPerGuild {
+		return nil, false, ErrTemplateLimitReached
+	}
+	template = &models.GroceryTemplate{
+		GuildID:     guildID,
+		Name:        name,
+		CreatedByID: &createdByID,
+	}
+	if err := template.SetItems(items); err != nil {
+		return nil, false, err
+	}
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, false, err
+	}
+	return template, false, nil
+}
+
+func (s *TemplatesServiceImpl) ApplyTemplate(ctx context.Context, guildID string, template *models.GroceryTemplate, groceryList *models.GroceryList, appliedByID string) ([]models.GroceryEntry, []models.GroceryTemplateItem, error) {
+	items, err := template.GetItems()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := s.findGroceryEntries(ctx, guildID, groceryList)
+	if err != nil {
+		return nil, nil, err
+	}
+	onList := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.CheckedAt == nil {
+			onList[groceryutils.NormalizeItemDesc(entry.ItemDesc)] = true
+		}
+	}
+
+	// entries go into the guild that owns the grocery list, which is also whose limit they count against
+	ownerGuildID := groceryList.GetGuildID(guildID)
+	toAdd := make([]models.GroceryEntry, 0, len(items))
+	skipped := make([]models.GroceryTemplateItem, 0)
+	for _, item := range items {
+		key := groceryutils.NormalizeItemDesc(item.ItemDesc)
+		if onList[key] {
+			skipped = append(skipped, item)
+			continue
+		}
+		onList[key] = true
+		entry := item.ToGroceryEntry()
+		entry.GuildID = ownerGuildID
+		if appliedByID != "" {
+			entry.UpdatedByID = &appliedByID
+		}
+		toAdd = append(toAdd, entry)
+	}
+	if len(toAdd) == 0 {
+		return toAdd, skipped, nil
+	}
+
+	registrationContext, err := registration.Service.GetRegistrationContext(ownerGuildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	limitOk, limit, err := grocery.Service.ValidateGroceryEntryLimit(ctx, registrationContext, ownerGuildID, len(toAdd))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !limitOk {
+		return nil, nil, &GroceryEntryLimitError{Limit: limit}
+	}
+	if err := grocery.Service.AssignCategories(ctx, ownerGuildID, toAdd); err != nil {
+		return nil, nil, err
+	}
+	if rErr := s.groceryEntryRepo.WithContext(ctx).AddToGroceryList(groceryList, toAdd, ownerGuildID); rErr != nil {
+		return nil, nil, rErr
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeAdd, toAdd, appliedByID))
+	if err := grocery.Service.OnGroceryListEdit(ctx, groceryList, ownerGuildID); err != nil {
+		return nil, nil, err
+	}
+	return toAdd, skipped, nil
+}
+
+func (s *TemplatesServiceImpl) GetTemplates(ctx context.Context, guildID string) ([]models.GroceryTemplate, error) {
+	return s.templateRepo.FindByGuildID(ctx, guildID)
+}
+
+func (s *TemplatesServiceImpl) GetTemplate(ctx context.Context, guildID string, name string) (*models.GroceryTemplate, error) {
+	return s.templateRepo.GetByName(ctx, guildID, models.NormaliseTemplateName(name))
+}
+
+func (s *TemplatesServiceImpl) GetTemplateByID(ctx context.Context, guildID string, id uint) (*models.GroceryTemplate, error) {
+	return s.templateRepo.GetByID(ctx, guildID, id)
+}
+
+func (s *TemplatesServiceImpl) DeleteTemplate(ctx context.Context, template *models.GroceryTemplate) error {
+	return s.templateRepo.Delete(ctx, template)
+}
+
+// findGroceryEntries returns what's on a grocery list (nil for the default list) that guildID can see.
+func (s *TemplatesServiceImpl) findGroceryEntries(ctx context.Context, guildID string, groceryList *models.GroceryList) ([]models.GroceryEntry, error) {
+	return s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(&models.GroceryEntry{
+		GuildID:       groceryList.GetGuildID(guildID),
+		GroceryListID: groceryList.GetID(),
+	}, repositories.GroceryEntryQueryOpts{
+		IsStrongNilForGroceryListID: true,
+	})
+}
@@ -0,0 +1,64 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// how many destructive operations are kept per guild
+	undoJournalSize = 5
+	// how long a destructive operation can be undone for
+	UndoWindow = 24 * time.Hour
+	// how often expired journal entries are deleted
+	pruneInterval = 10 * time.Minute
+)
+
+var (
+	Service UndoService
+
+	ErrNothingToUndo = errors.New("nothing to undo")
+)
+
+// GroceryEntryLimitError is returned when undoing would take the guild over its grocery entry limit (e.g. items have been added since
+// the list was cleared). The journal entry is kept, so that it can still be undone once some room has been made.
+type GroceryEntryLimitError struct {
+	Limit int
+}
+
+func (e *GroceryEntryLimitError) Error() string {
+	return fmt.Sprintf("undoing would go over the limit of %d grocery entries", e.Limit)
+}
+
+type UndoService interface {
+	// Record journals what the groceries looked like before a destructive operation. Returns the ID of the journal entry.
+	Record(ctx context.Context, guildID string, authorID string, operation string, groceryList *models.GroceryList, snapshot *models.UndoSnapshot) (uint, error)
+	// Undo restores the snapshot of a journal entry (or the guild's latest one if journalID is nil).
+	// Returns a *GroceryEntryLimitError if there isn't enough room left for the groceries that it would bring back.
+	Undo(ctx context.Context, guildID string, journalID *uint, undoneByID string) (operation string, restored []models.GroceryEntry, err error)
+}
+
+type UndoServiceImpl struct {
+	undoJournalRepo  repositories.UndoJournalRepository
+	groceryEntryRepo repositories.GroceryEntryRepository
+	logger           *zap.Logger
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		s := &UndoServiceImpl{
+			undoJournalRepo:  &repositories.UndoJournalRepositoryImpl{DB: db},
+			groceryEntryRepo: &repositories.GroceryEntryRepositoryImpl{DB: db},
+			logger:           logger.Named("undo"),
+		}
+		go s.pruneExpired()
+		Service = s
+	}
+}
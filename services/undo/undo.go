@@ -0,0 +1,118 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"github.com/verzac/grocer-discord-bot/services/grocery"
+	"github.com/verzac/grocer-discord-bot/services/registration"
+	"go.uber.org/zap"
+)
+
+func (s *UndoServiceImpl) Record(ctx context.Context, guildID string, authorID string, operation string, groceryList *models.GroceryList, snapshot *models.UndoSnapshot) (uint, error) {
+	e := &models.UndoJournalEntry{
+		GuildID:       guildID,
+		Operation:     operation,
+		GroceryListID: groceryList.GetID(),
+	}
+	if authorID != "" {
+		e.CreatedByID = &authorID
+	}
+	if err := e.SetSnapshot(snapshot); err != nil {
+		return 0, err
+	}
+	if err := s.undoJournalRepo.Create(ctx, e, undoJournalSize); err != nil {
+		return 0, err
+	}
+	return e.ID, nil
+}
+
+func (s *UndoServiceImpl) Undo(ctx context.Context, guildID string, journalID *uint, undoneByID string) (operation string, restored []models.GroceryEntry, err error) {
+	since := time.Now().Add(-UndoWindow)
+	var e *models.UndoJournalEntry
+	if journalID != nil {
+		e, err = s.undoJournalRepo.GetByID(ctx, guildID, *journalID, since)
+	} else {
+		e, err = s.undoJournalRepo.GetLatest(ctx, guildID, since)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if e == nil {
+		return "", nil, ErrNothingToUndo
+	}
+	if err := s.validateGroceryEntryLimit(ctx, e); err != nil {
+		return "", nil, err
+	}
+	restored, err = s.undoJournalRepo.Restore(ctx, e)
+	if errors.Is(err, repositories.ErrUndoJournalEntryNotFound) {
+		// someone else has undone it in the meantime
+		return "", nil, ErrNothingToUndo
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	grocery.Service.OnGroceryEntriesChanged(ctx, models.NewGroceryEntryChanges(models.GroceryEntryChangeRestore, restored, undoneByID))
+	if err := grocery.Service.OnGroceryEntriesEdit(ctx, guildID, restored); err != nil {
+		// the groceries have been restored anyway
+		s.logger.Error("Failed to run OnGroceryEntriesEdit after undo.", zap.Error(err))
+	}
+	return e.Operation, restored, nil
+}
+
+// validateGroceryEntryLimit checks that the guild has room for the groceries that the journal entry would bring back.
+func (s *UndoServiceImpl) validateGroceryEntryLimit(ctx context.Context, e *models.UndoJournalEntry) error {
+	snapshot, err := e.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	count, err := s.groceryEntryRepo.WithContext(ctx).GetCount(&models.GroceryEntry{GuildID: e.GuildID})
+	if err != nil {
+		return err
+	}
+	total := int(count) + len(snapshot.GroceryEntries)
+	if e.Operation == models.UndoOperationReplace {
+		// what's on the list now gets deleted first
+		replaced, err := s.groceryEntryRepo.WithContext(ctx).FindByQueryWithConfig(&models.GroceryEntry{
+			GuildID:       e.GuildID,
+			GroceryListID: e.GroceryListID,
+		}, repositories.GroceryEntryQueryOpts{
+			IsStrongNilForGroceryListID: true,
+		})
+		if err != nil {
+			return err
+		}
+		total -= len(replaced)
+	}
+	registrationContext, err := registration.Service.GetRegistrationContext(e.GuildID)
+	if err != nil {
+		return err
+	}
+	limitOk, limit, err := grocery.Service.ValidateGroceryEntryLimitUsingTotalCount(ctx, registrationContext, e.GuildID, total)
+	if err != nil {
+		return err
+	}
+	if !limitOk {
+		return &GroceryEntryLimitError{Limit: limit}
+	}
+	return nil
+}
+
+// pruneExpired deletes journal entries that can no longer be undone, so that we don't keep deleted groceries around for longer than we need to
+func (s *UndoServiceImpl) pruneExpired() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		rowsAffected, err := s.undoJournalRepo.DeleteCreatedBefore(ctx, time.Now().Add(-UndoWindow))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to prune expired undo journal entries.", zap.Error(err))
+			continue
+		}
+		s.logger.Debug("Pruned expired undo journal entries.", zap.Int64("rowsAffected", rowsAffected))
+	}
+}
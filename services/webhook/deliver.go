@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/verzac/grocer-discord-bot/auth"
+	"github.com/verzac/grocer-discord-bot/config"
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/utils"
+	"go.uber.org/zap"
+)
+
+// keeps the delivery log from filling up with huge error messages
+const maxDeliveryErrorLength = 500
+
+var errPrivateNetwork = errors.New("webhooks cannot be delivered to private network addresses")
+
+type delivery struct {
+	subscription models.WebhookSubscription
+	eventID      string
+	eventType    string
+	body         []byte
+	attempt      int
+}
+
+func (s *WebhookServiceImpl) Publish(ctx context.Context, event dto.GroceryEvent) {
+	subscriptions, err := s.webhookRepo.FindByGuildID(ctx, event.GuildID)
+	if err != nil {
+		s.logger.Error("Failed to look up webhook subscriptions.", zap.Error(err), zap.String("GuildID", event.GuildID))
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to marshal webhook event.", zap.Error(err), zap.String("EventType", event.Type))
+		return
+	}
+	for _, subscription := range subscriptions {
+		s.enqueue(&delivery{
+			subscription: subscription,
+			eventID:      event.ID,
+			eventType:    event.Type,
+			body:         body,
+			attempt:      1,
+		})
+	}
+}
+
+func (s *WebhookServiceImpl) enqueue(d *delivery) {
+	select {
+	case s.deliveries <- d:
+	default:
+		s.logger.Error("Webhook delivery queue is full: dropping delivery.", zap.String("EventID", d.eventID), zap.Uint("WebhookSubscriptionID", d.subscription.ID))
+	}
+}
+
+func (s *WebhookServiceImpl) deliveryWorker() {
+	for d := range s.deliveries {
+		s.attempt(d)
+	}
+}
+
+func (s *WebhookServiceImpl) attempt(d *delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	if d.attempt > 1 {
+		// the webhook might have been removed while we were waiting to retry
+		subscription, err := s.webhookRepo.GetByID(ctx, d.subscription.GuildID, d.subscription.ID)
+		if err != nil {
+			s.logger.Error("Failed to look up webhook subscription for retry.", zap.Error(err))
+		}
+		if subscription == nil {
+			return
+		}
+		d.subscription = *subscription
+	}
+
+	statusCode, err := s.send(ctx, d)
+	succeeded := err == nil && statusCode >= 200 && statusCode < 300
+	record := &models.WebhookDelivery{
+		GuildID:               d.subscription.GuildID,
+		WebhookSubscriptionID: d.subscription.ID,
+		EventID:               d.eventID,
+		EventType:             d.eventType,
+		Attempt:               d.attempt,
+		Succeeded:             succeeded,
+	}
+	if statusCode != 0 {
+		record.StatusCode = &statusCode
+	}
+	if err != nil {
+		errStr := err.Error()
+		if len(errStr) > maxDeliveryErrorLength {
+			errStr = errStr[:maxDeliveryErrorLength]
+		}
+		record.Error = &errStr
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, record); err != nil {
+		s.logger.Error("Failed to log webhook delivery.", zap.Error(err))
+	}
+
+	if succeeded || !isRetryable(statusCode, err) || d.attempt >= maxDeliveryAttempts {
+		return
+	}
+	next := *d
+	next.attempt++
+	time.AfterFunc(retryDelay(d.attempt), func() {
+		s.enqueue(&next)
+	})
+}
+
+func (s *WebhookServiceImpl) send(ctx context.Context, d *delivery) (statusCode int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.subscription.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GroceryBot-Webhook")
+	req.Header.Set(HeaderEvent, d.eventType)
+	req.Header.Set(HeaderDelivery, d.eventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, auth.SignWebhookPayload(d.subscription.Secret, timestamp, d.body))
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// isRetryable returns false for responses that won't change by retrying, e.g. 404s
+func isRetryable(statusCode int, err error) bool {
+	if statusCode == 0 {
+		return !errors.Is(err, errPrivateNetwork)
+	}
+	return statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func retryDelay(attempt int) time.Duration {
+	return retryBaseDelay << (attempt - 1)
+}
+
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout}
+	if !config.IsWebhookPrivateNetworkAllowed() {
+		dialer.Control = utils.DenyPrivateNetwork(errPrivateNetwork)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		// redirects are treated as failures, so that they can't be used to get around the private network check either
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// pruneExpired deletes old delivery logs
+func (s *WebhookServiceImpl) pruneExpired() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		rowsAffected, err := s.webhookRepo.DeleteDeliveriesCreatedBefore(ctx, time.Now().Add(-DeliveryRetention))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to prune expired webhook deliveries.", zap.Error(err))
+			continue
+		}
+		s.logger.Debug("Pruned expired webhook deliveries.", zap.Int64("rowsAffected", rowsAffected))
+	}
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/dto"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/repositories"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxWebhooksPerGuild = 5
+	// how many times an event is sent before giving up on it
+	maxDeliveryAttempts = 5
+	// the delay before the first retry, which doubles with every retry after (i.e. 10s, 20s, 40s, 80s)
+	retryBaseDelay       = 10 * time.Second
+	deliveryTimeout      = 10 * time.Second
+	deliveryWorkers      = 4
+	deliveryQueueSize    = 1000
+	DeliveryRetention    = 7 * 24 * time.Hour
+	pruneInterval        = time.Hour
+	DefaultDeliveryLimit = 20
+	MaxDeliveryLimit     = 100
+)
+
+const (
+	HeaderEvent     = "X-GroceryBot-Event"
+	HeaderDelivery  = "X-GroceryBot-Delivery"
+	HeaderTimestamp = "X-GroceryBot-Timestamp"
+	HeaderSignature = "X-GroceryBot-Signature"
+)
+
+var (
+	Service WebhookService
+
+	ErrInvalidURL   = errors.New("The webhook URL must be a full http:// or https:// URL, e.g. https://example.com/grocerybot.")
+	ErrLimitReached = fmt.Errorf("You can only have up to %d webhooks per server - remove one first with `/developer webhook-remove`.", MaxWebhooksPerGuild)
+)
+
+type WebhookService interface {
+	// Subscribe creates a webhook subscription for the guild. The secret is only ever returned here.
+	Subscribe(ctx context.Context, guildID string, url string, createdByID string) (subscription *models.WebhookSubscription, secret string, err error)
+	Unsubscribe(ctx context.Context, guildID string, id uint) error
+	GetSubscriptions(ctx context.Context, guildID string) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, guildID string, id uint) (*models.WebhookSubscription, error)
+	GetDeliveries(ctx context.Context, guildID string, id uint, limit int) ([]models.WebhookDelivery, error)
+	// Publish sends the event to all of the guild's webhook subscriptions in the background. ID & CreatedAt are filled in if they're empty.
+	// Failing to publish shouldn't fail the change itself, so errors are only logged.
+	Publish(ctx context.Context, event dto.GroceryEvent)
+}
+
+type WebhookServiceImpl struct {
+	webhookRepo repositories.WebhookRepository
+	logger      *zap.Logger
+	client      *http.Client
+	deliveries  chan *delivery
+}
+
+func Init(db *gorm.DB, logger *zap.Logger) {
+	if Service == nil {
+		s := &WebhookServiceImpl{
+			webhookRepo: &repositories.WebhookRepositoryImpl{DB: db},
+			logger:      logger.Named("webhook"),
+			client:      newDeliveryClient(),
+			deliveries:  make(chan *delivery, deliveryQueueSize),
+		}
+		for i := 0; i < deliveryWorkers; i++ {
+			go s.deliveryWorker()
+		}
+		go s.pruneExpired()
+		Service = s
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/auth"
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func (s *WebhookServiceImpl) Subscribe(ctx context.Context, guildID string, rawURL string, createdByID string) (*models.WebhookSubscription, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, "", ErrInvalidURL
+	}
+	existing, err := s.webhookRepo.FindByGuildID(ctx, guildID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= MaxWebhooksPerGuild {
+		return nil, "", ErrLimitReached
+	}
+	secret, err := auth.GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	subscription := &models.WebhookSubscription{
+		GuildID: guildID,
+		URL:     u.String(),
+		Secret:  secret,
+	}
+	if createdByID != "" {
+		subscription.CreatedByID = &createdByID
+	}
+	if err := s.webhookRepo.Create(ctx, subscription); err != nil {
+		return nil, "", err
+	}
+	return subscription, secret, nil
+}
+
+func (s *WebhookServiceImpl) Unsubscribe(ctx context.Context, guildID string, id uint) error {
+	return s.webhookRepo.Delete(ctx, guildID, id)
+}
+
+func (s *WebhookServiceImpl) GetSubscriptions(ctx context.Context, guildID string) ([]models.WebhookSubscription, error) {
+	return s.webhookRepo.FindByGuildID(ctx, guildID)
+}
+
+func (s *WebhookServiceImpl) GetSubscription(ctx context.Context, guildID string, id uint) (*models.WebhookSubscription, error) {
+	return s.webhookRepo.GetByID(ctx, guildID, id)
+}
+
+func (s *WebhookServiceImpl) GetDeliveries(ctx context.Context, guildID string, id uint, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit > MaxDeliveryLimit {
+		limit = MaxDeliveryLimit
+	}
+	return s.webhookRepo.FindDeliveries(ctx, guildID, id, limit)
+}
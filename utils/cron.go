@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// cronSearchLimit stops CronSchedule.Next from looking forever for a schedule that never runs, e.g. "0 0 31 2 *"
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronSchedule is a standard 5-field cron expression (minute, hour, day of month, month, day of week).
+// Fields take *, numbers, ranges (1-5), lists (1,3,5) and steps (*/15, 1-10/2). Sunday is both 0 and 7.
+type CronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+	// like cron, if both the day of month & the day of week are restricted, either of them can match
+	isDayOfMonthStar, isDayOfWeekStar bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = []cronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week
+}
+
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, ErrInvalidCron
+	}
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &CronSchedule{
+		minutes:          bits[0],
+		hours:            bits[1],
+		daysOfMonth:      bits[2],
+		months:           bits[3],
+		daysOfWeek:       bits[4],
+		isDayOfMonthStar: strings.HasPrefix(fields[2], "*"),
+		isDayOfWeekStar:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeStr, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, ErrInvalidCron
+			}
+			step = n
+		}
+		start, end := f.min, f.max
+		if rangeStr != "*" {
+			startStr, endStr, isRange := strings.Cut(rangeStr, "-")
+			n, err := strconv.Atoi(startStr)
+			if err != nil {
+				return 0, ErrInvalidCron
+			}
+			start, end = n, n
+			if isRange {
+				if end, err = strconv.Atoi(endStr); err != nil {
+					return 0, ErrInvalidCron
+				}
+			} else if hasStep {
+				// e.g. 5/15 means every 15 starting from 5
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, ErrInvalidCron
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+// RunsAtMostHourly reports whether the schedule only runs at one minute of the hour, so its runs are at least an hour apart.
+func (s *CronSchedule) RunsAtMostHourly() bool {
+	return bits.OnesCount64(s.minutes) == 1
+}
+
+// Next returns the first time after t (to the minute) that the schedule runs at, in t's location.
+// Returns the zero time if the schedule never runs.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		if s.months&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hours&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minutes&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.daysOfMonth&(1<<t.Day()) != 0
+	dayOfWeek := s.daysOfWeek&(1<<int(t.Weekday())) != 0
+	if s.isDayOfMonthStar || s.isDayOfWeekStar {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// CronExpression returns the cron expression for a time of day on certain weekdays (every day if there are none),
+// e.g. "30 9 * * 6" for 9:30am every Saturday.
+func CronExpression(minute int, hour int, weekdays []time.Weekday) string {
+	dayOfWeek := "*"
+	if len(weekdays) > 0 {
+		tokens := make([]string, len(weekdays))
+		for i, w := range weekdays {
+			tokens[i] = strconv.Itoa(int(w))
+		}
+		dayOfWeek = strings.Join(tokens, ",")
+	}
+	return fmt.Sprintf("%d %d * * %s", minute, hour, dayOfWeek)
+}
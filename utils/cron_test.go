@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	sydney := time.FixedZone("AEDT", 11*60*60)
+	// a Sunday
+	from := time.Date(2026, 10, 18, 10, 15, 30, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", from, time.Date(2026, 10, 18, 10, 16, 0, 0, time.UTC)},
+		{"later today", "30 18 * * *", from, time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)},
+		{"tomorrow", "0 9 * * *", from, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"saturday", "0 9 * * 6", from, time.Date(2026, 10, 24, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 12 * * 7", from, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		{"weekdays", "0 17 * * 1-5", from, time.Date(2026, 10, 19, 17, 0, 0, 0, time.UTC)},
+		{"steps", "*/20 * * * *", from, time.Date(2026, 10, 18, 10, 20, 0, 0, time.UTC)},
+		{"first of the month", "0 0 1 * *", from, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"day of month or weekday", "0 0 1 * 1", from, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"next year", "0 0 1 1 *", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"in another timezone", "0 9 * * 6", from.In(sydney), time.Date(2026, 10, 24, 9, 0, 0, 0, sydney)},
+		{"never", "0 0 31 2 *", from, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestCronExpression(t *testing.T) {
+	if got := CronExpression(30, 9, []time.Weekday{time.Saturday}); got != "30 9 * * 6" {
+		t.Errorf("got %q", got)
+	}
+	if got := CronExpression(0, 18, nil); got != "0 18 * * *" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCronScheduleRunsAtMostHourly(t *testing.T) {
+	for expr, want := range map[string]bool{
+		"0 9 * * 6":    true,
+		"30 * * * *":   true,
+		"0 9,10 * * *": true,
+		"* * * * *":    false,
+		"*/30 * * * *": false,
+		"0,1 9 1 1 *":  false,
+	} {
+		s, err := ParseCron(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.RunsAtMostHourly(); got != want {
+			t.Errorf("RunsAtMostHourly(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
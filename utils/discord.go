@@ -1,6 +1,10 @@
 package utils
 
-import "unicode/utf8"
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
 
 // DiscordCheckboxOptionLabelMaxBytes is the UTF-8 byte limit for checkbox group option labels
 // (enforced by the API in practice; keep labels ≤ 100 UTF-8 bytes).
@@ -24,3 +28,28 @@ func TruncateStringToMaxUTF8Bytes(s string, maxBytes int) string {
 	}
 	return s[:i]
 }
+
+// DiscordMessageMaxLength is the most characters that a message's content can have.
+const DiscordMessageMaxLength = 2000
+
+var regexMention = regexp.MustCompile(`^<@([!&]?)(\d+)>$`)
+
+// ParseMentions splits s into the IDs of the users (<@id> or <@!id>) and roles (<@&id>) that it mentions.
+// Anything else in s is returned in unrecognised.
+func ParseMentions(s string) (userIDs []string, roleIDs []string, unrecognised []string) {
+	tokens := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\n'
+	})
+	for _, token := range tokens {
+		match := regexMention.FindStringSubmatch(token)
+		switch {
+		case match == nil:
+			unrecognised = append(unrecognised, token)
+		case match[1] == "&":
+			roleIDs = append(roleIDs, match[2])
+		default:
+			userIDs = append(userIDs, match[2])
+		}
+	}
+	return userIDs, roleIDs, unrecognised
+}
@@ -37,3 +37,16 @@ func TestTruncateStringToMaxUTF8Bytes(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMentions(t *testing.T) {
+	userIDs, roleIDs, unrecognised := ParseMentions("<@123> <@!456>,<@&789>  bob")
+	if strings.Join(userIDs, ",") != "123,456" {
+		t.Errorf("userIDs = %v", userIDs)
+	}
+	if strings.Join(roleIDs, ",") != "789" {
+		t.Errorf("roleIDs = %v", roleIDs)
+	}
+	if strings.Join(unrecognised, ",") != "bob" {
+		t.Errorf("unrecognised = %v", unrecognised)
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "strings"
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// IfMatch returns true if the If-Match header matches etag. An empty header means that the client doesn't care which version
+// it is changing, so it always matches (and so does "*"). If-Match uses the strong comparison (RFC 9110), so weak tags (W/"3") never match.
+func IfMatch(ifMatch string, etag string) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
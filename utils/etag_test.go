@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		etag    string
+		want    bool
+	}{
+		{"no header", "", `"3"`, true},
+		{"wildcard", "*", `"3"`, true},
+		{"match", `"3"`, `"3"`, true},
+		{"stale", `"2"`, `"3"`, false},
+		{"weak", `W/"3"`, `"3"`, false},
+		{"weak and strong", `W/"2", "3"`, `"3"`, true},
+		{"one of many", `"1", "3"`, `"3"`, true},
+		{"unquoted", "3", `"3"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IfMatch(tt.ifMatch, tt.etag); got != tt.want {
+				t.Errorf("IfMatch(%q, %q) = %v, want %v", tt.ifMatch, tt.etag, got, tt.want)
+			}
+		})
+	}
+}
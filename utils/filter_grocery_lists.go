@@ -0,0 +1,32 @@
+package utils
+
+import "github.com/verzac/grocer-discord-bot/models"
+
+// FilterGroceryLists keeps the grocery lists that keep returns true for, and drops the groceries of the ones that it doesn't.
+// Groceries that aren't on any of groceryLists (e.g. the default list's) are kept as-is.
+func FilterGroceryLists(
+	groceryLists []models.GroceryList,
+	groceries []models.GroceryEntry,
+	keep func(gl *models.GroceryList) bool,
+) ([]models.GroceryList, []models.GroceryEntry) {
+	keptLists := make([]models.GroceryList, 0, len(groceryLists))
+	droppedListIDs := make(map[uint]bool)
+	for i := range groceryLists {
+		if keep(&groceryLists[i]) {
+			keptLists = append(keptLists, groceryLists[i])
+		} else {
+			droppedListIDs[groceryLists[i].ID] = true
+		}
+	}
+	if len(droppedListIDs) == 0 {
+		return keptLists, groceries
+	}
+	keptGroceries := make([]models.GroceryEntry, 0, len(groceries))
+	for _, g := range groceries {
+		if g.GroceryListID != nil && droppedListIDs[*g.GroceryListID] {
+			continue
+		}
+		keptGroceries = append(keptGroceries, g)
+	}
+	return keptLists, keptGroceries
+}
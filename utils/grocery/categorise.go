@@ -0,0 +1,167 @@
+package groceryutils
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+const (
+	CategoryProduce      = "Produce"
+	CategoryDairy        = "Dairy & Eggs"
+	CategoryMeat         = "Meat & Seafood"
+	CategoryBakery       = "Bakery"
+	CategoryFrozen       = "Frozen"
+	CategoryPantry       = "Pantry"
+	CategoryDrinks       = "Drinks"
+	CategorySnacks       = "Snacks"
+	CategoryHousehold    = "Household"
+	CategoryPersonalCare = "Personal Care"
+	// CategoryOther is only used for display - uncategorised entries have a nil category
+	CategoryOther = "Other"
+)
+
+// BuiltInCategories is in shop-walk order, which is also the order that categories are displayed in
+var BuiltInCategories = []string{
+	CategoryProduce,
+	CategoryBakery,
+	CategoryMeat,
+	CategoryDairy,
+	CategoryFrozen,
+	CategoryPantry,
+	CategorySnacks,
+	CategoryDrinks,
+	CategoryHousehold,
+	CategoryPersonalCare,
+}
+
+var builtInCategoryKeywords = map[string][]string{
+	CategoryProduce: {
+		"apple", "banana", "orange", "lemon", "lime", "grape", "berry", "strawberry", "blueberry", "raspberry", "pear", "peach",
+		"mango", "pineapple", "melon", "watermelon", "kiwi", "avocado", "tomato", "potato", "sweet potato", "onion", "spring onion",
+		"garlic", "ginger", "carrot", "lettuce", "spinach", "kale", "broccoli", "cauliflower", "cabbage", "cucumber", "zucchini",
+		"capsicum", "bell pepper", "chilli", "mushroom", "celery", "corn", "pea", "bean sprout", "herb", "basil", "coriander",
+		"cilantro", "parsley", "mint", "fruit", "vegetable", "veggie", "salad",
+	},
+	CategoryDairy: {
+		"milk", "cheese", "butter", "yogurt", "yoghurt", "cream", "sour cream", "cream cheese", "egg", "mozzarella", "parmesan",
+		"cheddar", "feta",
+	},
+	CategoryMeat: {
+		"chicken", "beef", "pork", "lamb", "turkey", "bacon", "ham", "sausage", "mince", "steak", "meat", "fish", "salmon",
+		"shrimp", "prawn",
+	},
+	CategoryBakery: {
+		"bread", "bagel", "bun", "croissant", "tortilla", "baguette", "muffin", "cake", "pita", "wrap",
+	},
+	CategoryFrozen: {
+		"frozen", "ice cream", "ice", "frozen pizza", "fish finger",
+	},
+	CategoryPantry: {
+		"rice", "pasta", "spaghetti", "noodle", "flour", "sugar", "salt", "pepper", "oil", "olive oil", "vinegar", "sauce",
+		"soy sauce", "ketchup", "mayo", "mayonnaise", "mustard", "spice", "cereal", "oat", "bean", "lentil", "chickpea", "soup",
+		"honey", "jam", "peanut butter", "stock", "broth", "yeast", "baking powder", "baking soda", "tomato paste", "canned",
+		"tuna", "coconut milk",
+	},
+	CategoryDrinks: {
+		"water", "juice", "soda", "coffee", "tea", "beer", "wine", "coke", "lemonade", "kombucha", "sparkling water",
+	},
+	CategorySnacks: {
+		"chip", "crisp", "chocolate", "cookie", "biscuit", "cracker", "popcorn", "candy", "nut", "pretzel", "snack",
+	},
+	CategoryHousehold: {
+		"toilet paper", "paper towel", "detergent", "dish soap", "dishwashing liquid", "laundry", "trash bag", "bin bag",
+		"sponge", "bleach", "foil", "cling wrap", "battery", "light bulb", "tissue",
+	},
+	CategoryPersonalCare: {
+		"shampoo", "conditioner", "toothpaste", "toothbrush", "deodorant", "soap", "liquid soap", "razor", "lotion",
+		"sunscreen", "floss",
+	},
+}
+
+// normalized keyword -> category, built from builtInCategoryKeywords
+var builtInKeywordToCategory = func() map[string]string {
+	out := make(map[string]string)
+	for category, keywords := range builtInCategoryKeywords {
+		for _, keyword := range keywords {
+			out[NormalizeItemDesc(keyword)] = category
+		}
+	}
+	return out
+}()
+
+// maxCategoryKeywordWords limits how long a keyword can be (in words)
+const maxCategoryKeywordWords = 3
+
+// Categorise returns the category of an item based on its description, or an empty string if it cannot be categorised.
+// Guild overrides take precedence over the built-in keywords. Longer keywords win (e.g. "peanut butter" over "butter"), and
+// keywords closer to the end of the description win otherwise (e.g. "chocolate milk" is dairy, "milk chocolate" is a snack).
+func Categorise(itemDesc string, overrides []models.GuildCategoryOverride) string {
+	words := strings.Fields(NormalizeItemDesc(itemDesc))
+	if len(words) == 0 {
+		return ""
+	}
+	if len(overrides) > 0 {
+		overrideKeywordToCategory := make(map[string]string, len(overrides))
+		for _, o := range overrides {
+			overrideKeywordToCategory[NormalizeItemDesc(o.Keyword)] = o.Category
+		}
+		if category := matchCategoryKeyword(words, overrideKeywordToCategory); category != "" {
+			return category
+		}
+	}
+	for _, w := range words {
+		if w == "frozen" {
+			// "frozen peas" should go into the frozen section, not produce
+			return CategoryFrozen
+		}
+	}
+	return matchCategoryKeyword(words, builtInKeywordToCategory)
+}
+
+func matchCategoryKeyword(words []string, keywordToCategory map[string]string) string {
+	for n := maxCategoryKeywordWords; n >= 1; n-- {
+		for start := len(words) - n; start >= 0; start-- {
+			if category, ok := keywordToCategory[strings.Join(words[start:start+n], " ")]; ok {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// CanonicalCategory matches the casing of a built-in category if possible (e.g. "produce" -> "Produce").
+func CanonicalCategory(category string) string {
+	category = strings.TrimSpace(category)
+	for _, c := range BuiltInCategories {
+		if strings.EqualFold(c, category) {
+			return c
+		}
+	}
+	return category
+}
+
+// sortCategories sorts categories in shop-walk order: built-in categories first, then custom categories alphabetically, then uncategorised entries.
+func sortCategories(categories []string) {
+	order := make(map[string]int, len(BuiltInCategories))
+	for i, c := range BuiltInCategories {
+		order[c] = i
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		ci, cj := categories[i], categories[j]
+		if (ci == "") != (cj == "") {
+			return cj == ""
+		}
+		oi, iIsBuiltIn := order[ci]
+		oj, jIsBuiltIn := order[cj]
+		switch {
+		case iIsBuiltIn && jIsBuiltIn:
+			return oi < oj
+		case iIsBuiltIn != jIsBuiltIn:
+			return iIsBuiltIn
+		default:
+			return strings.ToLower(ci) < strings.ToLower(cj)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package groceryutils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestCategorise(t *testing.T) {
+	cases := map[string]string{
+		"Bananas":           CategoryProduce,
+		"cherry tomatoes":   CategoryProduce,
+		"Eggs":              CategoryDairy,
+		"chocolate milk":    CategoryDairy,
+		"milk chocolate":    CategorySnacks,
+		"peanut butter":     CategoryPantry,
+		"frozen peas":       CategoryFrozen,
+		"Toilet Paper":      CategoryHousehold,
+		"sourdough bread":   CategoryBakery,
+		"something strange": "",
+		"":                  "",
+	}
+	for in, want := range cases {
+		if got := Categorise(in, nil); got != want {
+			t.Errorf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestCategorise_OverridesTakePrecedence(t *testing.T) {
+	overrides := []models.GuildCategoryOverride{
+		{Keyword: "oat milk", Category: "Vegan"},
+		{Keyword: "Kimchi", Category: "Asian Grocer"},
+	}
+	if got := Categorise("Oat Milk", overrides); got != "Vegan" {
+		t.Errorf("expected override to win, got %q", got)
+	}
+	if got := Categorise("kimchi", overrides); got != "Asian Grocer" {
+		t.Errorf("expected override category, got %q", got)
+	}
+	if got := Categorise("milk", overrides); got != CategoryDairy {
+		t.Errorf("expected built-in category, got %q", got)
+	}
+}
+
+func TestGetGroceryListText_GroupsByCategory(t *testing.T) {
+	produce, custom := CategoryProduce, "Asian Grocer"
+	groceries := []models.GroceryEntry{
+		{ItemDesc: "Mystery"},
+		{ItemDesc: "Kimchi", Category: &custom},
+		{ItemDesc: "Apple", Category: &produce},
+	}
+	got := GetGroceryListText(groceries, nil)
+	want := "*Produce*\n3: Apple\n*Asian Grocer*\n2: Kimchi\n*Other*\n1: Mystery\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	flat := GetGroceryListText([]models.GroceryEntry{{ItemDesc: "Mystery"}}, nil)
+	if strings.Contains(flat, "*") {
+		t.Fatalf("uncategorised lists should not be grouped, got %q", flat)
+	}
+}
@@ -11,9 +11,33 @@ func GetGroceryListText(groceries []models.GroceryEntry, groceryList *models.Gro
 		label := ":" + groceryList.ListLabel
 		return getNoGroceryText(label)
 	}
-	msg := ""
+	// entries keep their position in the list so that they can still be removed/checked by their number
+	lineByCategory := make(map[string]string)
+	categories := make([]string, 0)
 	for i, grocery := range groceries {
-		msg += fmt.Sprintf("%d: %s\n", i+1, grocery.ItemDesc)
+		line := fmt.Sprintf("%d: %s\n", i+1, grocery.GetDisplayText())
+		if grocery.IsChecked() {
+			// checked-off items stay on the list until they're swept
+			line = fmt.Sprintf("%d: ~~%s~~\n", i+1, grocery.GetDisplayText())
+		}
+		category := grocery.GetCategory()
+		if _, ok := lineByCategory[category]; !ok {
+			categories = append(categories, category)
+		}
+		lineByCategory[category] += line
+	}
+	if len(categories) == 1 && categories[0] == "" {
+		// nothing has been categorised, so there's no point in grouping
+		return lineByCategory[""]
+	}
+	sortCategories(categories)
+	msg := ""
+	for _, category := range categories {
+		header := category
+		if header == "" {
+			header = CategoryOther
+		}
+		msg += fmt.Sprintf("*%s*\n%s", header, lineByCategory[category])
 	}
 	return msg
 }
@@ -0,0 +1,109 @@
+package groceryutils
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+	"github.com/verzac/grocer-discord-bot/utils"
+)
+
+// custom IDs for the components on !grohere messages, suffixed with the message's scope (e.g. "grohere_check:all") - see GetGrohereScope
+const (
+	CustomIDGrohereCheck   = "grohere_check"
+	CustomIDGrohereAdd     = "grohere_add"
+	CustomIDGrohereSweep   = "grohere_sweep"
+	CustomIDGrohereRefresh = "grohere_refresh"
+
+	// GrohereScopeAll is the scope of !grohere all
+	GrohereScopeAll = "all"
+
+	grohereSelectMenuMaxOptions = 25
+	// a message can only have 5 rows, and one of them is used for the buttons
+	grohereSelectMenuMaxCount = 4
+)
+
+// GetGrohereScope returns the scope of a grocery list's !grohere message, which is the list's ID (0 for the default list).
+func GetGrohereScope(groceryList *models.GroceryList) string {
+	if groceryList == nil {
+		return "0"
+	}
+	return strconv.FormatUint(uint64(groceryList.ID), 10)
+}
+
+// GetGrohereComponents returns the components of a !grohere message: select menus to tick items off with, and buttons to add an item,
+// remove the items that have been ticked off, and refresh the message.
+// groceryLists is only used to label the items of !grohere all.
+func GetGrohereComponents(scope string, groceryLists []models.GroceryList, groceries []models.GroceryEntry) []discordgo.MessageComponent {
+	listNames := make(map[uint]string, len(groceryLists))
+	for i := range groceryLists {
+		listNames[groceryLists[i].ID] = groceryLists[i].GetName()
+	}
+	hasChecked := false
+	options := make([]discordgo.SelectMenuOption, 0, len(groceries))
+	for i, g := range groceries {
+		if g.IsChecked() {
+			hasChecked = true
+		}
+		if len(options) == grohereSelectMenuMaxOptions*grohereSelectMenuMaxCount {
+			continue
+		}
+		label := fmt.Sprintf("%d: %s", i+1, g.GetDisplayText())
+		description := ""
+		if scope == GrohereScopeAll {
+			label = g.GetDisplayText()
+			if g.GroceryListID != nil {
+				description = "On " + listNames[*g.GroceryListID]
+			}
+		}
+		if g.IsChecked() {
+			description = "Ticked off - pick it again to untick it"
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       utils.TruncateStringToMaxUTF8Bytes(label, utils.DiscordCheckboxOptionLabelMaxBytes),
+			Value:       strconv.FormatUint(uint64(g.ID), 10),
+			Description: utils.TruncateStringToMaxUTF8Bytes(description, utils.DiscordCheckboxOptionLabelMaxBytes),
+		})
+	}
+	components := make([]discordgo.MessageComponent, 0, grohereSelectMenuMaxCount+1)
+	for start := 0; start < len(options); start += grohereSelectMenuMaxOptions {
+		end := min(start+grohereSelectMenuMaxOptions, len(options))
+		placeholder := "Tick off items..."
+		if len(options) > grohereSelectMenuMaxOptions {
+			placeholder = fmt.Sprintf("Tick off items (%d-%d)...", start+1, end)
+		}
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType: discordgo.StringSelectMenu,
+					// custom IDs have to be unique within a message
+					CustomID:    fmt.Sprintf("%s:%s:%d", CustomIDGrohereCheck, scope, start/grohereSelectMenuMaxOptions),
+					Placeholder: placeholder,
+					MaxValues:   end - start,
+					Options:     options[start:end],
+				},
+			},
+		})
+	}
+	return append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Add item",
+				Style:    discordgo.PrimaryButton,
+				CustomID: CustomIDGrohereAdd + ":" + scope,
+			},
+			discordgo.Button{
+				Label:    "Remove ticked items",
+				Style:    discordgo.SecondaryButton,
+				CustomID: CustomIDGrohereSweep + ":" + scope,
+				Disabled: !hasChecked,
+			},
+			discordgo.Button{
+				Label:    "Refresh",
+				Style:    discordgo.SecondaryButton,
+				CustomID: CustomIDGrohereRefresh + ":" + scope,
+			},
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package groceryutils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func TestGetGrohereComponents(t *testing.T) {
+	groceries := make([]models.GroceryEntry, 30)
+	for i := range groceries {
+		groceries[i] = models.GroceryEntry{ID: uint(i + 100), ItemDesc: fmt.Sprintf("item %d", i)}
+	}
+	components := GetGrohereComponents("5", nil, groceries)
+	if len(components) != 3 {
+		t.Fatalf("expected 2 select menus & a row of buttons, got %d rows", len(components))
+	}
+	first := components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	second := components[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	if len(first.Options) != 25 || len(second.Options) != 5 {
+		t.Errorf("expected 25 & 5 options, got %d & %d", len(first.Options), len(second.Options))
+	}
+	if first.CustomID == second.CustomID {
+		t.Errorf("expected unique custom IDs, got %q twice", first.CustomID)
+	}
+	if first.CustomID != "grohere_check:5:0" || first.Options[0].Value != "100" || first.Options[0].Label != "1: item 0" {
+		t.Errorf("unexpected select menu: %+v", first)
+	}
+	sweep := components[2].(discordgo.ActionsRow).Components[1].(discordgo.Button)
+	if !sweep.Disabled {
+		t.Errorf("expected the sweep button to be disabled when nothing is ticked off")
+	}
+
+	now := time.Now()
+	groceries[0].CheckedAt = &now
+	components = GetGrohereComponents(GrohereScopeAll, nil, groceries[:1])
+	if len(components) != 2 {
+		t.Fatalf("expected a select menu & a row of buttons, got %d rows", len(components))
+	}
+	sweep = components[1].(discordgo.ActionsRow).Components[1].(discordgo.Button)
+	if sweep.Disabled || sweep.CustomID != "grohere_sweep:all" {
+		t.Errorf("unexpected sweep button: %+v", sweep)
+	}
+
+	components = GetGrohereComponents("0", nil, nil)
+	if len(components) != 1 {
+		t.Errorf("expected only the buttons for an empty list, got %d rows", len(components))
+	}
+}
@@ -0,0 +1,97 @@
+package groceryutils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+// NormalizeItemDesc returns a key that is the same for near-duplicate entries, e.g. "Eggs", " egg " and "EGGS".
+func NormalizeItemDesc(itemDesc string) string {
+	words := strings.FieldsFunc(strings.ToLower(itemDesc), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, w := range words {
+		// naive singularisation - good enough for groceries
+		switch {
+		case len(w) > 4 && strings.HasSuffix(w, "ies"):
+			words[i] = strings.TrimSuffix(w, "ies") + "y"
+		case len(w) > 3 && (strings.HasSuffix(w, "oes") || strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "xes")):
+			words[i] = strings.TrimSuffix(w, "es")
+		case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+			words[i] = strings.TrimSuffix(w, "s")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// MergeDuplicates lines up incoming entries against the existing entries of a grocery list (and against each other).
+// Duplicates are merged when their quantities can be summed up (same unit), or when neither has a quantity (nothing to merge).
+// Checked-off entries are never merged into, since they're about to be swept off the list along with whatever was merged into them.
+//
+// Returns the existing entries that were merged into (callers need to save these), the incoming entries that should be added as new entries,
+// and the incoming entries that are duplicates but cannot be merged (e.g. "Milk 1L" vs "Milk 2 bottles").
+func MergeDuplicates(existing []models.GroceryEntry, incoming []models.GroceryEntry) (merged []models.GroceryEntry, toAdd []models.GroceryEntry, unmergeable []models.GroceryEntry) {
+	existingByKey := make(map[string]int, len(existing))
+	mergedIdxByKey := make(map[string]int)
+	for i := range existing {
+		if existing[i].CheckedAt != nil {
+			continue
+		}
+		key := NormalizeItemDesc(existing[i].ItemDesc)
+		if _, ok := existingByKey[key]; !ok {
+			existingByKey[key] = i
+		}
+	}
+	toAddIdxByKey := make(map[string]int)
+	for _, entry := range incoming {
+		key := NormalizeItemDesc(entry.ItemDesc)
+		if idx, ok := mergedIdxByKey[key]; ok {
+			if mergeQuantity(&merged[idx], entry) {
+				continue
+			}
+			unmergeable = append(unmergeable, entry)
+			continue
+		}
+		if idx, ok := existingByKey[key]; ok {
+			target := existing[idx]
+			if mergeQuantity(&target, entry) {
+				target.UpdatedByID = entry.UpdatedByID
+				mergedIdxByKey[key] = len(merged)
+				merged = append(merged, target)
+				continue
+			}
+			unmergeable = append(unmergeable, entry)
+			continue
+		}
+		if idx, ok := toAddIdxByKey[key]; ok && mergeQuantity(&toAdd[idx], entry) {
+			continue
+		}
+		toAddIdxByKey[key] = len(toAdd)
+		toAdd = append(toAdd, entry)
+	}
+	return merged, toAdd, unmergeable
+}
+
+func mergeQuantity(target *models.GroceryEntry, entry models.GroceryEntry) bool {
+	if target.Quantity == nil && entry.Quantity == nil {
+		return true
+	}
+	if target.Quantity == nil || entry.Quantity == nil {
+		return false
+	}
+	targetUnit, entryUnit := "", ""
+	if target.Unit != nil {
+		targetUnit = *target.Unit
+	}
+	if entry.Unit != nil {
+		entryUnit = *entry.Unit
+	}
+	if targetUnit != entryUnit {
+		return false
+	}
+	sum := *target.Quantity + *entry.Quantity
+	target.Quantity = &sum
+	return true
+}
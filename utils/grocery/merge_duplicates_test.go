@@ -0,0 +1,90 @@
+package groceryutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/verzac/grocer-discord-bot/models"
+)
+
+func entryWithQuantity(itemDesc string, quantity float64, unit string) models.GroceryEntry {
+	g := models.GroceryEntry{ItemDesc: itemDesc, Quantity: &quantity}
+	if unit != "" {
+		g.Unit = &unit
+	}
+	return g
+}
+
+func TestNormalizeItemDesc(t *testing.T) {
+	cases := map[string]string{
+		"Eggs":             "egg",
+		"  egg ":           "egg",
+		"Cherry  Tomatoes": "cherry tomato",
+		"berries":          "berry",
+		"Peaches":          "peach",
+		"glass":            "glass",
+		"Chicken-katsu":    "chicken katsu",
+	}
+	for in, want := range cases {
+		if got := NormalizeItemDesc(in); got != want {
+			t.Errorf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestMergeDuplicates_SumsQuantitiesWithSameUnit(t *testing.T) {
+	existing := []models.GroceryEntry{entryWithQuantity("Milk", 1, "L"), {ItemDesc: "Bread"}}
+	merged, toAdd, unmergeable := MergeDuplicates(existing, []models.GroceryEntry{entryWithQuantity("milk", 2, "L")})
+	if len(merged) != 1 || *merged[0].Quantity != 3 || merged[0].ItemDesc != "Milk" {
+		t.Fatalf("unexpected merged: %+v", merged)
+	}
+	if len(toAdd) != 0 || len(unmergeable) != 0 {
+		t.Fatalf("expected nothing to add, got %+v / %+v", toAdd, unmergeable)
+	}
+	if *existing[0].Quantity != 1 {
+		t.Fatalf("existing entries should not be mutated")
+	}
+}
+
+func TestMergeDuplicates_NoQuantitiesAreDeduped(t *testing.T) {
+	merged, toAdd, unmergeable := MergeDuplicates(
+		[]models.GroceryEntry{{ItemDesc: "eggs"}},
+		[]models.GroceryEntry{{ItemDesc: "Egg"}, {ItemDesc: "Salt"}, {ItemDesc: "salt "}},
+	)
+	if len(merged) != 1 || len(toAdd) != 1 || toAdd[0].ItemDesc != "Salt" || len(unmergeable) != 0 {
+		t.Fatalf("unexpected result: %+v / %+v / %+v", merged, toAdd, unmergeable)
+	}
+}
+
+func TestMergeDuplicates_MismatchingUnitsAreUnmergeable(t *testing.T) {
+	merged, toAdd, unmergeable := MergeDuplicates(
+		[]models.GroceryEntry{entryWithQuantity("Milk", 1, "L"), {ItemDesc: "Eggs"}},
+		[]models.GroceryEntry{entryWithQuantity("Milk", 2, "bottle"), entryWithQuantity("Eggs", 12, "")},
+	)
+	if len(merged) != 0 || len(toAdd) != 0 || len(unmergeable) != 2 {
+		t.Fatalf("unexpected result: %+v / %+v / %+v", merged, toAdd, unmergeable)
+	}
+}
+
+func TestMergeDuplicates_MergesWithinIncoming(t *testing.T) {
+	_, toAdd, _ := MergeDuplicates(nil, []models.GroceryEntry{entryWithQuantity("Apple", 2, ""), entryWithQuantity("apples", 3, "")})
+	if len(toAdd) != 1 || *toAdd[0].Quantity != 5 {
+		t.Fatalf("unexpected toAdd: %+v", toAdd)
+	}
+}
+
+func TestMergeDuplicates_SkipsCheckedEntries(t *testing.T) {
+	checkedAt := time.Now()
+	checked := entryWithQuantity("Milk", 1, "L")
+	checked.CheckedAt = &checkedAt
+	merged, toAdd, unmergeable := MergeDuplicates(
+		[]models.GroceryEntry{checked, {ItemDesc: "Eggs", CheckedAt: &checkedAt}},
+		[]models.GroceryEntry{entryWithQuantity("milk", 2, "L"), {ItemDesc: "eggs"}},
+	)
+	if len(merged) != 0 || len(unmergeable) != 0 {
+		t.Fatalf("expected nothing to be merged into checked entries, got %+v / %+v", merged, unmergeable)
+	}
+	if len(toAdd) != 2 || *toAdd[0].Quantity != 2 || toAdd[1].ItemDesc != "eggs" {
+		t.Fatalf("unexpected toAdd: %+v", toAdd)
+	}
+}
@@ -0,0 +1,39 @@
+package groceryutils
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/verzac/grocer-discord-bot/utils"
+)
+
+// PaginateText splits text into pages of at most maxLength characters, breaking between lines wherever possible.
+// Lines that are longer than a page by themselves are truncated. There's always at least one page.
+func PaginateText(text string, maxLength int) []string {
+	text = strings.TrimRight(text, "\n")
+	if utf8.RuneCountInString(text) <= maxLength {
+		return []string{text}
+	}
+	pages := make([]string, 0)
+	page := ""
+	pageLength := 0
+	for _, line := range strings.Split(text, "\n") {
+		lineLength := utf8.RuneCountInString(line)
+		if lineLength > maxLength {
+			line = utils.TruncateStringWithTargetLength(line, maxLength)
+			lineLength = utf8.RuneCountInString(line)
+		}
+		if pageLength > 0 && pageLength+1+lineLength > maxLength {
+			pages = append(pages, page)
+			page = ""
+			pageLength = 0
+		}
+		if pageLength > 0 {
+			page += "\n"
+			pageLength++
+		}
+		page += line
+		pageLength += lineLength
+	}
+	return append(pages, page)
+}
@@ -0,0 +1,27 @@
+package groceryutils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPaginateText(t *testing.T) {
+	if pages := PaginateText("1: milk\n2: eggs\n", 2000); len(pages) != 1 || pages[0] != "1: milk\n2: eggs" {
+		t.Errorf("PaginateText() short text = %q", pages)
+	}
+	pages := PaginateText("aaaa\nbbbb\ncccc\ndd", 10)
+	want := []string{"aaaa\nbbbb", "cccc\ndd"}
+	if strings.Join(pages, "|") != strings.Join(want, "|") {
+		t.Errorf("PaginateText() = %q, want %q", pages, want)
+	}
+	pages = PaginateText("short\n"+strings.Repeat("ü", 30)+"\nend", 10)
+	for _, page := range pages {
+		if utf8.RuneCountInString(page) > 10 {
+			t.Errorf("PaginateText() page %q is longer than 10 characters", page)
+		}
+	}
+	if len(pages) != 3 || pages[0] != "short" || pages[2] != "end" {
+		t.Errorf("PaginateText() long line = %q", pages)
+	}
+}
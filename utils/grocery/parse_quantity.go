@@ -0,0 +1,167 @@
+package groceryutils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maps the unit aliases that users type in to the unit that we store
+var unitAliases = map[string]string{
+	"mg":      "mg",
+	"g":       "g",
+	"gr":      "g",
+	"gram":    "g",
+	"grams":   "g",
+	"kg":      "kg",
+	"kgs":     "kg",
+	"kilo":    "kg",
+	"kilos":   "kg",
+	"ml":      "ml",
+	"cl":      "cl",
+	"dl":      "dl",
+	"l":       "L",
+	"litre":   "L",
+	"litres":  "L",
+	"liter":   "L",
+	"liters":  "L",
+	"oz":      "oz",
+	"lb":      "lb",
+	"lbs":     "lb",
+	"tsp":     "tsp",
+	"tbsp":    "tbsp",
+	"cup":     "cup",
+	"cups":    "cup",
+	"pc":      "pc",
+	"pcs":     "pc",
+	"pack":    "pack",
+	"packs":   "pack",
+	"can":     "can",
+	"cans":    "can",
+	"bottle":  "bottle",
+	"bottles": "bottle",
+	"dozen":   "dozen",
+	"doz":     "dozen",
+	"bunch":   "bunch",
+	"bunches": "bunch",
+	"clove":   "clove",
+	"cloves":  "clove",
+}
+
+var (
+	quantityNumberPattern = `(\d+(?:[.,]\d+)?|\d+/\d+)`
+	// e.g. 2, 1.5, 1/2
+	regexQuantityOnly = regexp.MustCompile(`^` + quantityNumberPattern + `$`)
+	// e.g. 2x, x2, 2×
+	regexQuantityMultiplier = regexp.MustCompile(`^(?:[x×]` + quantityNumberPattern + `|` + quantityNumberPattern + `[x×])$`)
+	// e.g. 500g, 1.5L
+	regexQuantityWithUnit = regexp.MustCompile(`^` + quantityNumberPattern + `([a-zA-Z]+)$`)
+)
+
+// ParseQuantity extracts a leading or trailing quantity (and unit) from a raw grocery entry, e.g. "2x milk", "Chicken 500g" or "Liquid soap 500 ml".
+// Returns the raw entry as the item description with nil quantity & unit if no quantity can be found.
+func ParseQuantity(raw string) (itemDesc string, quantity *float64, unit *string) {
+	itemDesc = strings.TrimSpace(raw)
+	tokens := strings.Fields(itemDesc)
+	if len(tokens) < 2 {
+		return itemDesc, nil, nil
+	}
+
+	// leading quantity - e.g. "2 milk", "2x milk", "500g chicken", "2 L milk"
+	if q, u, ok := parseQuantityToken(tokens[0], true); ok {
+		rest := tokens[1:]
+		if u == nil && len(rest) > 1 && (rest[0] == "x" || rest[0] == "×") {
+			// e.g. "2 x milk"
+			rest = rest[1:]
+		} else if u == nil && len(rest) > 1 {
+			if canonicalUnit, isUnit := unitAliases[strings.ToLower(rest[0])]; isUnit {
+				u = &canonicalUnit
+				rest = rest[1:]
+			}
+		}
+		return strings.Join(rest, " "), &q, u
+	}
+
+	// trailing quantity - e.g. "milk x2", "Chicken 500g", "Liquid soap 500 ml", "Milk — 2 L"
+	last := tokens[len(tokens)-1]
+	rest := tokens[:len(tokens)-1]
+	q, u, ok := parseQuantityToken(last, false)
+	if !ok && len(tokens) > 2 {
+		// the unit is separated from the number by a space
+		if canonicalUnit, isUnit := unitAliases[strings.ToLower(last)]; isUnit {
+			if parsedQuantity, isNumber := parseQuantityNumber(tokens[len(tokens)-2]); isNumber {
+				q, u, ok = parsedQuantity, &canonicalUnit, true
+				rest = tokens[:len(tokens)-2]
+			}
+		}
+	}
+	if !ok && len(tokens) > 2 && tokens[len(tokens)-2] == "—" {
+		// bare numbers are fine if they come after our display separator, e.g. "Eggs — 12"
+		q, ok = parseQuantityNumber(last)
+	}
+	if !ok {
+		return itemDesc, nil, nil
+	}
+	// strip the separator used by GetDisplayText so that displayed entries can be parsed back in (e.g. through /grobulk)
+	if len(rest) > 1 && (rest[len(rest)-1] == "—" || rest[len(rest)-1] == "-") {
+		rest = rest[:len(rest)-1]
+	}
+	return strings.Join(rest, " "), &q, u
+}
+
+// parseQuantityToken parses a single token into a quantity. Bare numbers (e.g. "iPhone 15") are only treated as quantities when they lead the entry.
+func parseQuantityToken(token string, allowBareNumber bool) (quantity float64, unit *string, ok bool) {
+	if allowBareNumber {
+		if m := regexQuantityOnly.FindStringSubmatch(token); m != nil {
+			q, isNumber := parseQuantityNumber(m[1])
+			return q, nil, isNumber
+		}
+	}
+	if m := regexQuantityMultiplier.FindStringSubmatch(token); m != nil {
+		numberStr := m[1]
+		if numberStr == "" {
+			numberStr = m[2]
+		}
+		q, isNumber := parseQuantityNumber(numberStr)
+		return q, nil, isNumber
+	}
+	if m := regexQuantityWithUnit.FindStringSubmatch(token); m != nil {
+		canonicalUnit, isUnit := unitAliases[strings.ToLower(m[2])]
+		if !isUnit {
+			return 0, nil, false
+		}
+		q, isNumber := parseQuantityNumber(m[1])
+		return q, &canonicalUnit, isNumber
+	}
+	return 0, nil, false
+}
+
+func parseQuantityNumber(s string) (float64, bool) {
+	if numerator, denominator, isFraction := strings.Cut(s, "/"); isFraction {
+		n, nErr := strconv.ParseFloat(numerator, 64)
+		d, dErr := strconv.ParseFloat(denominator, 64)
+		if nErr != nil || dErr != nil || d == 0 {
+			return 0, false
+		}
+		if n <= 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	q, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	return q, true
+}
+
+// ScaleQuantity multiplies a quantity by factor (e.g. to cook a recipe for more people), rounded to 2 decimal places.
+// Quantities are never scaled down to nothing.
+func ScaleQuantity(quantity float64, factor float64) float64 {
+	scaled := math.Round(quantity*factor*100) / 100
+	if scaled <= 0 {
+		return 0.01
+	}
+	return scaled
+}
@@ -0,0 +1,66 @@
+package groceryutils
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	cases := []struct {
+		raw          string
+		wantDesc     string
+		wantQuantity float64 // 0 means nil
+		wantUnit     string  // "" means nil
+	}{
+		{raw: "eggs", wantDesc: "eggs"},
+		{raw: "Chicken katsu", wantDesc: "Chicken katsu"},
+		{raw: "PS5", wantDesc: "PS5"},
+		{raw: "iPhone 15", wantDesc: "iPhone 15"},
+		{raw: "2x milk", wantDesc: "milk", wantQuantity: 2},
+		{raw: "2 x milk", wantDesc: "milk", wantQuantity: 2},
+		{raw: "milk x2", wantDesc: "milk", wantQuantity: 2},
+		{raw: "2 eggs", wantDesc: "eggs", wantQuantity: 2},
+		{raw: "milk 1L", wantDesc: "milk", wantQuantity: 1, wantUnit: "L"},
+		{raw: "Chicken 500g", wantDesc: "Chicken", wantQuantity: 500, wantUnit: "g"},
+		{raw: "Liquid soap 500 ml", wantDesc: "Liquid soap", wantQuantity: 500, wantUnit: "ml"},
+		{raw: "2 L milk", wantDesc: "milk", wantQuantity: 2, wantUnit: "L"},
+		{raw: "1,5 kg potatoes", wantDesc: "potatoes", wantQuantity: 1.5, wantUnit: "kg"},
+		{raw: "1/2 cup sugar", wantDesc: "sugar", wantQuantity: 0.5, wantUnit: "cup"},
+		{raw: "Soap 1pc", wantDesc: "Soap", wantQuantity: 1, wantUnit: "pc"},
+		{raw: "Milk — 2 L", wantDesc: "Milk", wantQuantity: 2, wantUnit: "L"},
+		{raw: "Eggs — 12", wantDesc: "Eggs", wantQuantity: 12},
+		{raw: "Vitamin B 12abc", wantDesc: "Vitamin B 12abc"},
+	}
+	for _, c := range cases {
+		desc, quantity, unit := ParseQuantity(c.raw)
+		if desc != c.wantDesc {
+			t.Errorf("%q: expected desc %q, got %q", c.raw, c.wantDesc, desc)
+		}
+		if c.wantQuantity == 0 && quantity != nil {
+			t.Errorf("%q: expected nil quantity, got %v", c.raw, *quantity)
+		} else if c.wantQuantity != 0 && (quantity == nil || *quantity != c.wantQuantity) {
+			t.Errorf("%q: expected quantity %v, got %v", c.raw, c.wantQuantity, quantity)
+		}
+		if c.wantUnit == "" && unit != nil {
+			t.Errorf("%q: expected nil unit, got %q", c.raw, *unit)
+		} else if c.wantUnit != "" && (unit == nil || *unit != c.wantUnit) {
+			t.Errorf("%q: expected unit %q, got %v", c.raw, c.wantUnit, unit)
+		}
+	}
+}
+
+func TestScaleQuantity(t *testing.T) {
+	cases := []struct {
+		quantity float64
+		factor   float64
+		want     float64
+	}{
+		{quantity: 2, factor: 2, want: 4},
+		{quantity: 500, factor: 0.5, want: 250},
+		{quantity: 1, factor: 1.0 / 3, want: 0.33},
+		{quantity: 0.5, factor: 1.5, want: 0.75},
+		{quantity: 0.01, factor: 0.25, want: 0.01},
+	}
+	for _, c := range cases {
+		if got := ScaleQuantity(c.quantity, c.factor); got != c.want {
+			t.Errorf("%v * %v: expected %v, got %v", c.quantity, c.factor, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"net"
+	"syscall"
+)
+
+// DenyPrivateNetwork returns a net.Dialer Control func that fails with err when connecting to a private/loopback address, so that
+// user-supplied URLs can't be used to poke around the network that GroceryBot runs in. It's checked on every connection (rather than
+// when the URL is given to us) so that DNS can't be changed to point somewhere else later on.
+func DenyPrivateNetwork(err error) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		host, _, splitErr := net.SplitHostPort(address)
+		if splitErr != nil {
+			return splitErr
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+			return err
+		}
+		return nil
+	}
+}
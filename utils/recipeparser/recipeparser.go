@@ -0,0 +1,292 @@
+// Package recipeparser reads the schema.org Recipe that most recipe sites embed in their pages, either as JSON-LD or as microdata.
+package recipeparser
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	ErrRecipeNotFound = errors.New("no schema.org recipe found on the page")
+
+	regexServings   = regexp.MustCompile(`\d+`)
+	regexWhitespace = regexp.MustCompile(`\s+`)
+)
+
+type Recipe struct {
+	Title       string
+	Ingredients []string
+	// 0 if the recipe doesn't say
+	Servings int
+}
+
+// Parse returns the first schema.org Recipe in an HTML page that has ingredients, preferring JSON-LD over microdata.
+// Returns ErrRecipeNotFound if there isn't one.
+func Parse(r io.Reader) (*Recipe, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var microdata *Recipe
+	var jsonLD *Recipe
+	walk(doc, func(n *html.Node) bool {
+		if jsonLD != nil {
+			return false
+		}
+		if isJSONLDScript(n) {
+			if recipe := parseJSONLD(textContent(n)); recipe != nil && len(recipe.Ingredients) > 0 {
+				jsonLD = recipe
+			}
+			return false
+		}
+		// recipe cards (e.g. "you might also like") are marked up as recipes too, so the first one with ingredients wins.
+		// Its children are still walked, since sites also put their JSON-LD inside of it.
+		if microdata == nil && isMicrodataRecipe(n) {
+			if recipe := parseMicrodata(n); len(recipe.Ingredients) > 0 {
+				microdata = recipe
+			}
+		}
+		return true
+	})
+	if jsonLD != nil {
+		return jsonLD, nil
+	}
+	if microdata != nil {
+		return microdata, nil
+	}
+	return nil, ErrRecipeNotFound
+}
+
+// ParseServings reads the number of servings out of a recipeYield-ish value: a number, a string like "4 servings" (or "4-6", which is 4),
+// or a list of those (the first one that has a number wins). Returns 0 if there's no number in there.
+func ParseServings(raw json.RawMessage) int {
+	var n float64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		if n < 1 {
+			return 0
+		}
+		return int(n)
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return parseServingsString(str)
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, item := range list {
+			if servings := ParseServings(item); servings > 0 {
+				return servings
+			}
+		}
+	}
+	return 0
+}
+
+func parseServingsString(str string) int {
+	servings, err := strconv.Atoi(regexServings.FindString(str))
+	if err != nil || servings < 1 {
+		return 0
+	}
+	return servings
+}
+
+// walk visits n and its descendants depth-first, skipping the children of nodes that visit returns false for.
+func walk(n *html.Node, visit func(n *html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func isJSONLDScript(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "script" && strings.EqualFold(strings.TrimSpace(getAttr(n, "type")), "application/ld+json")
+}
+
+func isMicrodataRecipe(n *html.Node) bool {
+	if n.Type != html.ElementNode || !hasAttr(n, "itemscope") {
+		return false
+	}
+	for _, itemType := range strings.Fields(getAttr(n, "itemtype")) {
+		if isRecipeType(itemType) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRecipeType matches "Recipe" as well as "https://schema.org/Recipe" and friends.
+func isRecipeType(t string) bool {
+	return strings.EqualFold(t[strings.LastIndex(t, "/")+1:], "Recipe")
+}
+
+type jsonLDRecipe struct {
+	Name             string          `json:"name"`
+	RecipeIngredient json.RawMessage `json:"recipeIngredient"`
+	// older sites still use the deprecated "ingredients"
+	Ingredients json.RawMessage `json:"ingredients"`
+	RecipeYield json.RawMessage `json:"recipeYield"`
+}
+
+// parseJSONLD returns nil if the script doesn't have a Recipe in it, which is common since sites use JSON-LD for all sorts of things.
+func parseJSONLD(script string) *Recipe {
+	var data interface{}
+	if err := json.Unmarshal([]byte(script), &data); err != nil {
+		return nil
+	}
+	node := findJSONLDRecipe(data)
+	if node == nil {
+		return nil
+	}
+	// round-trip so that we can unmarshal into a struct
+	raw, err := json.Marshal(node)
+	if err != nil {
+		return nil
+	}
+	var parsed jsonLDRecipe
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil
+	}
+	ingredientsRaw := parsed.RecipeIngredient
+	if len(ingredientsRaw) == 0 {
+		ingredientsRaw = parsed.Ingredients
+	}
+	var lines []string
+	if err := json.Unmarshal(ingredientsRaw, &lines); err != nil {
+		// some sites put everything into one string
+		var line string
+		if err := json.Unmarshal(ingredientsRaw, &line); err != nil {
+			return nil
+		}
+		lines = strings.Split(line, "\n")
+	}
+	return &Recipe{
+		Title:       cleanText(parsed.Name),
+		Ingredients: cleanLines(lines),
+		Servings:    ParseServings(parsed.RecipeYield),
+	}
+}
+
+// findJSONLDRecipe looks for an object with a Recipe @type, which can be at the top level, in a list or in an @graph.
+func findJSONLDRecipe(data interface{}) map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if recipe := findJSONLDRecipe(item); recipe != nil {
+				return recipe
+			}
+		}
+	case map[string]interface{}:
+		if hasRecipeType(v["@type"]) {
+			return v
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findJSONLDRecipe(graph)
+		}
+	}
+	return nil
+}
+
+func hasRecipeType(t interface{}) bool {
+	switch v := t.(type) {
+	case string:
+		return isRecipeType(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && isRecipeType(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseMicrodata(scope *html.Node) *Recipe {
+	recipe := &Recipe{}
+	lines := make([]string, 0)
+	walk(scope, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		// properties of nested items (e.g. the recipe's author) aren't the recipe's
+		if n != scope && hasAttr(n, "itemscope") {
+			return false
+		}
+		for _, prop := range strings.Fields(getAttr(n, "itemprop")) {
+			switch prop {
+			case "recipeIngredient", "ingredients":
+				lines = append(lines, microdataValue(n))
+			case "name":
+				if recipe.Title == "" {
+					recipe.Title = cleanText(microdataValue(n))
+				}
+			case "recipeYield":
+				if recipe.Servings == 0 {
+					recipe.Servings = parseServingsString(microdataValue(n))
+				}
+			}
+		}
+		return true
+	})
+	recipe.Ingredients = cleanLines(lines)
+	return recipe
+}
+
+// microdataValue returns the content attribute (e.g. for <meta>) if there is one, otherwise the element's text.
+func microdataValue(n *html.Node) string {
+	if hasAttr(n, "content") {
+		return getAttr(n, "content")
+	}
+	return textContent(n)
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(n *html.Node) bool {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// cleanText unescapes the HTML entities that sites tend to leave in their JSON-LD (e.g. "&amp;") and collapses whitespace.
+func cleanText(s string) string {
+	return strings.TrimSpace(regexWhitespace.ReplaceAllString(html.UnescapeString(s), " "))
+}
+
+func cleanLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = cleanText(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package recipeparser
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *Recipe
+	}{
+		{
+			"jsonld_graph.html",
+			&Recipe{
+				Title:       "Easy Weeknight Lasagne",
+				Ingredients: []string{"500 g beef mince", "1 jar (700g) tomato passata", "12 lasagne sheets", "250 g ricotta & spinach", "1 tsp salt"},
+				Servings:    4,
+			},
+		},
+		{
+			"jsonld_list.html",
+			&Recipe{
+				Title:       "Pancakes",
+				Ingredients: []string{"2 cups flour", "2 eggs", "1 1/2 cups milk"},
+				Servings:    8,
+			},
+		},
+		{
+			"microdata.html",
+			&Recipe{
+				Title:       "Banana bread",
+				Ingredients: []string{"3 ripe bananas", "200 g self-raising flour", "100 g butter & a pinch of salt"},
+				Servings:    10,
+			},
+		},
+		{
+			// the first recipe is a card without any ingredients
+			"microdata_card_first.html",
+			&Recipe{
+				Title:       "Pumpkin soup",
+				Ingredients: []string{"1 kg pumpkin", "1 L chicken stock"},
+				Servings:    6,
+			},
+		},
+		{
+			"jsonld_in_microdata.html",
+			&Recipe{
+				Title:       "Egg fried rice",
+				Ingredients: []string{"2 cups cooked rice", "2 eggs", "1 tbsp soy sauce"},
+				Servings:    2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := Parse(f)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNoRecipe(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "no_recipe.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got, err := Parse(f); !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Parse() = %+v, %v, want ErrRecipeNotFound", got, err)
+	}
+}
+
+func TestParseServings(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{`4`, 4},
+		{`4.5`, 4},
+		{`0`, 0},
+		{`"6 servings"`, 6},
+		{`"4-6"`, 4},
+		{`"a few"`, 0},
+		{`["", "Serves 2"]`, 2},
+		{`{"value": 4}`, 0},
+		{``, 0},
+	}
+	for _, tt := range tests {
+		if got := ParseServings(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("ParseServings(%s) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}